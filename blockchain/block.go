@@ -1,9 +1,7 @@
 package blockchain
 
 import (
-	"encoding/json"
 	"log"
-	"time"
 )
 
 type Block struct {
@@ -32,11 +30,11 @@ func (b *Block) HashTransactions() []byte {
 	return tree.RootNode.Data
 }
 
-// 블록 생성하는 함수
-func CreateBlock(txs []*Transaction, prevHash []byte, height int) *Block {
+// 주어진 시간의 블록 생성하는 함수
+func CreateBlock(txs []*Transaction, prevHash []byte, height int, timestamp int64) *Block {
 	// 블록을 생성하고 블록에 대한 포인터를 출력
 	// 블록 생성자를 사용하여 새 블록을 생성
-	block := &Block{time.Now().Unix(), []byte{}, txs, prevHash, 0, height}
+	block := &Block{timestamp, []byte{}, txs, prevHash, 0, height}
 	pow := NewProof(block)
 	nonce, hash := pow.Run()
 
@@ -46,33 +44,37 @@ func CreateBlock(txs []*Transaction, prevHash []byte, height int) *Block {
 	return block
 }
 
-// Genesis 블록 만드는 함수
-func Genesis(coinbase *Transaction) *Block {
-	return CreateBlock([]*Transaction{coinbase}, []byte{}, 0)
-}
-
 // 블록구조 직렬화
 func (b *Block) Serialize() []byte {
-	// Block 구조체를 JSON으로 직렬화
-	data, err := json.Marshal(b)
-
-	// 직렬화 중 에러가 발생하면 패닉
-	if err != nil {
-		log.Panic(err)
+	// 이전 형식으로 만들어진 블록은 블록 해시가 유지되도록 원래 형식으로 직렬화
+	if b.encoding() != 0 {
+		return b.serializeLegacy()
 	}
 
-	return data
+	e := &encoder{}
+	// 형식 버전을 기록한 뒤 Block 구조체를 바이너리로 직렬화
+	e.writeByte(txEncodingVersion)
+	b.encode(e)
+
+	return e.Bytes()
 }
 
 // 바이트 슬라이스를 사용하여 Block 구조체 복원
 func Deserialize(data []byte) *Block {
 	var block Block
 
-	// JSON 바이트 슬라이스를 Block 구조체로 변환
-	err := json.Unmarshal(data, &block)
+	// 스키마 버전 2 이전의 JSON 형식
+	if len(data) > 0 && data[0] == jsonEncodingVersion {
+		return deserializeJSONBlock(data)
+	}
+
+	// 바이너리 데이터를 Block 구조체로 변환
+	d := newDecoder(data)
+	d.readVersion()
+	block.decode(d)
 
 	// 역직렬화 중 에러가 발생하면 패닉
-	if err != nil {
+	if err := d.finish(); err != nil {
 		log.Panic(err)
 	}
 
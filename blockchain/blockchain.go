@@ -16,7 +16,8 @@ import (
 )
 
 const (
-	dbPath      = "./tmp/blocks_%s"
+	// 데이터 디렉터리 안의 데이터베이스 경로
+	dbPath      = "%s/blocks_%s"
 	genesisData = "First Transaction from Genesis."
 )
 
@@ -34,8 +35,13 @@ func DBexists(path string) bool {
 	return true
 }
 
+// 선택된 네트워크에서 노드의 데이터베이스 경로를 반환하는 함수
+func DBPath(nodeId string) string {
+	return fmt.Sprintf(dbPath, ActiveParams.DataDir, nodeId)
+}
+
 func ContinueBlockChain(nodeId string) *BlockChain {
-	path := fmt.Sprintf(dbPath, nodeId)
+	path := DBPath(nodeId)
 
 	if !DBexists(path) {
 		fmt.Println("No existing blockchain found, create one!")
@@ -54,7 +60,7 @@ func ContinueBlockChain(nodeId string) *BlockChain {
 
 	err = db.Update(func(txn *badger.Txn) error {
 		// lh 키에 해당하는 데이터 조회
-		item, err := txn.Get([]byte("lh"))
+		item, err := txn.Get(lastHashKey)
 		Handle(err)
 
 		// 마지막 블록의 해시 값 조회
@@ -66,17 +72,33 @@ func ContinueBlockChain(nodeId string) *BlockChain {
 
 	chain := BlockChain{lastHash, db}
 
+	// 이전 스키마로 저장된 데이터베이스를 현재 스키마로 업그레이드
+	err = chain.Migrate()
+	Handle(err)
+
 	return &chain
 }
 
-func InitBlockChain(address, nodeId string) *BlockChain {
-	path := fmt.Sprintf(dbPath, nodeId)
+// 선택된 네트워크의 고정된 Genesis 블록으로 블록체인을 생성하는 함수
+func InitBlockChain(nodeId string) *BlockChain {
+	path := DBPath(nodeId)
 	if DBexists(path) {
 		fmt.Println("Blockchain already exists")
 		runtime.Goexit()
 	}
 
-	var lastHash []byte
+	// Genesis 블록 생성
+	genesis := ActiveParams.GenesisBlock()
+	fmt.Printf("Genesis created: %x\n", genesis.Hash)
+
+	return initBlockChainWithGenesis(path, genesis)
+}
+
+// 주어진 Genesis 블록으로 새로운 블록체인 데이터베이스를 생성하는 함수
+func initBlockChainWithGenesis(path string, genesis *Block) *BlockChain {
+	// 데이터 디렉터리가 없으면 생성
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	Handle(err)
 
 	// Badger 데이터베이스의 옵션 설정 (최신 버전)
 	opts := badger.DefaultOptions(path)
@@ -87,22 +109,19 @@ func InitBlockChain(address, nodeId string) *BlockChain {
 
 	// 데이터베이스 업데이트 함수 실행
 	err = db.Update(func(txn *badger.Txn) error {
-		cbtx := CoinbaseTx(address, genesisData)
-		genesis := Genesis(cbtx)
-		fmt.Println("Genesis created")
 		// Genesis 블록 저장
 		err = txn.Set(genesis.Hash, genesis.Serialize())
 		Handle(err)
 		// 마지막 블록 해시 저장
-		err = txn.Set([]byte("lh"), genesis.Hash)
-
-		lastHash = genesis.Hash
-
-		return err
+		return txn.Set(lastHashKey, genesis.Hash)
 	})
 	Handle(err)
 
-	blockchain := BlockChain{lastHash, db}
+	// 새로 생성한 데이터베이스는 현재 스키마 버전으로 기록
+	err = setSchemaVersion(db, SchemaVersion)
+	Handle(err)
+
+	blockchain := BlockChain{genesis.Hash, db}
 	return &blockchain
 }
 
@@ -123,7 +142,7 @@ func (chain *BlockChain) AddBlock(block *Block) {
 		Handle(err)
 
 		// lh 키를 이용해 마지막 블록 해시를 데이터베이스에서 가져옴
-		item, err := txn.Get([]byte("lh"))
+		item, err := txn.Get(lastHashKey)
 		Handle(err)
 		// 마지막 블록 해시 가져옴
 		lastHash, _ := item.ValueCopy(nil)
@@ -140,7 +159,7 @@ func (chain *BlockChain) AddBlock(block *Block) {
 		// 새 블록의 높이가 마지막 블록의 높이보다 큰 경우
 		if block.Height > lastBlock.Height {
 			// lh 키에 새로운 블록의 해시를 저장하여 마지막 블록 해시를 업데이트
-			err = txn.Set([]byte("lh"), block.Hash)
+			err = txn.Set(lastHashKey, block.Hash)
 			Handle(err)
 			// 블록체인의 마지막 블록 해시를 새 블록의 해시로 업데이트
 			chain.LastHash = block.Hash
@@ -160,7 +179,7 @@ func (chain *BlockChain) GetBestHeight() int {
 	// 데이터베이스 읽기 트랜잭션 시작
 	err := chain.Database.View(func(txn *badger.Txn) error {
 		// lh 키를 이용해 마지막 블록 해시를 데이터베이스에서 가져옴
-		item, err := txn.Get([]byte("lh"))
+		item, err := txn.Get(lastHashKey)
 		Handle(err)
 		// 마지막 블록 해시를 가져옴
 		lastHash, _ := item.ValueCopy(nil)
@@ -212,6 +231,20 @@ func (chain *BlockChain) GetBlock(blockHash []byte) (Block, error) {
 	return block, nil
 }
 
+// 블록체인의 첫 블록(Genesis 블록) 해시를 가져오는 함수
+func (chain *BlockChain) GetGenesisHash() []byte {
+	iter := chain.Iterator()
+
+	for {
+		block := iter.Next()
+
+		// 이전 블록이 없는 블록이 Genesis 블록
+		if len(block.PrevHash) == 0 {
+			return block.Hash
+		}
+	}
+}
+
 // 블록체인에 있는 모든 블록의 해시를 가져오는 함수
 func (chain *BlockChain) GetBlockHashes() [][]byte {
 	// 모든 블록 해시를 저장할 슬라이스를 선언
@@ -243,18 +276,23 @@ func (chain *BlockChain) MineBlock(transactions []*Transaction) *Block {
 	var lastHash []byte
 	var lastHeight int
 
-	// 각 트랜잭션을 검증
+	// 각 트랜잭션의 출력 형식을 검증
 	for _, tx := range transactions {
-		if !chain.VerifyTransaction(tx) {
-			// 트랜잭션이 유효하지 않으면 패닉 발생
-			log.Panic("Invalid Transaction")
+		if err := tx.CheckOutputs(); err != nil {
+			log.Panicf("Invalid Transaction %x: %v", tx.ID, err)
 		}
 	}
 
+	// 새 블록의 높이와 마지막 블록까지의 중간 시간으로 서명과 잠금, 코인베이스 성숙 깊이를 확인하고
+	// 사용하는 출력이 UTXO 집합이나 앞의 트랜잭션에 있고 이중 지불이 없으며 입력 금액이 출력 금액 이상인지 확인
+	if err := (UTXOSet{chain}).CheckTransactions(transactions); err != nil {
+		log.Panic("Invalid Transaction: ", err)
+	}
+
 	// 데이터베이스에서 마지막 블록의 해시와 데이터를 가져옴
 	err := chain.Database.View(func(txn *badger.Txn) error {
 		// lh 키를 통해 마지막 블록의 해시를 가져옴
-		item, err := txn.Get([]byte("lh"))
+		item, err := txn.Get(lastHashKey)
 		Handle(err)
 		// 마지막 블록의 해시 값을 저장
 		lastHash, _ = item.ValueCopy(nil)
@@ -275,8 +313,8 @@ func (chain *BlockChain) MineBlock(transactions []*Transaction) *Block {
 	})
 	Handle(err)
 
-	// 새로운 블록을 생성
-	newBlock := CreateBlock(transactions, lastHash, lastHeight+1)
+	// 블록 시간 규칙을 지키는 시간으로 새로운 블록을 생성
+	newBlock := CreateBlock(transactions, lastHash, lastHeight+1, chain.NextBlockTime())
 
 	// 데이터베이스에 새로운 블록을 저장
 	err = chain.Database.Update(func(txn *badger.Txn) error {
@@ -284,7 +322,7 @@ func (chain *BlockChain) MineBlock(transactions []*Transaction) *Block {
 		err := txn.Set(newBlock.Hash, newBlock.Serialize())
 		Handle(err)
 		// lh 키를 새로운 블록의 해시로 업데이트
-		err = txn.Set([]byte("lh"), newBlock.Hash)
+		err = txn.Set(lastHashKey, newBlock.Hash)
 
 		// 체인의 마지막 해시를 새로운 블록의 해시로 업데이트
 		chain.LastHash = newBlock.Hash
@@ -297,10 +335,104 @@ func (chain *BlockChain) MineBlock(transactions []*Transaction) *Block {
 	return newBlock
 }
 
-// UTXO 찾는 함수
-func (chain *BlockChain) FindUTXO() map[string]TxOutputs {
-	// UTXO와 소비된 트랜잭션 아웃풋을 저장하기 위한 맵 생성
-	UTXO := make(map[string]TxOutputs)
+// 코인베이스 트랜잭션만 포함한 블록을 n개 즉시 채굴하는 함수(regtest 전용)
+func (chain *BlockChain) Generate(n int, address string) ([]*Block, error) {
+	if !ActiveParams.GenerateSupported {
+		return nil, fmt.Errorf("generate is not supported on %s", ActiveParams.Name)
+	}
+
+	UTXOSet := UTXOSet{chain}
+	blocks := make([]*Block, 0, n)
+
+	for i := 0; i < n; i++ {
+		// 보상을 주소로 보내는 블록을 채굴하고 UTXO 집합 갱신
+		cbTx := CoinbaseTx(address, "")
+		block := chain.MineBlock([]*Transaction{cbTx})
+		UTXOSet.Update(block)
+
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// 블록이 현재 체인의 마지막 블록 위에 연결될 수 있는지 검증하는 함수
+func (chain *BlockChain) ValidateBlock(block *Block) error {
+	// 이전 블록이 현재 체인의 마지막 블록인지 확인
+	if !bytes.Equal(block.PrevHash, chain.LastHash) {
+		return fmt.Errorf("block %x does not extend the chain tip %x", block.Hash, chain.LastHash)
+	}
+
+	// 블록 높이 확인
+	expectedHeight := chain.GetBestHeight() + 1
+	if block.Height != expectedHeight {
+		return fmt.Errorf("block %x has height %d, expected %d", block.Hash, block.Height, expectedHeight)
+	}
+
+	// 작업 증명과 블록 해시 확인
+	if err := checkProofOfWork(block); err != nil {
+		return err
+	}
+
+	// 블록 시간이 이전 블록들의 중간 시간보다 크고 현재 시간보다 너무 앞서지 않는지 확인
+	if err := chain.checkBlockTime(block); err != nil {
+		return err
+	}
+
+	return chain.validateTransactions(block)
+}
+
+// 블록의 작업 증명과 블록 해시가 올바른지 확인하는 함수
+func checkProofOfWork(block *Block) error {
+	pow := NewProof(block)
+	if !pow.Validate() {
+		return fmt.Errorf("block %x has invalid proof of work", block.Hash)
+	}
+	if !bytes.Equal(pow.Hash(), block.Hash) {
+		return fmt.Errorf("block %x has mismatched hash", block.Hash)
+	}
+
+	return nil
+}
+
+// 블록에 포함된 트랜잭션을 검증하는 함수
+func (chain *BlockChain) validateTransactions(block *Block) error {
+	// 출력 형식 확인
+	for _, tx := range block.Transactions {
+		if err := tx.CheckOutputs(); err != nil {
+			return fmt.Errorf("transaction %x: %w", tx.ID, err)
+		}
+	}
+
+	// 블록의 트랜잭션을 현재 UTXO 집합 위에 차례로 적용하여 코인베이스 위치와 ID, 서명, 이중 지불, 금액을 확인하고
+	// LockTime과 상대 잠금, 코인베이스 성숙 깊이는 블록의 높이와 이전 블록까지의 중간 시간을 기준으로 확인
+	if err := (UTXOSet{chain}).CheckTransactions(block.Transactions); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// 블록을 검증한 뒤 체인에 연결하고 UTXO 집합을 갱신하는 함수
+func (chain *BlockChain) ConnectBlock(block *Block) error {
+	if err := chain.ValidateBlock(block); err != nil {
+		return err
+	}
+
+	// 블록 저장 및 마지막 블록 해시 갱신
+	chain.AddBlock(block)
+
+	// UTXO 집합 갱신
+	UTXOSet := UTXOSet{chain}
+	UTXOSet.Update(block)
+
+	return nil
+}
+
+// UTXO 찾는 함수(출력마다 원래 출력 인덱스, 블록 높이, 코인베이스 여부를 함께 반환)
+func (chain *BlockChain) FindUTXO() []UnspentOutput {
+	// UTXO와 소비된 트랜잭션 아웃풋을 저장하기 위한 슬라이스와 맵 생성
+	var UTXO []UnspentOutput
 	spentTXOs := make(map[string][]int)
 
 	// 블록체인 순회
@@ -310,8 +442,10 @@ func (chain *BlockChain) FindUTXO() map[string]TxOutputs {
 		// 다음 블록
 		block := iter.Next()
 
-		// 블록의 모든 트랜잭션을 반복
-		for _, tx := range block.Transactions {
+		// 블록의 모든 트랜잭션을 뒤에서부터 반복
+		// 같은 블록의 부모 출력을 사용하는 자식이 부모보다 먼저 처리되어야 사용된 출력으로 표시됨
+		for i := len(block.Transactions) - 1; i >= 0; i-- {
+			tx := block.Transactions[i]
 			// 트랜잭션의 ID를 문자열로 변환
 			txID := hex.EncodeToString(tx.ID)
 
@@ -328,11 +462,12 @@ func (chain *BlockChain) FindUTXO() map[string]TxOutputs {
 						}
 					}
 				}
-				// UTXO 맵에서 해당 트랜잭션의 출력값을 가져옴
-				outs := UTXO[txID]
-				// 출력값을 UTXO에 추가
-				outs.Outputs = append(outs.Outputs, out)
-				UTXO[txID] = outs
+				// 사용할 수 없는 데이터 출력은 UTXO에 넣지 않음
+				if out.IsUnspendable() {
+					continue
+				}
+				// 출력값을 원래 인덱스, 블록 높이, 코인베이스 여부와 함께 UTXO에 추가
+				UTXO = append(UTXO, UnspentOutput{tx.ID, outIdx, out, block.Height, tx.IsCoinbase()})
 			}
 
 			// 코인베이스 트랜잭션이 아닌 경우 소비된 트랜잭션을 처리
@@ -353,6 +488,77 @@ func (chain *BlockChain) FindUTXO() map[string]TxOutputs {
 	return UTXO
 }
 
+// 트랜잭션이 포함된 블록의 높이와 시간
+type txLocation struct {
+	height    int
+	timestamp int64
+	// 코인베이스 트랜잭션인지 여부
+	coinbase bool
+}
+
+// 주어진 트랜잭션들이 포함된 블록의 높이를 찾는 함수(16진수 트랜잭션 ID를 키로 사용)
+func (chain *BlockChain) FindTransactionHeights(txIDs [][]byte) map[string]int {
+	heights := make(map[string]int)
+	for id, location := range chain.findTransactionLocations(txIDs) {
+		heights[id] = location.height
+	}
+
+	return heights
+}
+
+// 주어진 트랜잭션들이 포함된 블록의 높이와 시간을 찾는 함수(16진수 트랜잭션 ID를 키로 사용)
+func (chain *BlockChain) findTransactionLocations(txIDs [][]byte) map[string]txLocation {
+	locations := make(map[string]txLocation)
+
+	wanted := make(map[string]bool)
+	for _, txID := range txIDs {
+		wanted[hex.EncodeToString(txID)] = true
+	}
+
+	iter := chain.Iterator()
+	for len(locations) < len(wanted) {
+		block := iter.Next()
+
+		for _, tx := range block.Transactions {
+			id := hex.EncodeToString(tx.ID)
+			if wanted[id] {
+				locations[id] = txLocation{block.Height, block.Timestamp, tx.IsCoinbase()}
+			}
+		}
+
+		if len(block.PrevHash) == 0 {
+			break
+		}
+	}
+
+	return locations
+}
+
+// 체인에서 한 번이라도 사용된(출력을 받은) 공개 키 해시를 찾는 함수
+// 입력으로 서명한 키도 그 전에 출력을 받았으므로 출력만 확인
+func (chain *BlockChain) FindUsedPubKeyHashes() map[string]bool {
+	used := make(map[string]bool)
+
+	iter := chain.Iterator()
+	for {
+		block := iter.Next()
+
+		for _, tx := range block.Transactions {
+			for _, out := range tx.Outputs {
+				if pubKeyHash := out.PubKeyHash(); pubKeyHash != nil {
+					used[hex.EncodeToString(pubKeyHash)] = true
+				}
+			}
+		}
+
+		if len(block.PrevHash) == 0 {
+			break
+		}
+	}
+
+	return used
+}
+
 // 지정된 ID를 가진 트랜잭션 찾는 함수
 func (bc *BlockChain) FindTransaction(ID []byte) (Transaction, error) {
 	// 블록체인을 순회하기 위한 이터레이터 생성
@@ -384,20 +590,26 @@ func (bc *BlockChain) FindTransaction(ID []byte) (Transaction, error) {
 
 // 트랜잭션 서명함수
 func (bc *BlockChain) SignTransaction(tx *Transaction, privKey *ecdsa.PrivateKey) {
-	// 이전 트랜잭션을 저장할 맵 생성
+	// 이전 트랜잭션을 찾아 트랜잭션 서명
+	tx.Sign(privKey, bc.findPrevTransactions(tx))
+}
+
+// 트랜잭션의 각 입력이 소비하는 이전 출력을 찾는 함수
+func (bc *BlockChain) FindPrevOutputs(tx *Transaction) []TxOutput {
+	return tx.prevOutputs(bc.findPrevTransactions(tx))
+}
+
+// 트랜잭션 입력이 참조하는 이전 트랜잭션들을 찾는 함수
+func (bc *BlockChain) findPrevTransactions(tx *Transaction) map[string]Transaction {
 	prevTXs := make(map[string]Transaction)
 
-	// 트랜잭션의 입력을 순회
 	for _, in := range tx.Inputs {
-		// 이전 트랜잭션을 블록체인에서 검색
 		prevTX, err := bc.FindTransaction(in.ID)
 		Handle(err)
-		// 검색된 이진 트랜잭션을 맵에 추가
 		prevTXs[hex.EncodeToString(prevTX.ID)] = prevTX
 	}
 
-	// 트랜잭션 서명
-	tx.Sign(privKey, prevTXs)
+	return prevTXs
 }
 
 // 트랜잭션 유효성 검사 함수
@@ -0,0 +1,267 @@
+package blockchain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+const (
+	// 부트스트랩 파일 형식 버전
+	bootstrapVersion = 1
+	// 부트스트랩 파일에서 허용하는 블록 하나의 최대 크기
+	maxBootstrapBlockSize = 32 << 20
+)
+
+// 부트스트랩 파일의 시작을 나타내는 매직 바이트
+var bootstrapMagic = []byte("GBCB")
+
+// 부트스트랩 파일 헤더
+type bootstrapHeader struct {
+	Version int
+	From    int
+	To      int
+}
+
+// 블록체인의 블록을 높이 순서대로 부트스트랩 파일로 내보내는 함수
+// to가 음수이면 마지막 블록까지 내보내며, 내보낸 블록 수를 반환
+func (chain *BlockChain) ExportChain(w io.Writer, from, to int) (int, error) {
+	bestHeight := chain.GetBestHeight()
+	if to < 0 || to > bestHeight {
+		to = bestHeight
+	}
+	if from < 0 || from > to {
+		return 0, fmt.Errorf("invalid height range %d-%d", from, to)
+	}
+
+	// 마지막 블록부터 역순으로 수집된 해시를 높이 순서로 뒤집음
+	hashes := chain.GetBlockHashes()
+	for i, j := 0, len(hashes)-1; i < j; i, j = i+1, j-1 {
+		hashes[i], hashes[j] = hashes[j], hashes[i]
+	}
+
+	bw := bufio.NewWriter(w)
+
+	// 헤더 기록
+	if err := writeBootstrapHeader(bw, bootstrapHeader{bootstrapVersion, from, to}); err != nil {
+		return 0, err
+	}
+
+	// 높이 순서대로 블록 기록
+	count := 0
+	for height := from; height <= to; height++ {
+		block, err := chain.GetBlock(hashes[height])
+		if err != nil {
+			return count, err
+		}
+		if err := writeBootstrapRecord(bw, block.Serialize()); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, bw.Flush()
+}
+
+// 부트스트랩 파일의 블록을 검증하며 블록체인에 추가하는 함수
+// 파일의 높이 0 블록은 선택된 네트워크의 Genesis 블록이어야 하며, 블록체인이 없으면 이 블록으로 새로 생성하고, 추가한 블록 수를 반환
+func ImportChain(r io.Reader, nodeId string) (int, error) {
+	br := bufio.NewReader(r)
+
+	// 헤더 확인
+	header, err := readBootstrapHeader(br)
+	if err != nil {
+		return 0, err
+	}
+
+	var chain *BlockChain
+	imported := 0
+
+	for height := header.From; height <= header.To; height++ {
+		data, err := readBootstrapRecord(br)
+		if err != nil {
+			return imported, fmt.Errorf("block at height %d: %w", height, err)
+		}
+		block := Deserialize(data)
+
+		if block.Height != height {
+			return imported, fmt.Errorf("block %x has height %d, expected %d", block.Hash, block.Height, height)
+		}
+
+		// 다른 네트워크나 다른 Genesis 블록으로 만들어진 파일은 블록체인 유무와 관계없이 거부
+		if block.Height == 0 {
+			if err := checkGenesis(block); err != nil {
+				return imported, err
+			}
+		}
+
+		// 첫 블록을 읽은 뒤 블록체인을 열거나 생성
+		if chain == nil {
+			path := DBPath(nodeId)
+			if DBexists(path) {
+				chain = ContinueBlockChain(nodeId)
+			} else {
+				chain, err = initBlockChainFromBootstrap(path, block)
+				if err != nil {
+					return imported, err
+				}
+				imported++
+			}
+			defer chain.Database.Close()
+
+			if block.Height == 0 {
+				continue
+			}
+		}
+
+		// 이미 가지고 있는 블록은 건너뜀
+		if _, err := chain.GetBlock(block.Hash); err == nil {
+			continue
+		}
+
+		// 일반적인 블록 연결 과정을 통해 검증 후 추가
+		if err := chain.ConnectBlock(block); err != nil {
+			return imported, fmt.Errorf("block at height %d: %w", height, err)
+		}
+		imported++
+	}
+
+	return imported, nil
+}
+
+// 부트스트랩 파일의 Genesis 블록으로 새로운 블록체인을 생성하는 함수
+func initBlockChainFromBootstrap(path string, genesis *Block) (*BlockChain, error) {
+	// Genesis 블록 검증
+	if genesis.Height != 0 {
+		return nil, errors.New("no existing blockchain and bootstrap file does not start with a genesis block")
+	}
+	if err := checkGenesis(genesis); err != nil {
+		return nil, err
+	}
+
+	// 파일의 블록 대신 네트워크의 고정된 Genesis 블록으로 생성
+	chain := initBlockChainWithGenesis(path, ActiveParams.GenesisBlock())
+
+	// UTXO 집합 생성
+	UTXOSet := UTXOSet{chain}
+	UTXOSet.Reindex()
+
+	return chain, nil
+}
+
+// 부트스트랩 파일 헤더를 바이트 슬라이스로 인코딩하는 함수
+func (header bootstrapHeader) encode() []byte {
+	e := &encoder{}
+	e.buf.Write(bootstrapMagic)
+	e.writeByte(byte(header.Version))
+	e.writeUvarint(uint64(header.From))
+	e.writeUvarint(uint64(header.To))
+
+	return e.Bytes()
+}
+
+// 부트스트랩 파일 헤더를 체크섬과 함께 기록하는 함수
+func writeBootstrapHeader(w io.Writer, header bootstrapHeader) error {
+	data := header.encode()
+	data = append(data, wallet.Checksum(data)...)
+
+	_, err := w.Write(data)
+	return err
+}
+
+// 부트스트랩 파일 헤더를 읽고 검증하는 함수
+func readBootstrapHeader(r *bufio.Reader) (bootstrapHeader, error) {
+	var header bootstrapHeader
+
+	magic := make([]byte, len(bootstrapMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return header, err
+	}
+	if !bytes.Equal(magic, bootstrapMagic) {
+		return header, errors.New("not a bootstrap file")
+	}
+
+	version, err := r.ReadByte()
+	if err != nil {
+		return header, err
+	}
+	if version != bootstrapVersion {
+		return header, fmt.Errorf("unsupported bootstrap version %d", version)
+	}
+	header.Version = int(version)
+
+	from, err := binary.ReadUvarint(r)
+	if err != nil {
+		return header, err
+	}
+	to, err := binary.ReadUvarint(r)
+	if err != nil {
+		return header, err
+	}
+	header.From, header.To = int(from), int(to)
+	if header.From > header.To {
+		return header, fmt.Errorf("invalid height range %d-%d", header.From, header.To)
+	}
+
+	// 헤더 체크섬 확인
+	checksum := make([]byte, 4)
+	if _, err := io.ReadFull(r, checksum); err != nil {
+		return header, err
+	}
+	if !bytes.Equal(checksum, wallet.Checksum(header.encode())) {
+		return header, errors.New("bootstrap header checksum mismatch")
+	}
+
+	return header, nil
+}
+
+// 직렬화된 블록을 길이 접두사와 체크섬과 함께 기록하는 함수
+func writeBootstrapRecord(w io.Writer, data []byte) error {
+	e := &encoder{}
+	e.writeBytes(data)
+	e.buf.Write(wallet.Checksum(data))
+
+	_, err := w.Write(e.Bytes())
+	return err
+}
+
+// 길이 접두사가 붙은 블록을 읽고 체크섬을 검증하는 함수
+func readBootstrapRecord(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxBootstrapBlockSize {
+		return nil, fmt.Errorf("record size %d exceeds limit", size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	checksum := make([]byte, 4)
+	if _, err := io.ReadFull(r, checksum); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(checksum, wallet.Checksum(data)) {
+		return nil, errors.New("block checksum mismatch")
+	}
+
+	return data, nil
+}
+
+// 블록이 선택된 네트워크의 고정된 Genesis 블록인지 확인하는 함수
+func checkGenesis(block *Block) error {
+	genesis := ActiveParams.GenesisBlock()
+	if !bytes.Equal(block.Hash, genesis.Hash) {
+		return fmt.Errorf("genesis block %x does not match the %s genesis block %x", block.Hash, ActiveParams.Name, genesis.Hash)
+	}
+
+	return nil
+}
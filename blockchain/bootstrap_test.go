@@ -0,0 +1,62 @@
+package blockchain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 다른 Genesis 블록으로 시작하는 부트스트랩 파일을 만드는 함수
+func foreignBootstrap(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	genesis := CreateBlock([]*Transaction{CoinbaseTx(string(wallet.MakeWallet().Address()), "foreign genesis")}, []byte{}, 0, time.Now().Unix())
+
+	var buf bytes.Buffer
+	if err := writeBootstrapHeader(&buf, bootstrapHeader{bootstrapVersion, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeBootstrapRecord(&buf, genesis.Serialize()); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
+func TestImportChain(t *testing.T) {
+	chain := newTestChain(t, wallet.MakeWallet())
+
+	var buf bytes.Buffer
+	if _, err := chain.ExportChain(&buf, 0, -1); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := ImportChain(&buf, "import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := chain.GetBestHeight() + 1; imported != want {
+		t.Fatalf("imported %d blocks, want %d", imported, want)
+	}
+}
+
+func TestImportChainRejectsForeignGenesis(t *testing.T) {
+	chain := newTestChain(t, wallet.MakeWallet())
+	chain.Database.Close()
+
+	// 블록체인이 없는 노드
+	if _, err := ImportChain(foreignBootstrap(t), "import"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("import into a new chain returned %v, want genesis mismatch", err)
+	}
+	if DBexists(DBPath("import")) {
+		t.Fatal("rejected import created a blockchain")
+	}
+
+	// 이미 블록체인이 있는 노드
+	if _, err := ImportChain(foreignBootstrap(t), "test"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("import into an existing chain returned %v, want genesis mismatch", err)
+	}
+}
@@ -0,0 +1,248 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	// 트랜잭션 크기 추정값(바이너리 인코딩 기준, 바이트)
+	txOverheadSize = 36
+	txInputSize    = 164
+	txOutputSize   = 24
+	// 데이터 출력에서 스크립트를 제외한 크기(금액 0과 스크립트 길이)
+	dataOutputOverhead = 2
+
+	// branch and bound 탐색의 최대 시도 횟수
+	bnbMaxTries = 100000
+)
+
+var (
+	// 잔액이 부족할 때의 에러
+	ErrInsufficientFunds = errors.New("not enough funds")
+	// 잔돈 없이 정확히 맞는 조합을 찾지 못했을 때의 에러
+	ErrNoExactMatch = errors.New("no exact match found")
+)
+
+// 1000바이트당 수수료
+type FeeRate int
+
+// 입력과 일반 출력 개수로 트랜잭션 수수료를 계산하는 함수
+func (rate FeeRate) Fee(inputs, outputs int) int {
+	return rate.FeeForSize(txOverheadSize + inputs*txInputSize + outputs*txOutputSize)
+}
+
+// 크기가 size 바이트인 트랜잭션의 수수료를 계산하는 함수
+func (rate FeeRate) FeeForSize(size int) int {
+	// 1000바이트 단위로 올림
+	return (size*int(rate) + 999) / 1000
+}
+
+// 출력의 크기 추정값
+// 데이터 출력은 기록한 데이터에 따라 크기가 달라지므로 스크립트 길이로 계산
+func outputSize(out TxOutput) int {
+	if out.IsUnspendable() {
+		return dataOutputOverhead + len(out.ScriptPubKey)
+	}
+
+	return txOutputSize
+}
+
+// 입력 개수와 출력으로 트랜잭션 크기를 추정하는 함수
+func estimateSize(inputs int, outputs []TxOutput) int {
+	size := txOverheadSize + inputs*txInputSize
+	for _, out := range outputs {
+		size += outputSize(out)
+	}
+
+	return size
+}
+
+// 트랜잭션의 크기 추정값(서명 전에도 같은 값)
+func (tx *Transaction) EstimatedSize() int {
+	return estimateSize(len(tx.Inputs), tx.Outputs)
+}
+
+// 코인 선택 결과
+type CoinSelection struct {
+	Inputs []UnspentOutput
+	Fee    int
+	// 잔돈 금액(0이면 잔돈 출력 없음)
+	Change int
+}
+
+// 지불에 사용할 UTXO를 고르는 전략
+// amount는 지불 총액, outputs는 잔돈을 제외한 지불 출력과 데이터 출력(수수료 계산에 사용)
+type CoinSelector interface {
+	Select(utxos []UnspentOutput, amount int, outputs []TxOutput, rate FeeRate) (CoinSelection, error)
+}
+
+// 큰 금액의 UTXO부터 선택
+type LargestFirst struct{}
+
+// 작은 금액의 UTXO부터 선택
+type SmallestFirst struct{}
+
+// 무작위 순서로 선택
+type RandomSelector struct{}
+
+// 잔돈이 생기지 않는 조합을 탐색하고, 찾지 못하면 Fallback 전략 사용
+type BranchAndBound struct {
+	Fallback CoinSelector
+}
+
+// 이름으로 코인 선택 전략을 생성하는 함수
+func NewCoinSelector(name string) (CoinSelector, error) {
+	switch name {
+	case "largest":
+		return LargestFirst{}, nil
+	case "smallest":
+		return SmallestFirst{}, nil
+	case "random":
+		return RandomSelector{}, nil
+	case "bnb":
+		return BranchAndBound{Fallback: LargestFirst{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown coin selection strategy %q", name)
+	}
+}
+
+// 금액이 큰 순서로 정렬하여 선택
+func (LargestFirst) Select(utxos []UnspentOutput, amount int, outputs []TxOutput, rate FeeRate) (CoinSelection, error) {
+	return accumulate(sortedByValue(utxos, true), amount, outputs, rate)
+}
+
+// 금액이 작은 순서로 정렬하여 선택
+func (SmallestFirst) Select(utxos []UnspentOutput, amount int, outputs []TxOutput, rate FeeRate) (CoinSelection, error) {
+	return accumulate(sortedByValue(utxos, false), amount, outputs, rate)
+}
+
+// 무작위로 섞은 순서로 선택
+func (RandomSelector) Select(utxos []UnspentOutput, amount int, outputs []TxOutput, rate FeeRate) (CoinSelection, error) {
+	shuffled := append([]UnspentOutput{}, utxos...)
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	random.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return accumulate(shuffled, amount, outputs, rate)
+}
+
+// 깊이 우선 탐색으로 잔돈 출력 없이 금액과 수수료를 맞추는 조합을 찾음
+// 잔돈 출력을 만드는 비용 이하의 초과분은 수수료로 사용
+func (bnb BranchAndBound) Select(utxos []UnspentOutput, amount int, outputs []TxOutput, rate FeeRate) (CoinSelection, error) {
+	// 큰 금액부터 탐색
+	sorted := sortedByValue(utxos, true)
+
+	// 각 위치 이후 UTXO 금액의 합
+	remaining := make([]int, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + sorted[i].Output.Value
+	}
+
+	costOfChange := rate.FeeForSize(estimateSize(0, outputs)+txOutputSize) - rate.FeeForSize(estimateSize(0, outputs))
+	tries := 0
+
+	var picked []int
+	var search func(index, total int) bool
+	search = func(index, total int) bool {
+		tries++
+		if tries > bnbMaxTries {
+			return false
+		}
+
+		if len(picked) > 0 {
+			excess := total - amount - rate.FeeForSize(estimateSize(len(picked), outputs))
+			// 초과분이 잔돈 비용 이하이면 성공
+			if excess >= 0 && excess <= costOfChange {
+				return true
+			}
+			// 더 추가해도 초과분만 커짐
+			if excess > costOfChange {
+				return false
+			}
+		}
+
+		// 남은 UTXO를 모두 더해도 부족하면 중단
+		if index == len(sorted) || total+remaining[index] < amount+rate.FeeForSize(estimateSize(len(picked)+1, outputs)) {
+			return false
+		}
+
+		// 현재 UTXO를 포함하는 경우
+		picked = append(picked, index)
+		if search(index+1, total+sorted[index].Output.Value) {
+			return true
+		}
+		picked = picked[:len(picked)-1]
+
+		// 현재 UTXO를 제외하는 경우
+		return search(index+1, total)
+	}
+
+	if search(0, 0) {
+		var inputs []UnspentOutput
+		total := 0
+		for _, index := range picked {
+			inputs = append(inputs, sorted[index])
+			total += sorted[index].Output.Value
+		}
+
+		return CoinSelection{inputs, total - amount, 0}, nil
+	}
+
+	if bnb.Fallback != nil {
+		return bnb.Fallback.Select(utxos, amount, outputs, rate)
+	}
+
+	return CoinSelection{}, ErrNoExactMatch
+}
+
+// 선택된 UTXO로 수수료와 잔돈을 계산하는 함수(outputs는 잔돈을 제외한 출력)
+// 잔돈 출력을 만들 만큼 남지 않으면 남는 금액을 수수료로 사용
+func NewCoinSelection(inputs []UnspentOutput, amount int, outputs []TxOutput, rate FeeRate) (CoinSelection, error) {
+	total := 0
+	for _, utxo := range inputs {
+		total += utxo.Output.Value
+	}
+
+	size := estimateSize(len(inputs), outputs)
+	if total < amount+rate.FeeForSize(size) {
+		return CoinSelection{}, ErrInsufficientFunds
+	}
+
+	// 잔돈 출력을 추가했을 때의 수수료
+	fee := rate.FeeForSize(size + txOutputSize)
+	if change := total - amount - fee; change > 0 {
+		return CoinSelection{inputs, fee, change}, nil
+	}
+
+	return CoinSelection{inputs, total - amount, 0}, nil
+}
+
+// 주어진 순서대로 금액과 수수료가 채워질 때까지 UTXO를 선택하는 함수
+func accumulate(utxos []UnspentOutput, amount int, outputs []TxOutput, rate FeeRate) (CoinSelection, error) {
+	for i := range utxos {
+		selection, err := NewCoinSelection(utxos[:i+1], amount, outputs, rate)
+		if err == nil {
+			return selection, nil
+		}
+	}
+
+	return CoinSelection{}, ErrInsufficientFunds
+}
+
+// 금액 순서(descending이면 내림차순)로 정렬된 복사본을 반환하는 함수
+func sortedByValue(utxos []UnspentOutput, descending bool) []UnspentOutput {
+	sorted := append([]UnspentOutput{}, utxos...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].Output.Value > sorted[j].Output.Value
+		}
+		return sorted[i].Output.Value < sorted[j].Output.Value
+	})
+
+	return sorted
+}
@@ -0,0 +1,276 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// 1바이트당 1의 수수료율(수수료가 크기 추정값과 같음)
+const testFeeRate FeeRate = 1000
+
+// 주어진 금액의 테스트용 UTXO
+func testUTXOs(values ...int) []UnspentOutput {
+	var utxos []UnspentOutput
+	for i, value := range values {
+		utxos = append(utxos, UnspentOutput{TxID: []byte{byte(i + 1)}, Vout: 0, Output: TxOutput{value, nil}})
+	}
+	return utxos
+}
+
+// 테스트용 지불 출력 n개
+func testPaymentOutputs(n int) []TxOutput {
+	outputs := make([]TxOutput, n)
+	for i := range outputs {
+		outputs[i] = TxOutput{1, PayToPubKeyHashScript(bytes.Repeat([]byte{byte(i)}, 20))}
+	}
+	return outputs
+}
+
+// 선택된 UTXO의 금액 목록
+func selectedValues(selection CoinSelection) []int {
+	var values []int
+	for _, utxo := range selection.Inputs {
+		values = append(values, utxo.Output.Value)
+	}
+	return values
+}
+
+// 선택 결과의 금액, 수수료, 잔돈이 맞는지 확인하는 함수
+func checkBalance(t *testing.T, selection CoinSelection, amount int) {
+	t.Helper()
+
+	total := 0
+	for _, value := range selectedValues(selection) {
+		total += value
+	}
+	if total != amount+selection.Fee+selection.Change {
+		t.Fatalf("inputs %d do not equal amount %d + fee %d + change %d", total, amount, selection.Fee, selection.Change)
+	}
+}
+
+func TestFeeRate(t *testing.T) {
+	tests := []struct {
+		name string
+		rate FeeRate
+		size int
+		fee  int
+	}{
+		{"zero rate", 0, 500, 0},
+		{"rounds up", 1, 1, 1},
+		{"exactly 1000 bytes", 1, 1000, 1},
+		{"just over 1000 bytes", 1, 1001, 2},
+		{"one per byte", testFeeRate, 224, 224},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.rate.FeeForSize(test.size); got != test.fee {
+				t.Fatalf("FeeForSize(%d) = %d, want %d", test.size, got, test.fee)
+			}
+		})
+	}
+
+	if got, want := testFeeRate.Fee(2, 3), txOverheadSize+2*txInputSize+3*txOutputSize; got != want {
+		t.Fatalf("Fee(2, 3) = %d, want %d", got, want)
+	}
+}
+
+func TestDataOutputSize(t *testing.T) {
+	data, err := NewDataOutput(bytes.Repeat([]byte{1}, MaxDataCarrierSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// OP_RETURN, OP_PUSHDATA1, 길이, 데이터
+	if got, want := outputSize(*data), dataOutputOverhead+3+MaxDataCarrierSize; got != want {
+		t.Fatalf("data output size = %d, want %d", got, want)
+	}
+	if outputSize(*data) <= txOutputSize {
+		t.Fatalf("data output size %d is not larger than a payment output", outputSize(*data))
+	}
+
+	// 데이터 출력이 있는 트랜잭션의 수수료는 데이터 출력의 실제 크기로 계산
+	outputs := append(testPaymentOutputs(1), *data)
+	selection, err := NewCoinSelection(testUTXOs(10000), 5000, outputs, testFeeRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := txOverheadSize + txInputSize + txOutputSize + outputSize(*data) + txOutputSize
+	if selection.Fee != want {
+		t.Fatalf("fee = %d, want %d", selection.Fee, want)
+	}
+
+	tx := &Transaction{Inputs: make([]TxInput, 1), Outputs: append(outputs, TxOutput{4000, nil})}
+	if got := tx.EstimatedSize(); got != want {
+		t.Fatalf("EstimatedSize = %d, want %d", got, want)
+	}
+}
+
+func TestNewCoinSelection(t *testing.T) {
+	const amount = 5000
+	noChangeFee := testFeeRate.Fee(1, 1)
+	changeFee := testFeeRate.Fee(1, 2)
+
+	tests := []struct {
+		name   string
+		input  int
+		fee    int
+		change int
+		err    error
+	}{
+		{"change output", 10000, changeFee, 10000 - amount - changeFee, nil},
+		{"change of one", amount + changeFee + 1, changeFee, 1, nil},
+		{"excess too small for change is fee", amount + changeFee, changeFee, 0, nil},
+		{"excess below change cost is fee", amount + noChangeFee + 10, noChangeFee + 10, 0, nil},
+		{"exact amount and fee", amount + noChangeFee, noChangeFee, 0, nil},
+		{"fee not covered", amount + noChangeFee - 1, 0, 0, ErrInsufficientFunds},
+		{"amount not covered", amount - 1, 0, 0, ErrInsufficientFunds},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selection, err := NewCoinSelection(testUTXOs(test.input), amount, testPaymentOutputs(1), testFeeRate)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+			if selection.Fee != test.fee || selection.Change != test.change {
+				t.Fatalf("fee %d, change %d, want fee %d, change %d", selection.Fee, selection.Change, test.fee, test.change)
+			}
+			checkBalance(t, selection, amount)
+		})
+	}
+}
+
+func TestSortedSelectors(t *testing.T) {
+	utxos := testUTXOs(1000, 5000, 3000, 20000)
+
+	tests := []struct {
+		name     string
+		selector CoinSelector
+		amount   int
+		values   []int
+	}{
+		{"largest one input", LargestFirst{}, 2500, []int{20000}},
+		{"largest two inputs", LargestFirst{}, 24000, []int{20000, 5000}},
+		{"smallest two inputs", SmallestFirst{}, 2500, []int{1000, 3000}},
+		{"smallest all inputs", SmallestFirst{}, 28000, []int{1000, 3000, 5000, 20000}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selection, err := test.selector.Select(utxos, test.amount, testPaymentOutputs(1), testFeeRate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := selectedValues(selection); !equalInts(got, test.values) {
+				t.Fatalf("selected %v, want %v", got, test.values)
+			}
+			checkBalance(t, selection, test.amount)
+		})
+	}
+
+	// 모든 UTXO로도 금액과 수수료를 낼 수 없으면 실패
+	for _, selector := range []CoinSelector{LargestFirst{}, SmallestFirst{}, RandomSelector{}, BranchAndBound{Fallback: LargestFirst{}}} {
+		if _, err := selector.Select(utxos, 29000, testPaymentOutputs(1), testFeeRate); !errors.Is(err, ErrInsufficientFunds) {
+			t.Fatalf("%T: got %v, want %v", selector, err, ErrInsufficientFunds)
+		}
+	}
+}
+
+func TestRandomSelector(t *testing.T) {
+	utxos := testUTXOs(1000, 2000, 3000, 4000, 5000, 6000)
+	const amount = 9000
+
+	orders := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		selection, err := RandomSelector{}.Select(utxos, amount, testPaymentOutputs(1), testFeeRate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkBalance(t, selection, amount)
+
+		// 마지막 입력을 추가하기 전에는 금액이 모자랐어야 함
+		inputs := selection.Inputs
+		if _, err := NewCoinSelection(inputs[:len(inputs)-1], amount, testPaymentOutputs(1), testFeeRate); err == nil {
+			t.Fatalf("selected %v, more inputs than needed", selectedValues(selection))
+		}
+
+		var order []byte
+		for _, utxo := range inputs {
+			order = append(order, utxo.TxID...)
+		}
+		orders[string(order)] = true
+	}
+
+	if len(orders) < 2 {
+		t.Fatal("random selector always picked the same inputs")
+	}
+}
+
+func TestBranchAndBound(t *testing.T) {
+	utxos := testUTXOs(1000, 2000, 5000, 7000)
+
+	// 7000과 1000의 합에서 수수료를 뺀 금액보다 잔돈 비용 미만으로 적게 보내면 잔돈 없이 맞음
+	amount := 8000 - testFeeRate.Fee(2, 1) - 10
+	selection, err := BranchAndBound{}.Select(utxos, amount, testPaymentOutputs(1), testFeeRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := selectedValues(selection); !equalInts(got, []int{7000, 1000}) {
+		t.Fatalf("selected %v, want [7000 1000]", got)
+	}
+	if selection.Change != 0 || selection.Fee != testFeeRate.Fee(2, 1)+10 {
+		t.Fatalf("fee %d, change %d, want fee %d without change", selection.Fee, selection.Change, testFeeRate.Fee(2, 1)+10)
+	}
+	checkBalance(t, selection, amount)
+
+	// 같은 금액을 큰 금액부터 선택하면 잔돈이 생김
+	if largest, _ := (LargestFirst{}).Select(utxos, amount, testPaymentOutputs(1), testFeeRate); largest.Change == 0 {
+		t.Fatal("largest first found the exact match too")
+	}
+
+	// 잔돈 없이 맞는 조합이 없으면 대체 전략을 사용하고, 대체 전략이 없으면 실패
+	if _, err := (BranchAndBound{}).Select(utxos, 100, testPaymentOutputs(1), testFeeRate); !errors.Is(err, ErrNoExactMatch) {
+		t.Fatalf("got %v, want %v", err, ErrNoExactMatch)
+	}
+	selector, err := NewCoinSelector("bnb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback, err := selector.Select(utxos, 100, testPaymentOutputs(1), testFeeRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := selectedValues(fallback); !equalInts(got, []int{7000}) || fallback.Change == 0 {
+		t.Fatalf("fallback selected %v with change %d, want [7000] with change", got, fallback.Change)
+	}
+	checkBalance(t, fallback, 100)
+}
+
+func TestNewCoinSelector(t *testing.T) {
+	for _, name := range []string{"largest", "smallest", "random", "bnb"} {
+		if _, err := NewCoinSelector(name); err != nil {
+			t.Fatalf("NewCoinSelector(%q): %v", name, err)
+		}
+	}
+	if _, err := NewCoinSelector("biggest"); err == nil {
+		t.Fatal("NewCoinSelector accepted an unknown strategy")
+	}
+}
+
+// 두 정수 목록이 같은지 확인하는 함수
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
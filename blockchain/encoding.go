@@ -0,0 +1,327 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// 바이너리 직렬화 형식의 버전
+// 직렬화된 데이터의 첫 바이트에 기록되어 형식이 바뀌어도 이전 데이터를 구분할 수 있도록 함
+// 버전 2부터 출력과 입력에 공개 키 해시, 서명, 공개 키 대신 스크립트를 저장
+// 버전 3부터 트랜잭션 버전과 LockTime, 입력의 시퀀스 번호를 저장
+// 버전 4부터 UTXO 항목에 출력을 만든 블록 높이와 코인베이스 여부를 저장
+// 버전 5부터 UTXO 항목을 트랜잭션이 아닌 출력마다 저장
+const encodingVersion = 5
+
+// 읽을 수 있는 가장 오래된 형식 버전(마이그레이션에서 사용)
+const legacyEncodingVersion = 1
+
+// 스크립트를 저장하지만 LockTime과 시퀀스 번호가 없는 형식 버전(마이그레이션에서 사용)
+const scriptEncodingVersion = 2
+
+// UTXO 항목에 블록 높이와 코인베이스 여부가 없는 형식 버전(마이그레이션에서 사용)
+const lockTimeEncodingVersion = 3
+
+// 트랜잭션별 UTXO 항목에 높이와 코인베이스 여부를 저장하던 형식 버전(마이그레이션에서 사용)
+const utxoHeightEncodingVersion = 4
+
+// 블록과 트랜잭션을 기록하는 형식 버전
+// 블록 해시와 트랜잭션 ID는 직렬화된 데이터로 계산되므로 블록과 트랜잭션 형식이 바뀌지 않은 버전 4, 5에서는 버전 3으로 기록
+const txEncodingVersion = lockTimeEncodingVersion
+
+var (
+	// 길이 정보가 남은 데이터보다 큰 경우의 에러
+	errShortData = errors.New("encoded data is too short")
+	// 디코딩 후 데이터가 남은 경우의 에러
+	errTrailingData = errors.New("unexpected trailing data after decoding")
+)
+
+// 바이너리 직렬화를 위한 인코더
+type encoder struct {
+	buf bytes.Buffer
+}
+
+// 부호 없는 정수를 가변 길이(varint)로 기록
+func (e *encoder) writeUvarint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	e.buf.Write(tmp[:n])
+}
+
+// 부호 있는 정수를 지그재그 가변 길이(varint)로 기록
+func (e *encoder) writeVarint(v int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v)
+	e.buf.Write(tmp[:n])
+}
+
+// 바이트 슬라이스를 길이 접두사와 함께 기록
+func (e *encoder) writeBytes(b []byte) {
+	e.writeUvarint(uint64(len(b)))
+	e.buf.Write(b)
+}
+
+// 단일 바이트 기록
+func (e *encoder) writeByte(b byte) {
+	e.buf.WriteByte(b)
+}
+
+// 인코딩된 결과 반환
+func (e *encoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// 바이너리 역직렬화를 위한 디코더
+// 첫 번째 에러가 발생하면 이후의 읽기는 모두 무시되고 err에 에러가 남음
+type decoder struct {
+	r   *bytes.Reader
+	err error
+	// readVersion으로 읽은 형식 버전
+	version byte
+}
+
+// 새로운 디코더 생성
+func newDecoder(data []byte) *decoder {
+	return &decoder{r: bytes.NewReader(data)}
+}
+
+// 가변 길이 부호 없는 정수 읽기
+func (d *decoder) readUvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		d.err = err
+	}
+	return v
+}
+
+// 가변 길이 부호 있는 정수 읽기
+func (d *decoder) readVarint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	if err != nil {
+		d.err = err
+	}
+	return v
+}
+
+// 가변 길이 정수를 int로 읽기
+func (d *decoder) readInt() int {
+	return int(d.readVarint())
+}
+
+// 가변 길이 정수를 uint32로 읽기(범위를 넘는 값은 거부)
+func (d *decoder) readUint32() uint32 {
+	v := d.readUvarint()
+	if d.err == nil && v > math.MaxUint32 {
+		d.err = fmt.Errorf("value %d overflows uint32", v)
+		return 0
+	}
+	return uint32(v)
+}
+
+// 개수 정보 읽기(남은 데이터보다 큰 개수는 거부)
+func (d *decoder) readCount() int {
+	n := d.readUvarint()
+	if d.err == nil && n > uint64(d.r.Len()) {
+		d.err = errShortData
+		return 0
+	}
+	return int(n)
+}
+
+// 길이 접두사가 붙은 바이트 슬라이스 읽기
+func (d *decoder) readBytes() []byte {
+	n := d.readCount()
+	if d.err != nil {
+		return nil
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.err = err
+		return nil
+	}
+	return b
+}
+
+// 단일 바이트 읽기
+func (d *decoder) readByte() byte {
+	if d.err != nil {
+		return 0
+	}
+	b, err := d.r.ReadByte()
+	if err != nil {
+		d.err = err
+	}
+	return b
+}
+
+// 형식 버전을 읽고 지원하는 버전인지 확인
+// 이전 버전의 데이터는 읽을 때 현재 형식으로 변환됨
+func (d *decoder) readVersion() byte {
+	v := d.readByte()
+	if d.err == nil && (v < legacyEncodingVersion || v > encodingVersion) {
+		d.err = fmt.Errorf("unsupported encoding version %d", v)
+	}
+	d.version = v
+	return v
+}
+
+// 디코딩을 마치고 에러 또는 남은 데이터를 확인
+func (d *decoder) finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.r.Len() != 0 {
+		return errTrailingData
+	}
+	return nil
+}
+
+// 트랜잭션 입력 인코딩
+func (in *TxInput) encode(e *encoder) {
+	e.writeBytes(in.ID)
+	e.writeVarint(int64(in.Out))
+	e.writeBytes(in.ScriptSig)
+	e.writeUvarint(uint64(in.Sequence))
+}
+
+// 트랜잭션 입력 디코딩
+func (in *TxInput) decode(d *decoder) {
+	in.ID = d.readBytes()
+	in.Out = d.readInt()
+
+	if d.version == legacyEncodingVersion {
+		// 버전 1은 서명과 공개 키를 따로 저장
+		signature := d.readBytes()
+		pubKey := d.readBytes()
+		in.ScriptSig = legacyScriptSig(in, signature, pubKey)
+		in.Sequence = MaxTxInSequenceNum
+		return
+	}
+
+	in.ScriptSig = d.readBytes()
+
+	// 버전 3 이전의 입력은 시퀀스 번호가 없으므로 최종 시퀀스 번호 사용
+	if d.version < lockTimeEncodingVersion {
+		in.Sequence = MaxTxInSequenceNum
+		return
+	}
+	in.Sequence = d.readUint32()
+}
+
+// 트랜잭션 출력 인코딩
+func (out *TxOutput) encode(e *encoder) {
+	e.writeVarint(int64(out.Value))
+	e.writeBytes(out.ScriptPubKey)
+}
+
+// 트랜잭션 출력 디코딩
+func (out *TxOutput) decode(d *decoder) {
+	out.Value = d.readInt()
+
+	if d.version == legacyEncodingVersion {
+		// 버전 1은 공개 키 해시만 저장
+		out.ScriptPubKey = PayToPubKeyHashScript(d.readBytes())
+		return
+	}
+
+	out.ScriptPubKey = d.readBytes()
+}
+
+// 서명과 공개 키를 따로 저장하던 이전 형식의 입력을 잠금 해제 스크립트로 변환하는 함수
+// 코인베이스 입력의 공개 키 자리에는 임의의 데이터가 있으므로 그대로 사용
+func legacyScriptSig(in *TxInput, signature, pubKey []byte) []byte {
+	if len(in.ID) == 0 && in.Out == -1 {
+		return pubKey
+	}
+
+	return PayToPubKeyHashUnlockScript(signature, pubKey)
+}
+
+// 트랜잭션 인코딩
+func (tx *Transaction) encode(e *encoder) {
+	e.writeBytes(tx.ID)
+	e.writeVarint(int64(tx.Version))
+
+	e.writeUvarint(uint64(len(tx.Inputs)))
+	for i := range tx.Inputs {
+		tx.Inputs[i].encode(e)
+	}
+
+	e.writeUvarint(uint64(len(tx.Outputs)))
+	for i := range tx.Outputs {
+		tx.Outputs[i].encode(e)
+	}
+
+	e.writeUvarint(uint64(tx.LockTime))
+}
+
+// 트랜잭션 디코딩
+func (tx *Transaction) decode(d *decoder) {
+	tx.ID = d.readBytes()
+
+	// 버전 3 이전의 트랜잭션은 버전 1이며 LockTime이 없음
+	hasLockTime := d.version >= lockTimeEncodingVersion
+	tx.Version = 1
+	if hasLockTime {
+		tx.Version = d.readInt()
+	}
+
+	tx.Inputs = make([]TxInput, d.readCount())
+	for i := range tx.Inputs {
+		tx.Inputs[i].decode(d)
+	}
+
+	tx.Outputs = make([]TxOutput, d.readCount())
+	for i := range tx.Outputs {
+		tx.Outputs[i].decode(d)
+	}
+
+	if hasLockTime {
+		tx.LockTime = d.readUint32()
+		return
+	}
+
+	// 이전 형식의 트랜잭션은 해시가 유지되도록 형식 버전을 기록
+	tx.encoding = d.version
+}
+
+// 블록 인코딩
+func (b *Block) encode(e *encoder) {
+	e.writeVarint(b.Timestamp)
+	e.writeBytes(b.Hash)
+	e.writeBytes(b.PrevHash)
+	e.writeVarint(int64(b.Nonce))
+	e.writeVarint(int64(b.Height))
+
+	e.writeUvarint(uint64(len(b.Transactions)))
+	for _, tx := range b.Transactions {
+		tx.encode(e)
+	}
+}
+
+// 블록 디코딩
+func (b *Block) decode(d *decoder) {
+	b.Timestamp = d.readVarint()
+	b.Hash = d.readBytes()
+	b.PrevHash = d.readBytes()
+	b.Nonce = d.readInt()
+	b.Height = d.readInt()
+
+	b.Transactions = make([]*Transaction, d.readCount())
+	for i := range b.Transactions {
+		tx := &Transaction{}
+		tx.decode(d)
+		b.Transactions[i] = tx
+	}
+}
@@ -0,0 +1,222 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 입력, 출력, 잠금 정보가 모두 채워진 현재 형식의 트랜잭션을 만드는 함수
+func testTransaction(t *testing.T) *Transaction {
+	t.Helper()
+
+	w := wallet.MakeWallet()
+	data, err := NewDataOutput([]byte("round trip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := &Transaction{
+		Version: TxVersion,
+		Inputs: []TxInput{
+			{[]byte{1, 2, 3}, 0, PayToPubKeyHashUnlockScript(bytes.Repeat([]byte{7}, 64), w.PublicKey), MaxRBFSequence},
+			{[]byte{4, 5, 6}, 2, PayToPubKeyHashUnlockScript(bytes.Repeat([]byte{8}, 64), w.PublicKey), 10},
+		},
+		Outputs: []TxOutput{
+			*NewTXOutput(15, string(w.Address())),
+			{5, PayToScriptHashScript(bytes.Repeat([]byte{9}, 20))},
+			*data,
+		},
+		LockTime: 1234,
+	}
+	tx.ID = tx.Hash()
+
+	return tx
+}
+
+func TestTransactionSerializeRoundTrip(t *testing.T) {
+	tx := testTransaction(t)
+
+	decoded := DeserializeTransaction(tx.Serialize())
+	if !reflect.DeepEqual(&decoded, tx) {
+		t.Fatalf("decoded transaction %+v, want %+v", decoded, *tx)
+	}
+	if !bytes.Equal(decoded.Hash(), tx.ID) {
+		t.Fatal("decoded transaction hashes differently")
+	}
+}
+
+func TestBlockSerializeRoundTrip(t *testing.T) {
+	coinbase := CoinbaseTx(string(wallet.MakeWallet().Address()), "round trip")
+	block := &Block{1700000000, nil, []*Transaction{coinbase, testTransaction(t)}, []byte{1, 2, 3}, 42, 7}
+	block.Hash = NewProof(block).Hash()
+
+	decoded := Deserialize(block.Serialize())
+	if !reflect.DeepEqual(decoded, block) {
+		t.Fatalf("decoded block %+v, want %+v", decoded, block)
+	}
+	if !bytes.Equal(NewProof(decoded).Hash(), block.Hash) {
+		t.Fatal("decoded block hashes differently")
+	}
+}
+
+func TestTxOutputsSerializeRoundTrip(t *testing.T) {
+	outs := TxOutputs{Outputs: testTransaction(t).Outputs, Height: 321, Coinbase: true}
+
+	decoded := DeserializeOutputs(outs.Serialize())
+	if !reflect.DeepEqual(decoded, outs) {
+		t.Fatalf("decoded outputs %+v, want %+v", decoded, outs)
+	}
+}
+
+// 스키마 버전 2 이전 코드의 구조(서명, 공개 키, 공개 키 해시)로 트랜잭션을 직렬화하는 함수
+// format은 JSON, 버전 1 또는 버전 2(스크립트) 형식
+func encodeOldTransaction(tx legacyJSONTransaction, format byte) []byte {
+	if format == jsonEncodingVersion {
+		data, _ := json.Marshal(tx)
+		return data
+	}
+
+	e := &encoder{}
+	e.writeByte(format)
+	encodeOldTransactionBody(e, tx, format)
+
+	return e.Bytes()
+}
+
+// 형식 버전 1, 2의 트랜잭션 본문 인코딩
+func encodeOldTransactionBody(e *encoder, tx legacyJSONTransaction, format byte) {
+	e.writeBytes(tx.ID)
+	e.writeUvarint(uint64(len(tx.Inputs)))
+	for _, in := range tx.Inputs {
+		e.writeBytes(in.ID)
+		e.writeVarint(int64(in.Out))
+		switch {
+		case format == legacyEncodingVersion:
+			e.writeBytes(in.Signature)
+			e.writeBytes(in.PubKey)
+		case in.Out == -1:
+			e.writeBytes(in.PubKey)
+		case in.Signature == nil && in.PubKey != nil:
+			// 버전 2는 서명 대상 해시를 계산할 때 이전 출력의 잠금 스크립트를 넣음
+			e.writeBytes(PayToPubKeyHashScript(in.PubKey))
+		case in.Signature == nil:
+			e.writeBytes(nil)
+		default:
+			e.writeBytes(PayToPubKeyHashUnlockScript(in.Signature, in.PubKey))
+		}
+	}
+
+	e.writeUvarint(uint64(len(tx.Outputs)))
+	for _, out := range tx.Outputs {
+		e.writeVarint(int64(out.Value))
+		if format == legacyEncodingVersion {
+			e.writeBytes(out.PubKeyHash)
+		} else {
+			e.writeBytes(PayToPubKeyHashScript(out.PubKeyHash))
+		}
+	}
+}
+
+// 이전 코드와 같은 방식으로 코인베이스와 이를 사용하는 서명된 트랜잭션을 담아 채굴한 블록을 주어진 형식으로 직렬화하는 함수
+func oldTestBlock(t *testing.T, format byte) []byte {
+	t.Helper()
+
+	w := wallet.MakeWallet()
+	pubKeyHash := wallet.PublicKeyHash(w.PublicKey)
+
+	// 트랜잭션 해시는 ID를 비운 복사본의 직렬화로 계산
+	hash := func(tx legacyJSONTransaction) []byte {
+		tx.ID = []byte{}
+		hash := sha256.Sum256(encodeOldTransaction(tx, format))
+		return hash[:]
+	}
+
+	coinbase := legacyJSONTransaction{
+		Inputs:  []legacyJSONTxInput{{[]byte{}, -1, nil, []byte("old coinbase")}},
+		Outputs: []legacyJSONTxOutput{{20, pubKeyHash}},
+	}
+	coinbase.ID = hash(coinbase)
+
+	spend := legacyJSONTransaction{
+		Inputs:  []legacyJSONTxInput{{coinbase.ID, 0, nil, w.PublicKey}},
+		Outputs: []legacyJSONTxOutput{{20, pubKeyHash}},
+	}
+	spend.ID = hash(spend)
+
+	// 서명 대상은 서명과 공개 키를 지우고 공개 키 자리에 이전 출력의 공개 키 해시를 넣은 복사본의 해시
+	trimmed := spend
+	trimmed.Inputs = []legacyJSONTxInput{{coinbase.ID, 0, nil, pubKeyHash}}
+	spend.Inputs[0].Signature = signHash(w.DeserializePrivateKey(w.PrivateKey), hash(trimmed))
+
+	// 작업 증명은 트랜잭션 직렬화의 Merkle 루트로 계산
+	block := legacyJSONBlock{Timestamp: 1600000000, Transactions: []*legacyJSONTransaction{&coinbase, &spend}, PrevHash: []byte{}}
+	root := NewMerkleTree([][]byte{encodeOldTransaction(coinbase, format), encodeOldTransaction(spend, format)}).RootNode.Data
+	target := newProofWithDifficulty(&Block{}, ActiveParams.Difficulty).Target
+	for ; ; block.Nonce++ {
+		hash := sha256.Sum256(blockHeaderData(block.PrevHash, root, block.Nonce, ActiveParams.Difficulty))
+		if new(big.Int).SetBytes(hash[:]).Cmp(target) == -1 {
+			block.Hash = hash[:]
+			break
+		}
+	}
+
+	if format == jsonEncodingVersion {
+		data, err := json.Marshal(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	e := &encoder{}
+	e.writeByte(format)
+	e.writeVarint(block.Timestamp)
+	e.writeBytes(block.Hash)
+	e.writeBytes(block.PrevHash)
+	e.writeVarint(int64(block.Nonce))
+	e.writeVarint(int64(block.Height))
+	e.writeUvarint(uint64(len(block.Transactions)))
+	for _, tx := range block.Transactions {
+		encodeOldTransactionBody(e, *tx, format)
+	}
+
+	return e.Bytes()
+}
+
+func TestOldBlocksKeepHashesAndSignatures(t *testing.T) {
+	formats := map[string]byte{"json": jsonEncodingVersion, "v1": legacyEncodingVersion, "v2": scriptEncodingVersion}
+
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			data := oldTestBlock(t, format)
+
+			// 마이그레이션 후에도 블록은 원래 형식으로 직렬화되어야 함
+			block := Deserialize(data)
+			if !bytes.Equal(block.Serialize(), data) {
+				t.Fatal("block was not serialized in its original encoding")
+			}
+
+			pow := NewProof(block)
+			if !bytes.Equal(pow.Hash(), block.Hash) || !pow.Validate() {
+				t.Fatal("proof of work does not verify")
+			}
+
+			coinbase, spend := block.Transactions[0], block.Transactions[1]
+			if err := spend.verifyScripts([]TxOutput{coinbase.Outputs[0]}); err != nil {
+				t.Fatalf("signature does not verify: %v", err)
+			}
+
+			// 트랜잭션만 따로 직렬화해도 원래 형식이 유지되어야 함
+			decoded := DeserializeTransaction(spend.Serialize())
+			if err := decoded.verifyScripts([]TxOutput{coinbase.Outputs[0]}); err != nil {
+				t.Fatalf("signature does not verify after transaction round trip: %v", err)
+			}
+		})
+	}
+}
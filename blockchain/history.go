@@ -0,0 +1,73 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// 지갑 트랜잭션 내역 항목
+type HistoryEntry struct {
+	Height    int
+	Timestamp int64
+	TxID      []byte
+	// 공개 키 해시(16진수)별 잔액 변화량(받은 금액 - 보낸 금액)
+	Deltas map[string]int
+}
+
+// 주어진 공개 키 해시들과 관련된 트랜잭션 내역을 오래된 순서로 찾는 함수
+// pubKeyHashes는 16진수 공개 키 해시를 키로 사용
+func (chain *BlockChain) FindHistory(pubKeyHashes map[string]bool) []HistoryEntry {
+	// 블록을 Genesis부터 순서대로 처리하기 위해 역순으로 수집
+	var blocks []*Block
+	iter := chain.Iterator()
+	for {
+		block := iter.Next()
+		blocks = append(blocks, block)
+
+		if len(block.PrevHash) == 0 {
+			break
+		}
+	}
+
+	// 관련된 출력(트랜잭션 ID:인덱스)의 소유자와 금액
+	type trackedOutput struct {
+		pubKeyHash string
+		value      int
+	}
+	tracked := make(map[string]trackedOutput)
+
+	var history []HistoryEntry
+	for i := len(blocks) - 1; i >= 0; i-- {
+		block := blocks[i]
+
+		for _, tx := range block.Transactions {
+			deltas := make(map[string]int)
+
+			// 관련된 출력을 소비한 입력
+			if !tx.IsCoinbase() {
+				for _, in := range tx.Inputs {
+					outpoint := fmt.Sprintf("%x:%d", in.ID, in.Out)
+					if out, ok := tracked[outpoint]; ok {
+						deltas[out.pubKeyHash] -= out.value
+						delete(tracked, outpoint)
+					}
+				}
+			}
+
+			// 관련된 공개 키 해시로 잠긴 출력
+			for outIdx, out := range tx.Outputs {
+				pubKeyHash := hex.EncodeToString(out.AddressHash())
+				if pubKeyHashes[pubKeyHash] {
+					deltas[pubKeyHash] += out.Value
+					tracked[fmt.Sprintf("%x:%d", tx.ID, outIdx)] = trackedOutput{pubKeyHash, out.Value}
+				}
+			}
+
+			if len(deltas) > 0 {
+				history = append(history, HistoryEntry{block.Height, block.Timestamp, tx.ID, deltas})
+			}
+		}
+	}
+
+	return history
+}
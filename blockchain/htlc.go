@@ -0,0 +1,211 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 비밀 값의 길이(바이트)
+const HTLCSecretSize = 32
+
+// 해시 시간 잠금 계약(HTLC)
+// 비밀 값을 아는 수신자는 언제든 출력을 사용할 수 있고, LockTime 이후에는 환불 주소의 소유자가 돌려받을 수 있음
+type HTLC struct {
+	// 비밀 값의 SHA-256 해시
+	SecretHash []byte
+	// 비밀 값으로 출력을 받을 수신자의 공개 키 해시
+	RecipientPubKeyHash []byte
+	// LockTime 이후 출력을 돌려받을 공개 키 해시
+	RefundPubKeyHash []byte
+	// 환불이 가능해지는 블록 높이 또는 유닉스 시간
+	LockTime uint32
+}
+
+// HTLC 관련 에러
+var (
+	ErrNotHTLC     = errors.New("script is not a hash time-locked contract")
+	ErrWrongSecret = errors.New("secret does not match the contract's secret hash")
+)
+
+// HTLC의 redeem script 생성
+// OP_IF OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 <비밀 값 해시> OP_EQUALVERIFY OP_DUP OP_HASH160 <수신자 공개 키 해시>
+// OP_ELSE <LockTime> OP_CHECKLOCKTIMEVERIFY OP_DROP OP_DUP OP_HASH160 <환불 공개 키 해시>
+// OP_ENDIF OP_EQUALVERIFY OP_CHECKSIG
+func (c *HTLC) Script() []byte {
+	return NewScriptBuilder().
+		AddOp(OpIf).
+		AddOp(OpSize).AddData(scriptNumBytes(HTLCSecretSize)).AddOp(OpEqualVerify).
+		AddOp(OpSHA256).AddData(c.SecretHash).AddOp(OpEqualVerify).
+		AddOp(OpDup).AddOp(OpHash160).AddData(c.RecipientPubKeyHash).
+		AddOp(OpElse).
+		AddData(scriptNumBytes(int(c.LockTime))).AddOp(OpCheckLockTimeVerify).AddOp(OpDrop).
+		AddOp(OpDup).AddOp(OpHash160).AddData(c.RefundPubKeyHash).
+		AddOp(OpEndIf).
+		AddOp(OpEqualVerify).AddOp(OpCheckSig).
+		Script()
+}
+
+// redeem script에서 HTLC를 추출하는 함수
+func ExtractHTLC(script []byte) (*HTLC, error) {
+	ops, err := parseScript(script)
+	if err != nil || len(ops) != 20 {
+		return nil, ErrNotHTLC
+	}
+
+	// 데이터 위치를 제외한 명령어가 템플릿과 일치해야 함
+	template := []byte{
+		OpIf, OpSize, 0, OpEqualVerify, OpSHA256, 0, OpEqualVerify, OpDup, OpHash160, 0,
+		OpElse, 0, OpCheckLockTimeVerify, OpDrop, OpDup, OpHash160, 0, OpEndIf, OpEqualVerify, OpCheckSig,
+	}
+	for i, opcode := range template {
+		if opcode != 0 && ops[i].opcode != opcode {
+			return nil, ErrNotHTLC
+		}
+	}
+
+	secretSize, err := scriptNum(ops[2].data)
+	if err != nil || secretSize != HTLCSecretSize {
+		return nil, ErrNotHTLC
+	}
+	lockTime, err := parseScriptNum(ops[11].data, maxLockTimeNumLength)
+	if err != nil || lockTime <= 0 || lockTime > int(MaxTxInSequenceNum) {
+		return nil, ErrNotHTLC
+	}
+
+	c := &HTLC{
+		SecretHash:          ops[5].data,
+		RecipientPubKeyHash: ops[9].data,
+		RefundPubKeyHash:    ops[16].data,
+		LockTime:            uint32(lockTime),
+	}
+	if len(c.SecretHash) != sha256.Size || len(c.RecipientPubKeyHash) != pubKeyHashLength || len(c.RefundPubKeyHash) != pubKeyHashLength {
+		return nil, ErrNotHTLC
+	}
+
+	return c, nil
+}
+
+// 비밀 값으로 HTLC 출력을 받는 잠금 해제 스크립트 생성
+// <서명> <공개 키> <비밀 값> OP_1 <redeem script>
+func HTLCRedeemUnlockScript(sig, pubKey, secret, contract []byte) []byte {
+	return NewScriptBuilder().AddData(sig).AddData(pubKey).AddData(secret).AddOp(Op1).AddData(contract).Script()
+}
+
+// LockTime 이후 HTLC 출력을 돌려받는 잠금 해제 스크립트 생성
+// <서명> <공개 키> OP_0 <redeem script>
+func HTLCRefundUnlockScript(sig, pubKey, contract []byte) []byte {
+	return NewScriptBuilder().AddData(sig).AddData(pubKey).AddOp(Op0).AddData(contract).Script()
+}
+
+// HTLC 출력을 받은 잠금 해제 스크립트에서 비밀 값을 추출하는 함수(다른 형식은 nil)
+func ExtractHTLCSecret(scriptSig, contract []byte) []byte {
+	ops, err := parseScript(scriptSig)
+	if err != nil || len(ops) != 5 || !isPushOnly(ops) {
+		return nil
+	}
+	if ops[3].opcode != Op1 || !bytes.Equal(ops[4].data, contract) {
+		return nil
+	}
+
+	return ops[2].data
+}
+
+// HTLC 출력을 모두 사용하여 to 주소로 보내는 서명된 트랜잭션을 생성하는 함수
+// secret이 있으면 수신자가 비밀 값으로 받고, nil이면 환불 주소의 소유자가 LockTime 이후 돌려받음
+// privKey는 수신자 또는 환불 주소의 개인 키
+func NewHTLCSpend(contract []byte, utxos []UnspentOutput, to string, privKey *ecdsa.PrivateKey, secret []byte, rate FeeRate) (*Transaction, error) {
+	c, err := ExtractHTLC(contract)
+	if err != nil {
+		return nil, err
+	}
+	if len(utxos) == 0 {
+		return nil, errors.New("contract has no unspent outputs")
+	}
+
+	pubKey := serializePubKey(privKey)
+	tx := &Transaction{Version: TxVersion}
+
+	// 비밀 값으로 받으면 수신자, 환불이면 환불 주소의 키여야 함
+	if secret != nil {
+		if hash := sha256.Sum256(secret); !bytes.Equal(hash[:], c.SecretHash) {
+			return nil, ErrWrongSecret
+		}
+		if !bytes.Equal(wallet.PublicKeyHash(pubKey), c.RecipientPubKeyHash) {
+			return nil, errors.New("key is not the contract's recipient")
+		}
+	} else {
+		if !bytes.Equal(wallet.PublicKeyHash(pubKey), c.RefundPubKeyHash) {
+			return nil, errors.New("key is not the contract's refund key")
+		}
+		// OP_CHECKLOCKTIMEVERIFY는 트랜잭션의 LockTime과 최종이 아닌 시퀀스 번호를 요구
+		tx.LockTime = c.LockTime
+	}
+
+	// 계약의 스크립트 해시로 잠긴 출력만 사용
+	scriptHash := wallet.PublicKeyHash(contract)
+	total := 0
+	var prevOutputs []TxOutput
+	for _, utxo := range utxos {
+		if !bytes.Equal(utxo.Output.ScriptHash(), scriptHash) {
+			return nil, fmt.Errorf("output %s is not locked by the contract", utxo.Outpoint())
+		}
+		tx.Inputs = append(tx.Inputs, TxInput{utxo.TxID, utxo.Vout, nil, MaxTxInSequenceNum - 1})
+		prevOutputs = append(prevOutputs, utxo.Output)
+		total += utxo.Output.Value
+	}
+
+	// 잠금 해제 스크립트에 redeem script와 비밀 값이 들어가므로 그만큼 수수료 추가
+	fee := rate.Fee(len(utxos), 1) + (len(utxos)*(len(contract)+HTLCSecretSize)*int(rate)+999)/1000
+	if total <= fee {
+		return nil, fmt.Errorf("contract value %d does not cover the fee %d", total, fee)
+	}
+	tx.Outputs = append(tx.Outputs, *NewTXOutput(total-fee, to))
+	tx.ID = tx.Hash()
+
+	// 입력마다 서명하여 잠금 해제 스크립트 생성
+	txCopy := tx.TrimmedCopy()
+	for inId := range tx.Inputs {
+		sig := signHash(privKey, txCopy.signatureHash(inId, prevOutputs[inId]))
+		if secret != nil {
+			tx.Inputs[inId].ScriptSig = HTLCRedeemUnlockScript(sig, pubKey, secret, contract)
+		} else {
+			tx.Inputs[inId].ScriptSig = HTLCRefundUnlockScript(sig, pubKey, contract)
+		}
+	}
+
+	if err := tx.verifyScripts(prevOutputs); err != nil {
+		return nil, fmt.Errorf("transaction does not verify: %w", err)
+	}
+
+	return tx, nil
+}
+
+// 체인에서 HTLC 출력을 비밀 값으로 받은 입력을 찾아 비밀 값을 추출하는 함수
+func (chain *BlockChain) FindHTLCSecret(contract []byte) ([]byte, error) {
+	iter := chain.Iterator()
+	for {
+		block := iter.Next()
+
+		for _, tx := range block.Transactions {
+			if tx.IsCoinbase() {
+				continue
+			}
+			for _, in := range tx.Inputs {
+				if secret := ExtractHTLCSecret(in.ScriptSig, contract); secret != nil {
+					return secret, nil
+				}
+			}
+		}
+
+		if len(block.PrevHash) == 0 {
+			break
+		}
+	}
+
+	return nil, errors.New("contract has not been redeemed")
+}
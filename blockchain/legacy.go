@@ -0,0 +1,222 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// 스키마 버전 2 이전에 JSON으로 저장된 값의 형식(첫 바이트가 JSON 객체의 시작)
+const jsonEncodingVersion = '{'
+
+// 이전 형식으로 만들어진 블록과 트랜잭션은 마이그레이션 후에도 원래 형식으로 저장하고 직렬화함
+// 블록 해시(작업 증명)는 트랜잭션 직렬화의 Merkle 루트로, 서명은 트랜잭션 복사본의 해시로 계산되므로
+// 현재 형식으로 다시 기록하면 블록 해시와 서명을 검증할 수 없게 됨
+
+// 스크립트 도입 전의 JSON 형식 트랜잭션 출력
+type legacyJSONTxOutput struct {
+	Value      int
+	PubKeyHash []byte
+}
+
+// 스크립트 도입 전의 JSON 형식 트랜잭션 입력
+type legacyJSONTxInput struct {
+	ID        []byte
+	Out       int
+	Signature []byte
+	PubKey    []byte
+}
+
+// 스크립트 도입 전의 JSON 형식 트랜잭션
+type legacyJSONTransaction struct {
+	ID      []byte
+	Inputs  []legacyJSONTxInput
+	Outputs []legacyJSONTxOutput
+}
+
+// 스크립트 도입 전의 JSON 형식 블록
+type legacyJSONBlock struct {
+	Timestamp    int64
+	Hash         []byte
+	Transactions []*legacyJSONTransaction
+	PrevHash     []byte
+	Nonce        int
+	Height       int
+}
+
+// JSON 형식의 출력 목록을 현재 형식으로 변환
+func convertLegacyOutputs(outs []legacyJSONTxOutput) []TxOutput {
+	var outputs []TxOutput
+	for _, out := range outs {
+		outputs = append(outputs, TxOutput{out.Value, PayToPubKeyHashScript(out.PubKeyHash)})
+	}
+
+	return outputs
+}
+
+// JSON 형식의 트랜잭션을 현재 구조체로 변환(해시 계산을 위해 JSON 형식임을 기록)
+func (legacy *legacyJSONTransaction) convert() *Transaction {
+	tx := &Transaction{ID: legacy.ID, Version: 1, Outputs: convertLegacyOutputs(legacy.Outputs), encoding: jsonEncodingVersion}
+	for _, in := range legacy.Inputs {
+		input := TxInput{ID: in.ID, Out: in.Out, Sequence: MaxTxInSequenceNum}
+		input.ScriptSig = legacyScriptSig(&input, in.Signature, in.PubKey)
+		tx.Inputs = append(tx.Inputs, input)
+	}
+
+	return tx
+}
+
+// JSON 형식의 블록을 현재 구조체로 변환
+func (legacy *legacyJSONBlock) convert() *Block {
+	block := &Block{legacy.Timestamp, legacy.Hash, nil, legacy.PrevHash, legacy.Nonce, legacy.Height}
+
+	for _, legacyTx := range legacy.Transactions {
+		block.Transactions = append(block.Transactions, legacyTx.convert())
+	}
+
+	return block
+}
+
+// 트랜잭션을 JSON 형식의 구조체로 변환
+func (tx *Transaction) legacyJSON() *legacyJSONTransaction {
+	legacy := &legacyJSONTransaction{ID: tx.ID}
+	for i := range tx.Inputs {
+		in := &tx.Inputs[i]
+		signature, pubKey := splitLegacyScriptSig(in)
+		legacy.Inputs = append(legacy.Inputs, legacyJSONTxInput{in.ID, in.Out, signature, pubKey})
+	}
+	for _, out := range tx.Outputs {
+		legacy.Outputs = append(legacy.Outputs, legacyJSONTxOutput{out.Value, ExtractPubKeyHash(out.ScriptPubKey)})
+	}
+
+	return legacy
+}
+
+// 잠금 해제 스크립트를 서명과 공개 키로 나누는 함수(legacyScriptSig의 역변환)
+// 서명 대상 해시를 계산할 때 넣는 P2PKH 잠금 스크립트는 이전 형식처럼 공개 키 자리의 공개 키 해시로 변환
+func splitLegacyScriptSig(in *TxInput) (signature, pubKey []byte) {
+	if len(in.ID) == 0 && in.Out == -1 {
+		return nil, in.ScriptSig
+	}
+	if len(in.ScriptSig) == 0 {
+		return nil, nil
+	}
+	if pubKeyHash := ExtractPubKeyHash(in.ScriptSig); pubKeyHash != nil {
+		return nil, pubKeyHash
+	}
+
+	ops, err := parseScript(in.ScriptSig)
+	if err != nil || len(ops) != 2 || !isPushOnly(ops) {
+		return nil, nil
+	}
+
+	return ops[0].data, ops[1].data
+}
+
+// 이전 형식(JSON, 버전 1, 2)의 트랜잭션 인코딩
+// 버전 1은 서명과 공개 키, 공개 키 해시를 따로 저장하고, 버전 2까지는 트랜잭션 버전과 LockTime, 시퀀스 번호가 없음
+func (tx *Transaction) encodeLegacy(e *encoder) {
+	e.writeBytes(tx.ID)
+
+	e.writeUvarint(uint64(len(tx.Inputs)))
+	for i := range tx.Inputs {
+		in := &tx.Inputs[i]
+		e.writeBytes(in.ID)
+		e.writeVarint(int64(in.Out))
+		if tx.encoding == legacyEncodingVersion {
+			signature, pubKey := splitLegacyScriptSig(in)
+			e.writeBytes(signature)
+			e.writeBytes(pubKey)
+		} else {
+			e.writeBytes(in.ScriptSig)
+		}
+	}
+
+	e.writeUvarint(uint64(len(tx.Outputs)))
+	for i := range tx.Outputs {
+		out := &tx.Outputs[i]
+		e.writeVarint(int64(out.Value))
+		if tx.encoding == legacyEncodingVersion {
+			e.writeBytes(ExtractPubKeyHash(out.ScriptPubKey))
+		} else {
+			e.writeBytes(out.ScriptPubKey)
+		}
+	}
+}
+
+// 이전 형식으로 만들어진 트랜잭션을 원래 형식으로 직렬화하는 함수
+func (tx *Transaction) serializeLegacy() []byte {
+	if tx.encoding == jsonEncodingVersion {
+		data, err := json.Marshal(tx.legacyJSON())
+		if err != nil {
+			log.Panic(err)
+		}
+		return data
+	}
+
+	e := &encoder{}
+	e.writeByte(tx.encoding)
+	tx.encodeLegacy(e)
+
+	return e.Bytes()
+}
+
+// JSON으로 직렬화된 트랜잭션을 복원하는 함수
+func deserializeJSONTransaction(data []byte) Transaction {
+	var legacy legacyJSONTransaction
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		log.Panic(err)
+	}
+
+	return *legacy.convert()
+}
+
+// 블록을 만든 형식 버전(현재 형식이면 0)
+// 한 블록의 트랜잭션은 모두 같은 형식으로 만들어짐
+func (b *Block) encoding() byte {
+	if len(b.Transactions) == 0 {
+		return 0
+	}
+
+	return b.Transactions[0].encoding
+}
+
+// 이전 형식으로 만들어진 블록을 원래 형식으로 직렬화하는 함수
+func (b *Block) serializeLegacy() []byte {
+	if b.encoding() == jsonEncodingVersion {
+		legacy := legacyJSONBlock{b.Timestamp, b.Hash, nil, b.PrevHash, b.Nonce, b.Height}
+		for _, tx := range b.Transactions {
+			legacy.Transactions = append(legacy.Transactions, tx.legacyJSON())
+		}
+
+		data, err := json.Marshal(legacy)
+		if err != nil {
+			log.Panic(err)
+		}
+		return data
+	}
+
+	e := &encoder{}
+	e.writeByte(b.encoding())
+	e.writeVarint(b.Timestamp)
+	e.writeBytes(b.Hash)
+	e.writeBytes(b.PrevHash)
+	e.writeVarint(int64(b.Nonce))
+	e.writeVarint(int64(b.Height))
+
+	e.writeUvarint(uint64(len(b.Transactions)))
+	for _, tx := range b.Transactions {
+		tx.encodeLegacy(e)
+	}
+
+	return e.Bytes()
+}
+
+// JSON으로 저장된 블록을 복원하는 함수
+func deserializeJSONBlock(data []byte) *Block {
+	var legacy legacyJSONBlock
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		log.Panic(err)
+	}
+
+	return legacy.convert()
+}
@@ -0,0 +1,153 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// 새로 생성하는 트랜잭션의 버전(버전 2부터 입력의 시퀀스 번호로 상대 잠금 적용)
+	TxVersion = 2
+
+	// 잠금을 사용하지 않는 입력의 시퀀스 번호
+	// 모든 입력이 이 값이면 트랜잭션의 LockTime도 무시됨
+	MaxTxInSequenceNum uint32 = 0xffffffff
+
+	// 이 값보다 작은 LockTime은 블록 높이, 크거나 같으면 유닉스 시간
+	LockTimeThreshold = 500000000
+
+	// 시퀀스 번호에 이 비트가 설정되면 상대 잠금을 사용하지 않음
+	SequenceLockTimeDisabled uint32 = 1 << 31
+	// 시퀀스 번호에 이 비트가 설정되면 상대 잠금이 블록 수가 아닌 시간(512초 단위)
+	SequenceLockTimeIsSeconds uint32 = 1 << 22
+	// 시퀀스 번호에서 상대 잠금 값을 나타내는 비트
+	SequenceLockTimeMask uint32 = 0x0000ffff
+	// 시간 기반 상대 잠금 단위(2^9 = 512초)
+	SequenceLockTimeGranularity = 9
+)
+
+var (
+	// LockTime에 도달하지 않은 트랜잭션의 에러
+	ErrTxNotFinal = errors.New("transaction is not final")
+	// 입력의 상대 잠금이 풀리지 않은 트랜잭션의 에러
+	ErrSequenceLocked = errors.New("transaction input is still locked by its sequence number")
+)
+
+// 트랜잭션을 주어진 높이의 블록에 포함할 수 있는지 LockTime을 확인하는 함수
+// 시간 LockTime은 블록 시간이 아닌 이전 블록들의 중간 시간 medianTime과 비교
+func (tx *Transaction) IsFinal(height int, medianTime int64) bool {
+	if tx.LockTime == 0 {
+		return true
+	}
+
+	// LockTime 값의 크기로 블록 높이와 유닉스 시간을 구분
+	limit := int64(height)
+	if tx.LockTime >= LockTimeThreshold {
+		limit = medianTime
+	}
+	if int64(tx.LockTime) < limit {
+		return true
+	}
+
+	// 모든 입력의 시퀀스 번호가 최종이면 LockTime을 무시
+	for _, in := range tx.Inputs {
+		if in.Sequence != MaxTxInSequenceNum {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 입력의 상대 잠금이 적용되는지 확인하는 함수
+func (tx *Transaction) hasSequenceLock(inId int) bool {
+	return tx.Version >= 2 && tx.Inputs[inId].Sequence&SequenceLockTimeDisabled == 0
+}
+
+// 트랜잭션의 LockTime과 입력의 상대 잠금이 뷰의 블록 높이와 중간 시간에서 모두 풀렸는지 확인하는 함수
+// 시간 잠금은 채굴자가 정하는 블록 시간 대신 이전 블록들의 중간 시간(median-time-past)을 기준으로 하며,
+// 상대 잠금은 사용하는 출력이 포함된 블록의 높이와 그 이전 블록까지의 중간 시간을 기준으로 함
+// 뷰에 적용한 트랜잭션(메모리 풀이나 같은 블록의 부모)의 출력은 이번 블록에 포함되는 것으로 봄
+// 사용하는 출력이 뷰에 남아 있도록 Connect 전에 호출해야 함
+func (v *UTXOView) CheckLocks(tx *Transaction) error {
+	if !tx.IsFinal(v.height, v.medianTime) {
+		return fmt.Errorf("%w: lock time %d, block height %d, median time %d", ErrTxNotFinal, tx.LockTime, v.height, v.medianTime)
+	}
+	if tx.IsCoinbase() {
+		return nil
+	}
+
+	for inId, in := range tx.Inputs {
+		if !tx.hasSequenceLock(inId) {
+			continue
+		}
+
+		prev, ok := v.fetch(UnspentOutput{TxID: in.ID, Vout: in.Out})
+		if !ok {
+			return fmt.Errorf("%w: input %d spends %x:%d", ErrMissingOutput, inId, in.ID, in.Out)
+		}
+
+		value := int64(in.Sequence & SequenceLockTimeMask)
+		if in.Sequence&SequenceLockTimeIsSeconds != 0 {
+			// 출력이 포함된 블록 이전까지의 중간 시간으로부터 지정한 시간이 지나야 함
+			prevTime := v.medianTime
+			if prev.Height == 0 {
+				prevTime = v.set.Blockchain.MedianTimePast(0)
+			} else if prev.Height < v.height {
+				prevTime = v.set.Blockchain.MedianTimePast(prev.Height - 1)
+			}
+			if v.medianTime-prevTime < value<<SequenceLockTimeGranularity {
+				return fmt.Errorf("%w: input %d needs %d seconds after block %d", ErrSequenceLocked, inId, value<<SequenceLockTimeGranularity, prev.Height)
+			}
+		} else if int64(v.height-prev.Height) < value {
+			// 출력이 포함된 블록으로부터 지정한 블록 수가 지나야 함
+			return fmt.Errorf("%w: input %d needs %d blocks after block %d", ErrSequenceLocked, inId, value, prev.Height)
+		}
+	}
+
+	return nil
+}
+
+// OP_CHECKLOCKTIMEVERIFY: 트랜잭션의 LockTime이 스크립트의 잠금 값에 도달했는지 확인하는 함수
+func (tx *Transaction) checkLockTime(inId int, lockTime int64) error {
+	// 잠금 값과 트랜잭션의 LockTime은 같은 종류(높이 또는 시간)여야 함
+	if (lockTime < LockTimeThreshold) != (tx.LockTime < LockTimeThreshold) {
+		return errors.New("lock time type mismatch")
+	}
+	if lockTime > int64(tx.LockTime) {
+		return fmt.Errorf("lock time %d has not been reached by transaction lock time %d", lockTime, tx.LockTime)
+	}
+
+	// 최종 시퀀스 번호인 입력은 LockTime을 무시하게 만들 수 있으므로 거부
+	if tx.Inputs[inId].Sequence == MaxTxInSequenceNum {
+		return errors.New("input sequence is final")
+	}
+
+	return nil
+}
+
+// OP_CHECKSEQUENCEVERIFY: 입력의 시퀀스 번호가 스크립트의 상대 잠금 값 이상인지 확인하는 함수
+func (tx *Transaction) checkSequence(inId int, sequence int64) error {
+	// 비활성화 비트가 설정된 잠금 값은 아무것도 확인하지 않음
+	if uint32(sequence)&SequenceLockTimeDisabled != 0 {
+		return nil
+	}
+
+	if !tx.hasSequenceLock(inId) {
+		return errors.New("input has no relative lock")
+	}
+
+	txSequence := tx.Inputs[inId].Sequence
+	mask := SequenceLockTimeIsSeconds | SequenceLockTimeMask
+	want, have := uint32(sequence)&mask, txSequence&mask
+
+	// 잠금 값과 입력의 시퀀스 번호는 같은 종류(블록 수 또는 시간)여야 함
+	if want&SequenceLockTimeIsSeconds != have&SequenceLockTimeIsSeconds {
+		return errors.New("sequence type mismatch")
+	}
+	if want&SequenceLockTimeMask > have&SequenceLockTimeMask {
+		return fmt.Errorf("relative lock %d has not been reached by input sequence %d", want&SequenceLockTimeMask, have&SequenceLockTimeMask)
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 주어진 시퀀스 번호와 LockTime으로 출력을 사용하는 트랜잭션을 만들고 서명하는 함수
+func lockedSpendTx(t *testing.T, w *wallet.Wallet, prev *Transaction, sequence, lockTime uint32) *Transaction {
+	t.Helper()
+
+	tx := &Transaction{
+		Version:  TxVersion,
+		Inputs:   []TxInput{{prev.ID, 0, nil, sequence}},
+		Outputs:  []TxOutput{*NewTXOutput(prev.Outputs[0].Value, string(w.Address()))},
+		LockTime: lockTime,
+	}
+	tx.ID = tx.Hash()
+	tx.Sign(w.DeserializePrivateKey(w.PrivateKey), map[string]Transaction{hex.EncodeToString(prev.ID): *prev})
+
+	return tx
+}
+
+// 주어진 시간의 코인베이스 블록을 다른 노드가 채굴한 블록처럼 검증하여 연결하는 함수
+func connectBlockAt(t *testing.T, chain *BlockChain, miner *wallet.Wallet, timestamp int64) {
+	t.Helper()
+
+	block := CreateBlock([]*Transaction{CoinbaseTx(string(miner.Address()), "")}, chain.LastHash, chain.GetBestHeight()+1, timestamp)
+	if err := chain.ConnectBlock(block); err != nil {
+		t.Fatalf("ConnectBlock: %v", err)
+	}
+}
+
+func TestIsFinal(t *testing.T) {
+	const height, medianTime = 100, LockTimeThreshold + 1000
+
+	tests := []struct {
+		name     string
+		lockTime uint32
+		sequence uint32
+		final    bool
+	}{
+		{"no lock time", 0, 0, true},
+		{"height reached", height - 1, 0, true},
+		{"height not reached", height, 0, false},
+		{"time reached", medianTime - 1, 0, true},
+		{"time not reached", medianTime, 0, false},
+		{"final sequence ignores lock time", height, MaxTxInSequenceNum, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx := &Transaction{Inputs: []TxInput{{[]byte{1}, 0, nil, test.sequence}}, LockTime: test.lockTime}
+			if got := tx.IsFinal(height, medianTime); got != test.final {
+				t.Fatalf("IsFinal = %v, want %v", got, test.final)
+			}
+		})
+	}
+}
+
+func TestBlockTimeRule(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+
+	mtp := chain.MedianTimePast(chain.GetBestHeight())
+	tests := []struct {
+		name      string
+		timestamp int64
+		err       error
+	}{
+		{"at the median time", mtp, ErrTimeTooOld},
+		{"before the median time", mtp - 1, ErrTimeTooOld},
+		{"too far in the future", time.Now().Unix() + MaxFutureBlockTime + 60, ErrTimeTooNew},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			block := CreateBlock([]*Transaction{CoinbaseTx(string(miner.Address()), "")}, chain.LastHash, chain.GetBestHeight()+1, test.timestamp)
+			if err := chain.ValidateBlock(block); !errors.Is(err, test.err) {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	// 중간 시간 바로 다음 시간과 허용된 범위의 미래 시간은 받아들임
+	connectBlockAt(t, chain, miner, mtp+1)
+	connectBlockAt(t, chain, miner, time.Now().Unix()+MaxFutureBlockTime-60)
+}
+
+func TestTimeLockUsesMedianTimePast(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	funding := coinbaseAt(chain, 1)
+
+	// 마지막 블록의 시간을 미래로 정해도 중간 시간은 거의 바뀌지 않으므로 그 사이의 LockTime은 풀리지 않음
+	connectBlockAt(t, chain, miner, time.Now().Unix()+MaxFutureBlockTime-60)
+
+	mtp := chain.MedianTimePast(chain.GetBestHeight())
+	early := lockedSpendTx(t, miner, funding, 0, uint32(mtp))
+	if err := (UTXOSet{chain}).CheckTransactions([]*Transaction{CoinbaseTx(string(miner.Address()), ""), early}); !errors.Is(err, ErrTxNotFinal) {
+		t.Fatalf("lock time at the median time: got %v, want %v", err, ErrTxNotFinal)
+	}
+
+	ready := lockedSpendTx(t, miner, funding, 0, uint32(mtp-1))
+	if err := (UTXOSet{chain}).CheckTransactions([]*Transaction{CoinbaseTx(string(miner.Address()), ""), ready}); err != nil {
+		t.Fatalf("lock time before the median time: %v", err)
+	}
+}
+
+func TestCheckLockTimeVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		lock     int64
+		lockTime uint32
+		sequence uint32
+		ok       bool
+	}{
+		{"height reached", 100, 100, 0, true},
+		{"height not reached", 101, 100, 0, false},
+		{"time reached", LockTimeThreshold + 10, LockTimeThreshold + 10, 0, true},
+		{"time not reached", LockTimeThreshold + 11, LockTimeThreshold + 10, 0, false},
+		{"height lock with time lock time", 100, LockTimeThreshold, 0, false},
+		{"time lock with height lock time", LockTimeThreshold, 100, 0, false},
+		{"final sequence", 100, 100, MaxTxInSequenceNum, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx := &Transaction{Version: TxVersion, Inputs: []TxInput{{[]byte{1}, 0, nil, test.sequence}}, LockTime: test.lockTime}
+			if err := tx.checkLockTime(0, test.lock); (err == nil) != test.ok {
+				t.Fatalf("checkLockTime: %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
+
+func TestCheckSequenceVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		lock     int64
+		version  int
+		sequence uint32
+		ok       bool
+	}{
+		{"blocks reached", 10, TxVersion, 10, true},
+		{"blocks not reached", 11, TxVersion, 10, false},
+		{"time reached", int64(SequenceLockTimeIsSeconds | 2), TxVersion, SequenceLockTimeIsSeconds | 2, true},
+		{"time not reached", int64(SequenceLockTimeIsSeconds | 3), TxVersion, SequenceLockTimeIsSeconds | 2, false},
+		{"blocks lock with time sequence", 1, TxVersion, SequenceLockTimeIsSeconds | 2, false},
+		{"disabled lock", int64(SequenceLockTimeDisabled), TxVersion, 0, true},
+		{"disabled input sequence", 1, TxVersion, SequenceLockTimeDisabled | 10, false},
+		{"version 1 transaction", 1, 1, 10, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx := &Transaction{Version: test.version, Inputs: []TxInput{{[]byte{1}, 0, nil, test.sequence}}}
+			if err := tx.checkSequence(0, test.lock); (err == nil) != test.ok {
+				t.Fatalf("checkSequence: %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
+
+func TestRelativeSequenceLocks(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	funding := coinbaseAt(chain, 1)
+	cb := func() *Transaction { return CoinbaseTx(string(miner.Address()), "") }
+
+	// 높이 1의 출력은 다음 블록에서 마지막 블록 높이만큼의 블록이 지난 것이 됨
+	depth := uint32(chain.GetBestHeight())
+	if err := (UTXOSet{chain}).CheckTransactions([]*Transaction{cb(), lockedSpendTx(t, miner, funding, depth, 0)}); err != nil {
+		t.Fatalf("block lock reached: %v", err)
+	}
+	if err := (UTXOSet{chain}).CheckTransactions([]*Transaction{cb(), lockedSpendTx(t, miner, funding, depth+1, 0)}); !errors.Is(err, ErrSequenceLocked) {
+		t.Fatalf("block lock not reached: got %v, want %v", err, ErrSequenceLocked)
+	}
+
+	// 시간 잠금은 출력이 포함되기 전까지의 중간 시간으로부터 512초 단위로 계산하므로 방금 포함된 출력은 잠겨 있음
+	prev := spendTx(t, miner, funding, 0, *NewTXOutput(ActiveParams.Subsidy, string(miner.Address())))
+	if err := chain.ConnectBlock(CreateBlock([]*Transaction{cb(), prev}, chain.LastHash, chain.GetBestHeight()+1, chain.NextBlockTime())); err != nil {
+		t.Fatalf("ConnectBlock: %v", err)
+	}
+
+	timeLock := SequenceLockTimeIsSeconds | 1
+	if err := (UTXOSet{chain}).CheckTransactions([]*Transaction{cb(), lockedSpendTx(t, miner, prev, timeLock, 0)}); !errors.Is(err, ErrSequenceLocked) {
+		t.Fatalf("time lock not reached: got %v, want %v", err, ErrSequenceLocked)
+	}
+
+	// 중간 시간이 512초 이상 지나도록 미래 시간의 블록을 연결
+	future := time.Now().Unix() + 1<<SequenceLockTimeGranularity + 60
+	for i := 0; i < medianTimeBlocks; i++ {
+		connectBlockAt(t, chain, miner, future+int64(i))
+	}
+	if err := (UTXOSet{chain}).CheckTransactions([]*Transaction{cb(), lockedSpendTx(t, miner, prev, timeLock, 0)}); err != nil {
+		t.Fatalf("time lock reached: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 성숙 깊이에 도달하지 않은 코인베이스 출력을 사용하는 트랜잭션의 에러
+var ErrImmatureCoinbase = errors.New("transaction spends an immature coinbase output")
+
+// 트랜잭션이 뷰의 블록 높이에서 성숙 깊이에 도달하지 않은 코인베이스 출력을 사용하는지 확인하는 함수
+// 팁이 다른 블록으로 바뀌면 코인베이스 출력이 사라질 수 있으므로 CoinbaseMaturity 블록이 지나야 사용 가능
+// 출력의 블록 높이와 코인베이스 여부는 UTXO 항목에서 읽음
+func (v *UTXOView) CheckCoinbaseMaturity(tx *Transaction) error {
+	if tx.IsCoinbase() {
+		return nil
+	}
+
+	for inId, in := range tx.Inputs {
+		prev, ok := v.fetch(UnspentOutput{TxID: in.ID, Vout: in.Out})
+		if !ok {
+			return fmt.Errorf("%w: input %d spends %x:%d", ErrMissingOutput, inId, in.ID, in.Out)
+		}
+
+		if !prev.IsMature(v.height) {
+			return fmt.Errorf("%w: input %d spends coinbase of block %d, spendable at block %d", ErrImmatureCoinbase, inId, prev.Height, prev.Height+ActiveParams.CoinbaseMaturity)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package blockchain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+func TestCoinbaseMaturity(t *testing.T) {
+	miner, alice := wallet.MakeWallet(), wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	next := chain.GetBestHeight() + 1
+
+	// 다음 블록에서 정확히 성숙 깊이에 도달하는 코인베이스와 한 블록 모자라는 코인베이스
+	mature := coinbaseAt(chain, next-ActiveParams.CoinbaseMaturity)
+	immature := coinbaseAt(chain, next-ActiveParams.CoinbaseMaturity+1)
+
+	tests := []struct {
+		name string
+		prev *Transaction
+		err  error
+	}{
+		{"spent exactly at maturity", mature, nil},
+		{"spent one block before maturity", immature, ErrImmatureCoinbase},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spend := spendTx(t, miner, test.prev, 0, *NewTXOutput(ActiveParams.Subsidy, string(alice.Address())))
+
+			// 블록 검증과 메모리 풀, 블록 템플릿이 같은 규칙을 사용
+			txs := []*Transaction{CoinbaseTx(string(miner.Address()), ""), spend}
+			if err := (UTXOSet{chain}).CheckTransactions(txs); !errors.Is(err, test.err) {
+				t.Fatalf("CheckTransactions: got %v, want %v", err, test.err)
+			}
+			if err := NewUTXOView(UTXOSet{chain}).CheckInputs(spend); !errors.Is(err, test.err) {
+				t.Fatalf("CheckInputs: got %v, want %v", err, test.err)
+			}
+			if template := chain.NewBlockTemplate([]*Transaction{spend}); (len(template.Transactions) == 1) != (test.err == nil) {
+				t.Fatalf("template has %d transactions", len(template.Transactions))
+			}
+		})
+	}
+
+	// 한 블록이 더 지나면 성숙하지 않았던 코인베이스도 사용할 수 있음
+	if _, err := chain.Generate(1, string(miner.Address())); err != nil {
+		t.Fatal(err)
+	}
+	spend := spendTx(t, miner, immature, 0, *NewTXOutput(ActiveParams.Subsidy, string(alice.Address())))
+	if err := (UTXOSet{chain}).CheckTransactions([]*Transaction{CoinbaseTx(string(miner.Address()), ""), spend}); err != nil {
+		t.Fatalf("CheckTransactions after one more block: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// 중간 시간(median-time-past)을 계산할 때 사용하는 이전 블록 수
+	medianTimeBlocks = 11
+	// 블록 시간이 현재 시간보다 앞설 수 있는 최대 시간(초)
+	MaxFutureBlockTime = 2 * 60 * 60
+)
+
+var (
+	// 이전 블록들의 중간 시간보다 시간이 이르거나 같은 블록의 에러
+	ErrTimeTooOld = errors.New("block timestamp is not after the median time of the previous blocks")
+	// 현재 시간보다 너무 앞선 시간의 블록의 에러
+	ErrTimeTooNew = errors.New("block timestamp is too far in the future")
+)
+
+// 주어진 높이의 블록과 그 이전 블록들(최대 11개) 시간의 중간값을 구하는 함수
+// 채굴자가 블록 시간을 임의로 정해도 이 값은 크게 바꿀 수 없으므로 시간 잠금과 블록 시간 규칙의 기준으로 사용
+func (chain *BlockChain) MedianTimePast(height int) int64 {
+	var timestamps []int64
+
+	iter := chain.Iterator()
+	for {
+		block := iter.Next()
+		if block.Height <= height {
+			timestamps = append(timestamps, block.Timestamp)
+		}
+		if len(timestamps) == medianTimeBlocks || len(block.PrevHash) == 0 {
+			break
+		}
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	return timestamps[len(timestamps)/2]
+}
+
+// 블록 시간이 마지막 블록까지의 중간 시간보다 크고 현재 시간보다 너무 앞서지 않는지 확인하는 함수
+func (chain *BlockChain) checkBlockTime(block *Block) error {
+	if mtp := chain.MedianTimePast(block.Height - 1); block.Timestamp <= mtp {
+		return fmt.Errorf("%w: block %x has time %d, median time is %d", ErrTimeTooOld, block.Hash, block.Timestamp, mtp)
+	}
+	if limit := time.Now().Unix() + MaxFutureBlockTime; block.Timestamp > limit {
+		return fmt.Errorf("%w: block %x has time %d, limit is %d", ErrTimeTooNew, block.Hash, block.Timestamp, limit)
+	}
+
+	return nil
+}
+
+// 다음 블록의 시간을 정하는 함수
+// 현재 시간을 사용하되 블록 시간 규칙을 지키도록 마지막 블록까지의 중간 시간보다 크게 함
+func (chain *BlockChain) NextBlockTime() int64 {
+	now := time.Now().Unix()
+	if mtp := chain.MedianTimePast(chain.GetBestHeight()); now <= mtp {
+		return mtp + 1
+	}
+
+	return now
+}
@@ -46,6 +46,8 @@ func NewMerkleNode(left, right *MerkleNode, data []byte) *MerkleNode {
 }
 
 // 주어진 데이터 조각들로부터 Merkle 트리를 생성
+// 홀수 개수의 레벨은 마지막 노드를 한 번 더 사용하므로 데이터가 5개 이상이어도 트리를 만들 수 있으며,
+// 데이터가 1~4개인 트리의 루트는 레벨 수를 데이터 개수의 절반으로 반복하던 이전 방식과 같음
 func NewMerkleTree(data [][]byte) *MerkleTree {
 	// 초기 노드 리스트
 	var nodes []MerkleNode
@@ -64,8 +66,13 @@ func NewMerkleTree(data [][]byte) *MerkleTree {
 		nodes = append(nodes, *node)
 	}
 
-	// 반복 횟수를 데이터 길이의 절반으로 제한
-	for i := 0; i < len(data)/2; i++ {
+	// 노드가 하나 남을 때까지 두 개씩 묶어 위 레벨을 생성
+	for len(nodes) > 1 {
+		// 노드 개수가 홀수인 레벨은 마지막 노드를 한 번 더 사용
+		if len(nodes)%2 != 0 {
+			nodes = append(nodes, nodes[len(nodes)-1])
+		}
+
 		// 현재 레벨의 노드 리스트
 		var level []MerkleNode
 
@@ -86,3 +93,48 @@ func NewMerkleTree(data [][]byte) *MerkleTree {
 
 	return &tree
 }
+
+// index 번째 데이터에서 루트까지 올라가며 필요한 형제 노드 해시 목록을 반환하는 함수
+// NewMerkleTree와 같은 방식으로 홀수 개수의 레벨은 마지막 노드를 한 번 더 사용
+func MerkleBranch(data [][]byte, index int) [][]byte {
+	var level [][]byte
+	for _, dat := range data {
+		hash := sha256.Sum256(dat)
+		level = append(level, hash[:])
+	}
+
+	var branch [][]byte
+	for len(level) > 1 || len(branch) == 0 {
+		if len(level)%2 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+
+		// 현재 노드의 형제 노드 해시 추가
+		branch = append(branch, level[index^1])
+
+		var next [][]byte
+		for j := 0; j < len(level); j += 2 {
+			next = append(next, NewMerkleNode(&MerkleNode{Data: level[j]}, &MerkleNode{Data: level[j+1]}, nil).Data)
+		}
+		level = next
+		index /= 2
+	}
+
+	return branch
+}
+
+// 데이터와 형제 노드 해시 목록으로 Merkle 루트를 계산하는 함수
+func MerkleRootFromBranch(data []byte, index int, branch [][]byte) []byte {
+	node := NewMerkleNode(nil, nil, data)
+	for _, sibling := range branch {
+		// 인덱스가 짝수이면 왼쪽, 홀수이면 오른쪽 자식
+		if index%2 == 0 {
+			node = NewMerkleNode(node, &MerkleNode{Data: sibling}, nil)
+		} else {
+			node = NewMerkleNode(&MerkleNode{Data: sibling}, node, nil)
+		}
+		index /= 2
+	}
+
+	return node.Data
+}
@@ -0,0 +1,104 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+// 테스트용 데이터 n개
+func merkleTestData(n int) [][]byte {
+	var data [][]byte
+	for i := 0; i < n; i++ {
+		data = append(data, []byte(fmt.Sprintf("tx %d", i)))
+	}
+	return data
+}
+
+// 레벨 수를 데이터 개수의 절반으로 반복하던 이전 NewMerkleTree의 루트 계산
+// 트랜잭션이 1~4개인 블록에서만 동작했으며, 기존 블록의 해시가 바뀌지 않았는지 비교하는 데 사용
+func legacyMerkleRoot(data [][]byte) []byte {
+	if len(data)%2 != 0 {
+		data = append(data, data[len(data)-1])
+	}
+
+	var nodes []MerkleNode
+	for _, dat := range data {
+		nodes = append(nodes, *NewMerkleNode(nil, nil, dat))
+	}
+
+	for i := 0; i < len(data)/2; i++ {
+		var level []MerkleNode
+		for j := 0; j < len(nodes); j += 2 {
+			level = append(level, *NewMerkleNode(&nodes[j], &nodes[j+1], nil))
+		}
+		nodes = level
+	}
+
+	return nodes[0].Data
+}
+
+// 두 해시를 이어 붙여 해시
+func hashPair(left, right []byte) []byte {
+	hash := sha256.Sum256(append(append([]byte{}, left...), right...))
+	return hash[:]
+}
+
+func TestMerkleRootUnchangedForSmallBlocks(t *testing.T) {
+	for n := 1; n <= 4; n++ {
+		data := merkleTestData(n)
+		if got, want := NewMerkleTree(data).RootNode.Data, legacyMerkleRoot(data); !bytes.Equal(got, want) {
+			t.Fatalf("%d transactions: root %x, previous algorithm gave %x", n, got, want)
+		}
+	}
+}
+
+func TestMerkleRootLargeBlocks(t *testing.T) {
+	leaf := func(i int) []byte {
+		hash := sha256.Sum256(merkleTestData(5)[i])
+		return hash[:]
+	}
+
+	// 5개: 홀수 개수의 레벨마다 마지막 노드를 한 번 더 사용
+	// ((0,1),(2,3)),((4,4),(4,4))
+	left := hashPair(hashPair(leaf(0), leaf(1)), hashPair(leaf(2), leaf(3)))
+	right := hashPair(hashPair(leaf(4), leaf(4)), hashPair(leaf(4), leaf(4)))
+	if got, want := NewMerkleTree(merkleTestData(5)).RootNode.Data, hashPair(left, right); !bytes.Equal(got, want) {
+		t.Fatalf("5 transactions: root %x, want %x", got, want)
+	}
+
+	// 더 많은 트랜잭션도 트리를 만들 수 있고 데이터가 다르면 루트도 다름
+	roots := make(map[string]bool)
+	for n := 5; n <= 33; n++ {
+		root := NewMerkleTree(merkleTestData(n)).RootNode.Data
+		if roots[string(root)] {
+			t.Fatalf("%d transactions: root %x repeats a smaller tree", n, root)
+		}
+		roots[string(root)] = true
+	}
+}
+
+func TestMerkleBranch(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		data := merkleTestData(n)
+		root := NewMerkleTree(data).RootNode.Data
+
+		for i := range data {
+			branch := MerkleBranch(data, i)
+			if got := MerkleRootFromBranch(data[i], i, branch); !bytes.Equal(got, root) {
+				t.Fatalf("%d transactions, index %d: branch gives root %x, want %x", n, i, got, root)
+			}
+
+			// 다른 데이터나 다른 위치로는 같은 루트가 나오지 않음
+			if got := MerkleRootFromBranch([]byte("other"), i, branch); bytes.Equal(got, root) {
+				t.Fatalf("%d transactions, index %d: branch proves other data", n, i)
+			}
+			if i^1 < n {
+				if got := MerkleRootFromBranch(data[i], i^1, branch); bytes.Equal(got, root) {
+					t.Fatalf("%d transactions, index %d: branch proves the wrong position", n, i)
+				}
+			}
+		}
+	}
+}
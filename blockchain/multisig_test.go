@@ -0,0 +1,166 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 테스트용 공개 키 n개
+func testPubKeys(n int) [][]byte {
+	var pubKeys [][]byte
+	for i := 0; i < n; i++ {
+		pubKeys = append(pubKeys, bytes.Repeat([]byte{byte(i + 1)}, 64))
+	}
+	return pubKeys
+}
+
+func TestNewMultiSigScript(t *testing.T) {
+	tests := []struct {
+		name string
+		m    int
+		keys int
+		ok   bool
+	}{
+		{"1-of-1", 1, 1, true},
+		{"2-of-3", 2, 3, true},
+		{"3-of-3", 3, 3, true},
+		{"no keys", 1, 0, false},
+		{"too many keys", 1, maxMultiSigKeys + 1, false},
+		{"no signatures", 0, 3, false},
+		{"more signatures than keys", 4, 3, false},
+		{"redeem script too large", 1, maxMultiSigKeys, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pubKeys := testPubKeys(test.keys)
+			script, err := NewMultiSigScript(test.m, pubKeys)
+			if (err == nil) != test.ok {
+				t.Fatalf("NewMultiSigScript: %v, want ok %v", err, test.ok)
+			}
+			if err != nil {
+				return
+			}
+
+			m, extracted := ExtractMultiSig(script)
+			if m != test.m || len(extracted) != len(pubKeys) {
+				t.Fatalf("ExtractMultiSig = %d-of-%d, want %d-of-%d", m, len(extracted), test.m, len(pubKeys))
+			}
+			for i := range pubKeys {
+				if !bytes.Equal(extracted[i], pubKeys[i]) {
+					t.Fatalf("public key %d does not round trip", i)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckMultiSig(t *testing.T) {
+	keys := testPubKeys(3)
+	twoOfThree, _ := NewMultiSigScript(2, keys)
+	oneOfOne, _ := NewMultiSigScript(1, keys[:1])
+	threeOfThree, _ := NewMultiSigScript(3, keys)
+
+	// 주어진 공개 키 순서대로 서명을 넣는 잠금 해제 스크립트
+	sigs := func(signers ...[]byte) []byte {
+		builder := NewScriptBuilder()
+		for _, pubKey := range signers {
+			builder.AddData(testSig(pubKey))
+		}
+		return builder.Script()
+	}
+	other := bytes.Repeat([]byte{0xff}, 64)
+
+	tests := []struct {
+		name         string
+		scriptSig    []byte
+		scriptPubKey []byte
+		ok           bool
+	}{
+		{"1-of-1", sigs(keys[0]), oneOfOne, true},
+		{"2-of-3 with keys 1 and 2", sigs(keys[0], keys[1]), twoOfThree, true},
+		{"2-of-3 with keys 1 and 3", sigs(keys[0], keys[2]), twoOfThree, true},
+		{"2-of-3 with keys 2 and 3", sigs(keys[1], keys[2]), twoOfThree, true},
+		{"3-of-3", sigs(keys[0], keys[1], keys[2]), threeOfThree, true},
+		{"signatures out of key order", sigs(keys[1], keys[0]), twoOfThree, false},
+		{"same signature twice", sigs(keys[0], keys[0]), twoOfThree, false},
+		{"signature from another key", sigs(keys[0], other), twoOfThree, false},
+		{"too few signatures", sigs(keys[0]), twoOfThree, false},
+		{"empty signature", NewScriptBuilder().AddData(testSig(keys[0])).AddOp(Op0).Script(), twoOfThree, false},
+		{"3-of-3 missing one", sigs(keys[0], keys[2]), threeOfThree, false},
+		{"more signatures than keys", sigs(keys[0]), ops(Op1+1, Op1, OpCheckMultiSig), false},
+		{"too many keys", nil, NewScriptBuilder().AddOp(Op0).AddData(scriptNumBytes(maxMultiSigKeys + 1)).AddOp(OpCheckMultiSig).Script(), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyScript(test.scriptSig, test.scriptPubKey, nil, 0, testCheckSig)
+			if (err == nil) != test.ok {
+				t.Fatalf("VerifyScript: %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
+
+func TestSpendMultiSigThroughP2SH(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	signers := []*wallet.Wallet{wallet.MakeWallet(), wallet.MakeWallet(), wallet.MakeWallet()}
+
+	var pubKeys [][]byte
+	for _, w := range signers {
+		pubKeys = append(pubKeys, w.PublicKey)
+	}
+	redeemScript, err := NewMultiSigScript(2, pubKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 채굴 보상을 2-of-3 다중 서명 P2SH 출력으로 전송
+	funding := coinbaseAt(chain, 1)
+	lock := TxOutput{ActiveParams.Subsidy, PayToScriptHashScript(wallet.PublicKeyHash(redeemScript))}
+	fund := spendTx(t, miner, funding, 0, lock)
+
+	// 주어진 서명자 순서대로 서명하여 다중 서명 출력을 사용하는 트랜잭션
+	spend := func(signed ...*wallet.Wallet) *Transaction {
+		tx := &Transaction{
+			Version: TxVersion,
+			Inputs:  []TxInput{{fund.ID, 0, nil, MaxTxInSequenceNum}},
+			Outputs: []TxOutput{*NewTXOutput(ActiveParams.Subsidy, string(miner.Address()))},
+		}
+		tx.ID = tx.Hash()
+
+		txCopy := tx.TrimmedCopy()
+		hash := txCopy.signatureHash(0, lock)
+		var sigs [][]byte
+		for _, w := range signed {
+			sigs = append(sigs, signHash(w.DeserializePrivateKey(w.PrivateKey), hash))
+		}
+		tx.Inputs[0].ScriptSig = MultiSigUnlockScript(sigs, redeemScript)
+
+		return tx
+	}
+
+	tests := []struct {
+		name   string
+		signed []*wallet.Wallet
+		ok     bool
+	}{
+		{"first and second signers", []*wallet.Wallet{signers[0], signers[1]}, true},
+		{"first and third signers", []*wallet.Wallet{signers[0], signers[2]}, true},
+		{"signatures out of key order", []*wallet.Wallet{signers[2], signers[0]}, false},
+		{"one signature", []*wallet.Wallet{signers[1]}, false},
+		{"outside signer", []*wallet.Wallet{signers[0], miner}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			txs := []*Transaction{CoinbaseTx(string(miner.Address()), ""), fund, spend(test.signed...)}
+			if err := (UTXOSet{chain}).CheckTransactions(txs); (err == nil) != test.ok {
+				t.Fatalf("CheckTransactions: %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// 데이터 출력이 있는 트랜잭션이 블록에 포함되었음을 증명하는 Merkle 증명
+// 블록 전체 없이 트랜잭션, Merkle 경로, 블록 헤더만으로 블록 해시와 작업 증명을 다시 확인할 수 있음
+type MerkleProof struct {
+	// 데이터 출력이 있는 트랜잭션
+	Tx *Transaction
+	// 블록 안에서 트랜잭션의 위치
+	Index int
+	// 트랜잭션에서 Merkle 루트까지의 형제 노드 해시
+	Branch [][]byte
+	// 블록 헤더(이전 블록 해시, nonce, 높이, 블록 해시)
+	PrevHash  []byte
+	Nonce     int
+	Height    int
+	BlockHash []byte
+}
+
+// 증명할 데이터가 체인에 없는 경우의 에러
+var ErrDataNotFound = errors.New("data is not anchored in the chain")
+
+// 체인에서 data를 기록한 가장 최근 트랜잭션을 찾아 Merkle 증명을 생성하는 함수
+func (chain *BlockChain) NewMerkleProof(data []byte) (*MerkleProof, error) {
+	iter := chain.Iterator()
+	for {
+		block := iter.Next()
+
+		for index, tx := range block.Transactions {
+			if !tx.HasData(data) {
+				continue
+			}
+
+			var leaves [][]byte
+			for _, blockTx := range block.Transactions {
+				leaves = append(leaves, blockTx.Serialize())
+			}
+
+			return &MerkleProof{
+				Tx:        tx,
+				Index:     index,
+				Branch:    MerkleBranch(leaves, index),
+				PrevHash:  block.PrevHash,
+				Nonce:     block.Nonce,
+				Height:    block.Height,
+				BlockHash: block.Hash,
+			}, nil
+		}
+
+		if len(block.PrevHash) == 0 {
+			break
+		}
+	}
+
+	return nil, ErrDataNotFound
+}
+
+// 트랜잭션의 데이터 출력에 data가 기록되어 있는지 확인
+func (tx *Transaction) HasData(data []byte) bool {
+	for _, out := range tx.Outputs {
+		if recorded := ExtractNullData(out.ScriptPubKey); recorded != nil && bytes.Equal(recorded, data) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 체인 없이 증명이 data를 기록한 트랜잭션과 작업 증명을 만족하는 블록 해시를 연결하는지 확인하는 함수
+func (p *MerkleProof) Verify(data []byte) error {
+	if !p.Tx.HasData(data) {
+		return errors.New("transaction does not record the data")
+	}
+
+	// Merkle 경로로 계산한 루트와 헤더로 블록 해시를 다시 계산
+	root := MerkleRootFromBranch(p.Tx.Serialize(), p.Index, p.Branch)
+	hash := sha256.Sum256(blockHeaderData(p.PrevHash, root, p.Nonce, ActiveParams.Difficulty))
+	if !bytes.Equal(hash[:], p.BlockHash) {
+		return errors.New("merkle branch does not lead to the block hash")
+	}
+
+	// 블록 해시가 작업 증명 목표값보다 작아야 함
+	var intHash big.Int
+	intHash.SetBytes(hash[:])
+	if intHash.Cmp(newProofWithDifficulty(&Block{}, ActiveParams.Difficulty).Target) != -1 {
+		return errors.New("block hash does not satisfy the proof of work")
+	}
+
+	return nil
+}
+
+// 증명을 확인하고 증명의 블록이 현재 체인에 있는지 확인하여 확인 수를 반환하는 함수
+func (chain *BlockChain) CheckMerkleProof(p *MerkleProof, data []byte) (int, error) {
+	if err := p.Verify(data); err != nil {
+		return 0, err
+	}
+
+	// 최신 블록부터 증명의 높이까지 내려가며 같은 블록인지 확인
+	iter := chain.Iterator()
+	for {
+		block := iter.Next()
+
+		if block.Height == p.Height {
+			if !bytes.Equal(block.Hash, p.BlockHash) {
+				return 0, fmt.Errorf("block %x is not in the chain", p.BlockHash)
+			}
+			return chain.GetBestHeight() - p.Height + 1, nil
+		}
+
+		if len(block.PrevHash) == 0 || block.Height < p.Height {
+			break
+		}
+	}
+
+	return 0, fmt.Errorf("block %x is not in the chain", p.BlockHash)
+}
+
+// Merkle 증명을 바이트 슬라이스로 직렬화
+func (p *MerkleProof) Serialize() []byte {
+	e := &encoder{}
+	e.writeByte(txEncodingVersion)
+	p.Tx.encode(e)
+	e.writeVarint(int64(p.Index))
+
+	e.writeUvarint(uint64(len(p.Branch)))
+	for _, hash := range p.Branch {
+		e.writeBytes(hash)
+	}
+
+	e.writeBytes(p.PrevHash)
+	e.writeVarint(int64(p.Nonce))
+	e.writeVarint(int64(p.Height))
+	e.writeBytes(p.BlockHash)
+
+	return e.Bytes()
+}
+
+// 바이트 슬라이스를 Merkle 증명으로 역직렬화
+func DeserializeMerkleProof(data []byte) (*MerkleProof, error) {
+	p := &MerkleProof{Tx: &Transaction{}}
+
+	d := newDecoder(data)
+	d.readVersion()
+	p.Tx.decode(d)
+	p.Index = d.readInt()
+
+	p.Branch = make([][]byte, d.readCount())
+	for i := range p.Branch {
+		p.Branch[i] = d.readBytes()
+	}
+
+	p.PrevHash = d.readBytes()
+	p.Nonce = d.readInt()
+	p.Height = d.readInt()
+	p.BlockHash = d.readBytes()
+
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,149 @@
+package blockchain
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 네트워크별 체인 파라미터
+type ChainParams struct {
+	// 네트워크 이름
+	Name string
+	// 네트워크 메시지 앞에 붙는 매직 바이트
+	Magic [4]byte
+	// 시드 노드가 사용하는 기본 포트
+	DefaultPort string
+	// 데이터베이스와 지갑 파일을 저장하는 디렉터리
+	DataDir string
+	// 블록 채굴 보상
+	Subsidy int
+	// 작업 증명 난이도(해시 앞부분의 0 비트 수)
+	Difficulty int
+	// 코인베이스 출력을 사용할 수 있을 때까지 필요한 블록 수
+	CoinbaseMaturity int
+	// 주소의 버전 바이트
+	AddressVersion byte
+	// 스크립트 해시 주소의 버전 바이트
+	ScriptHashVersion byte
+	// 내보낸 개인 키의 버전 바이트
+	PrivateKeyVersion byte
+	// HD 지갑 파생 경로의 코인 타입
+	HDCoinType uint32
+	// generate 명령으로 즉시 블록을 채굴할 수 있는지 여부
+	GenerateSupported bool
+
+	// Genesis 블록 구성 요소
+	GenesisTimestamp int64
+	GenesisData      string
+
+	// 계산된 Genesis 블록 캐시
+	genesis *Block
+}
+
+var (
+	// 메인 네트워크 파라미터
+	MainNetParams = ChainParams{
+		Name:              "mainnet",
+		Magic:             [4]byte{0xf9, 0xbe, 0xb4, 0xd9},
+		DefaultPort:       "3000",
+		DataDir:           "./tmp",
+		Subsidy:           20,
+		Difficulty:        12,
+		CoinbaseMaturity:  100,
+		AddressVersion:    0x00,
+		ScriptHashVersion: 0x05,
+		PrivateKeyVersion: 0x80,
+		HDCoinType:        0,
+		GenesisTimestamp:  1704067200,
+		GenesisData:       genesisData,
+	}
+
+	// 테스트 네트워크 파라미터
+	TestNetParams = ChainParams{
+		Name:              "testnet",
+		Magic:             [4]byte{0x0b, 0x11, 0x09, 0x07},
+		DefaultPort:       "13000",
+		DataDir:           "./tmp/testnet",
+		Subsidy:           20,
+		Difficulty:        12,
+		CoinbaseMaturity:  100,
+		AddressVersion:    0x6f,
+		ScriptHashVersion: 0xc4,
+		PrivateKeyVersion: 0xef,
+		HDCoinType:        1,
+		GenesisTimestamp:  1704067201,
+		GenesisData:       genesisData,
+	}
+
+	// 로컬 테스트용 회귀 테스트 네트워크 파라미터
+	RegTestParams = ChainParams{
+		Name:              "regtest",
+		Magic:             [4]byte{0xfa, 0xbf, 0xb5, 0xda},
+		DefaultPort:       "23000",
+		DataDir:           "./tmp/regtest",
+		Subsidy:           20,
+		Difficulty:        1,
+		CoinbaseMaturity:  100,
+		AddressVersion:    0x6f,
+		ScriptHashVersion: 0xc4,
+		PrivateKeyVersion: 0xef,
+		HDCoinType:        1,
+		GenerateSupported: true,
+		GenesisTimestamp:  1704067202,
+		GenesisData:       genesisData,
+	}
+
+	// 현재 사용 중인 체인 파라미터
+	ActiveParams = &MainNetParams
+)
+
+// 이름으로 체인 파라미터를 선택하는 함수
+func SelectParams(name string) error {
+	switch name {
+	case "", MainNetParams.Name:
+		ActiveParams = &MainNetParams
+	case TestNetParams.Name:
+		ActiveParams = &TestNetParams
+	case RegTestParams.Name:
+		ActiveParams = &RegTestParams
+	default:
+		return fmt.Errorf("unknown network %q", name)
+	}
+
+	// 지갑의 주소와 스크립트 해시 주소, 개인 키 버전, HD 코인 타입과 파일 위치도 선택된 네트워크에 맞춤
+	wallet.SetNetwork(ActiveParams.AddressVersion, ActiveParams.ScriptHashVersion, ActiveParams.PrivateKeyVersion, ActiveParams.HDCoinType, ActiveParams.DataDir)
+
+	return nil
+}
+
+// 네트워크의 고정된 Genesis 블록을 반환하는 함수
+// 모든 구성 요소가 고정되어 있으므로 항상 같은 블록이 생성됨
+func (p *ChainParams) GenesisBlock() *Block {
+	if p.genesis != nil {
+		return p.genesis
+	}
+
+	// 아무도 개인 키를 알 수 없는 공개 키 해시로 보상을 잠금
+	dataHash := sha256.Sum256([]byte(p.GenesisData))
+	lockHash := wallet.PublicKeyHash(dataHash[:])
+
+	// Genesis 코인베이스 트랜잭션
+	txin := TxInput{[]byte{}, -1, []byte(p.GenesisData), MaxTxInSequenceNum}
+	txout := TxOutput{p.Subsidy, PayToPubKeyHashScript(lockHash)}
+	coinbase := Transaction{Version: TxVersion, Inputs: []TxInput{txin}, Outputs: []TxOutput{txout}}
+	coinbase.ID = coinbase.Hash()
+
+	// 고정된 시간으로 블록을 생성하고 작업 증명 수행
+	block := &Block{p.GenesisTimestamp, []byte{}, []*Transaction{&coinbase}, []byte{}, 0, 0}
+	pow := newProofWithDifficulty(block, p.Difficulty)
+	nonce, hash := pow.Run()
+
+	block.Hash = hash
+	block.Nonce = nonce
+
+	p.genesis = block
+
+	return block
+}
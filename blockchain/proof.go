@@ -21,32 +21,44 @@ import (
 // Requirements:
 // The First few bytes must contain 0s
 
-const Difficulty = 12
+// 난이도는 네트워크별 체인 파라미터(ChainParams.Difficulty)로 정해짐
 
 type ProofOfWork struct {
-	Block  *Block
-	Target *big.Int
+	Block      *Block
+	Target     *big.Int
+	Difficulty int
 }
 
 // 블록을 가져오는 알고리즘의 첫 번째(새로운 증명)
 func NewProof(b *Block) *ProofOfWork {
+	return newProofWithDifficulty(b, ActiveParams.Difficulty)
+}
+
+// 주어진 난이도로 작업 증명을 생성하는 함수
+func newProofWithDifficulty(b *Block, difficulty int) *ProofOfWork {
 	target := big.NewInt(1)
 	// 해시 중 하나 내부의 바이트 수인 256에서 난이도를 뺌(Lsh 왼쪽 이동)
-	target.Lsh(target, uint(256-Difficulty))
+	target.Lsh(target, uint(256-difficulty))
 
-	pow := &ProofOfWork{b, target}
+	pow := &ProofOfWork{b, target, difficulty}
 
 	return pow
 }
 
 // 데이터 초기화 하는 함수
 func (pow *ProofOfWork) InitData(nonce int) []byte {
+	return blockHeaderData(pow.Block.PrevHash, pow.Block.HashTransactions(), nonce, pow.Difficulty)
+}
+
+// 블록 해시의 대상이 되는 데이터를 생성하는 함수
+// 트랜잭션 전체 대신 Merkle 루트만 있어도 블록 해시를 다시 계산할 수 있음
+func blockHeaderData(prevHash, merkleRoot []byte, nonce, difficulty int) []byte {
 	data := bytes.Join(
 		[][]byte{
-			pow.Block.PrevHash,
-			pow.Block.HashTransactions(),
+			prevHash,
+			merkleRoot,
 			ToHex(int64(nonce)),
-			ToHex(int64(Difficulty)),
+			ToHex(int64(difficulty)),
 		},
 		[]byte{},
 	)
@@ -96,6 +108,13 @@ func (pow *ProofOfWork) Validate() bool {
 	return intHash.Cmp(pow.Target) == -1
 }
 
+// 현재 nonce로 계산한 블록 해시를 반환하는 함수
+func (pow *ProofOfWork) Hash() []byte {
+	hash := sha256.Sum256(pow.InitData(pow.Block.Nonce))
+
+	return hash[:]
+}
+
 // int64 타입의 숫자를 16진수 바이트 배열로 변환하는 함수
 func ToHex(num int64) []byte {
 	buff := new(bytes.Buffer)
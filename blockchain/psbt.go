@@ -0,0 +1,349 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 입력에 대한 한 사람의 서명
+type PartialSig struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+// 부분 서명 트랜잭션의 입력별 정보
+type PSBTInput struct {
+	// 입력이 소비하는 이전 출력
+	PrevOutput TxOutput
+	// 이전 출력이 P2SH이면 해시가 일치하는 redeem script
+	RedeemScript []byte
+	// 지금까지 모인 서명(서명 전에는 비어 있음)
+	PartialSigs []PartialSig
+}
+
+// 여러 사람이 각자 소유한 입력에 서명할 수 있는 부분 서명 트랜잭션
+type PartiallySignedTransaction struct {
+	// 입력의 잠금 해제 스크립트가 비어 있는 트랜잭션
+	Tx     *Transaction
+	Inputs []PSBTInput
+}
+
+// 부분 서명 트랜잭션 관련 에러
+var (
+	ErrPSBTMismatch      = errors.New("partially signed transactions are for different transactions")
+	ErrPSBTIncomplete    = errors.New("not every input of the transaction is signed")
+	ErrInvalidPartialSig = errors.New("invalid partial signature")
+)
+
+// 입력과 출력으로 부분 서명 트랜잭션을 생성하는 함수
+// prevOutputs는 각 입력이 소비하는 이전 출력, redeemScripts는 P2SH 출력의 스크립트 해시(16진수)별 redeem script
+func NewPSBT(inputs []TxInput, outputs []TxOutput, prevOutputs []TxOutput, redeemScripts map[string][]byte) (*PartiallySignedTransaction, error) {
+	if len(inputs) == 0 || len(outputs) == 0 {
+		return nil, errors.New("transaction needs at least one input and one output")
+	}
+	if len(prevOutputs) != len(inputs) {
+		return nil, errors.New("previous outputs do not match the inputs")
+	}
+
+	// 출력 합계가 입력 합계를 넘을 수 없음
+	inputValue, outputValue := 0, 0
+	for _, out := range prevOutputs {
+		inputValue += out.Value
+	}
+	for _, out := range outputs {
+		outputValue += out.Value
+	}
+	if outputValue > inputValue {
+		return nil, fmt.Errorf("outputs (%d) exceed inputs (%d)", outputValue, inputValue)
+	}
+
+	tx := &Transaction{Version: TxVersion, Inputs: []TxInput{}, Outputs: outputs}
+	psbt := &PartiallySignedTransaction{Tx: tx}
+	for i, in := range inputs {
+		input := PSBTInput{PrevOutput: prevOutputs[i]}
+
+		switch ClassifyScript(input.PrevOutput.ScriptPubKey) {
+		case PubKeyHashScript:
+		case ScriptHashScript:
+			// 다중 서명 redeem script만 서명할 수 있음
+			input.RedeemScript = redeemScripts[hex.EncodeToString(input.PrevOutput.ScriptHash())]
+			if m, _ := ExtractMultiSig(input.RedeemScript); m == 0 {
+				return nil, fmt.Errorf("input %d: no multisig redeem script for the script hash", i)
+			}
+		default:
+			return nil, fmt.Errorf("input %d spends an output that cannot be signed", i)
+		}
+
+		tx.Inputs = append(tx.Inputs, TxInput{in.ID, in.Out, nil, MaxTxInSequenceNum})
+		psbt.Inputs = append(psbt.Inputs, input)
+	}
+	tx.ID = tx.Hash()
+
+	return psbt, nil
+}
+
+// 다중 서명 입력의 redeem script에 있는 공개 키 목록(P2PKH 입력은 nil)
+func (input *PSBTInput) signingKeys() [][]byte {
+	if input.RedeemScript != nil {
+		_, pubKeys := ExtractMultiSig(input.RedeemScript)
+		return pubKeys
+	}
+
+	return nil
+}
+
+// 입력에 필요한 서명 수
+func (input *PSBTInput) RequiredSigs() int {
+	if input.RedeemScript != nil {
+		m, _ := ExtractMultiSig(input.RedeemScript)
+		return m
+	}
+
+	return 1
+}
+
+// 공개 키의 서명 위치(서명이 없으면 -1)
+func (input *PSBTInput) sigIndex(pubKey []byte) int {
+	for i, sig := range input.PartialSigs {
+		if bytes.Equal(sig.PubKey, pubKey) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// 입력에 서명할 수 있는 공개 키의 올바른 서명인지 확인하는 함수
+func (psbt *PartiallySignedTransaction) checkPartialSig(inId int, sig PartialSig) error {
+	input := &psbt.Inputs[inId]
+
+	if input.RedeemScript != nil {
+		found := false
+		for _, pubKey := range input.signingKeys() {
+			if bytes.Equal(pubKey, sig.PubKey) {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: public key %x is not in the redeem script", ErrInvalidPartialSig, sig.PubKey)
+		}
+	} else if !bytes.Equal(wallet.PublicKeyHash(sig.PubKey), input.PrevOutput.PubKeyHash()) {
+		return fmt.Errorf("%w: public key %x does not own the output", ErrInvalidPartialSig, sig.PubKey)
+	}
+
+	txCopy := psbt.Tx.TrimmedCopy()
+	if !verifySignature(txCopy.signatureHash(inId, input.PrevOutput), sig.Signature, sig.PubKey) {
+		return fmt.Errorf("%w: signature of %x does not verify", ErrInvalidPartialSig, sig.PubKey)
+	}
+
+	return nil
+}
+
+// 필요한 수만큼 서명이 모였는지 확인
+func (input *PSBTInput) isComplete() bool {
+	return len(input.PartialSigs) >= input.RequiredSigs()
+}
+
+// 가진 개인 키로 서명할 수 있는 입력에 모두 서명하고, 추가한 서명 개수를 반환
+// keys는 16진수 공개 키 해시를 키로 사용
+func (psbt *PartiallySignedTransaction) Sign(keys map[string]*ecdsa.PrivateKey) int {
+	txCopy := psbt.Tx.TrimmedCopy()
+	signed := 0
+
+	for inId := range psbt.Inputs {
+		input := &psbt.Inputs[inId]
+		hash := txCopy.signatureHash(inId, input.PrevOutput)
+
+		// 서명할 개인 키 목록
+		var privKeys []*ecdsa.PrivateKey
+		if input.RedeemScript != nil {
+			for _, pubKey := range input.signingKeys() {
+				if privKey, ok := keys[hex.EncodeToString(wallet.PublicKeyHash(pubKey))]; ok {
+					privKeys = append(privKeys, privKey)
+				}
+			}
+		} else if privKey, ok := keys[hex.EncodeToString(input.PrevOutput.PubKeyHash())]; ok {
+			// 이전 출력을 잠근 공개 키 해시의 개인 키가 있어야 서명 가능
+			privKeys = append(privKeys, privKey)
+		}
+
+		for _, privKey := range privKeys {
+			pubKey := serializePubKey(privKey)
+			sig := PartialSig{pubKey, signHash(privKey, hash)}
+
+			// 이미 올바른 서명이 있으면 건너뛰고, 잘못된 서명이 있으면 새 서명으로 교체
+			if i := input.sigIndex(pubKey); i >= 0 {
+				if verifySignature(hash, input.PartialSigs[i].Signature, pubKey) {
+					continue
+				}
+				input.PartialSigs[i] = sig
+			} else {
+				input.PartialSigs = append(input.PartialSigs, sig)
+			}
+			signed++
+		}
+	}
+
+	return signed
+}
+
+// 같은 트랜잭션에 대한 다른 사람의 서명을 합치는 함수
+// 잘못된 서명이 같은 공개 키의 올바른 서명을 막지 않도록 합치기 전에 모든 서명을 검증
+func (psbt *PartiallySignedTransaction) Combine(other *PartiallySignedTransaction) error {
+	if !bytes.Equal(psbt.Tx.ID, other.Tx.ID) || len(psbt.Inputs) != len(other.Inputs) {
+		return ErrPSBTMismatch
+	}
+
+	for inId := range psbt.Inputs {
+		if !bytes.Equal(psbt.Inputs[inId].RedeemScript, other.Inputs[inId].RedeemScript) ||
+			!bytes.Equal(psbt.Inputs[inId].PrevOutput.ScriptPubKey, other.Inputs[inId].PrevOutput.ScriptPubKey) ||
+			psbt.Inputs[inId].PrevOutput.Value != other.Inputs[inId].PrevOutput.Value {
+			return ErrPSBTMismatch
+		}
+		for _, sig := range other.Inputs[inId].PartialSigs {
+			if err := psbt.checkPartialSig(inId, sig); err != nil {
+				return fmt.Errorf("input %d: %w", inId, err)
+			}
+		}
+	}
+
+	for inId := range psbt.Inputs {
+		input := &psbt.Inputs[inId]
+
+		// 아직 없는 공개 키의 서명을 추가하고, 저장된 서명이 잘못되었으면 올바른 서명으로 교체
+		for _, sig := range other.Inputs[inId].PartialSigs {
+			if i := input.sigIndex(sig.PubKey); i < 0 {
+				input.PartialSigs = append(input.PartialSigs, sig)
+			} else if psbt.checkPartialSig(inId, input.PartialSigs[i]) != nil {
+				input.PartialSigs[i] = sig
+			}
+		}
+	}
+
+	return nil
+}
+
+// 이전 출력을 UTXO 집합에서 확인하고 트랜잭션의 수수료를 반환
+func (psbt *PartiallySignedTransaction) CheckFee(UTXO UTXOSet) (int, error) {
+	var prevOutputs []TxOutput
+	for _, input := range psbt.Inputs {
+		prevOutputs = append(prevOutputs, input.PrevOutput)
+	}
+
+	return checkPrevOutputs(psbt.Tx, prevOutputs, UTXO)
+}
+
+// 필요한 서명이 모두 모인 입력 개수를 반환
+func (psbt *PartiallySignedTransaction) SignedCount() int {
+	count := 0
+	for i := range psbt.Inputs {
+		if psbt.Inputs[i].isComplete() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// 모든 입력에 필요한 서명이 모였으면 잠금 해제 스크립트를 만들고 검증하여 완성된 트랜잭션을 반환
+func (psbt *PartiallySignedTransaction) Finalize() (*Transaction, error) {
+	if psbt.SignedCount() != len(psbt.Inputs) {
+		return nil, ErrPSBTIncomplete
+	}
+
+	// 트랜잭션 ID는 잠금 해제 스크립트 없이 계산되므로 그대로 유지
+	tx := psbt.Tx.TrimmedCopy()
+	var prevOutputs []TxOutput
+	for inId := range psbt.Inputs {
+		input := &psbt.Inputs[inId]
+
+		if input.RedeemScript != nil {
+			// 다중 서명은 redeem script의 공개 키 순서대로 필요한 수만큼 서명을 넣음
+			var sigs [][]byte
+			for _, pubKey := range input.signingKeys() {
+				for _, sig := range input.PartialSigs {
+					if len(sigs) < input.RequiredSigs() && bytes.Equal(sig.PubKey, pubKey) {
+						sigs = append(sigs, sig.Signature)
+					}
+				}
+			}
+			tx.Inputs[inId].ScriptSig = MultiSigUnlockScript(sigs, input.RedeemScript)
+		} else {
+			sig := input.PartialSigs[0]
+			tx.Inputs[inId].ScriptSig = PayToPubKeyHashUnlockScript(sig.Signature, sig.PubKey)
+		}
+		prevOutputs = append(prevOutputs, input.PrevOutput)
+	}
+
+	// 공개 키가 이전 출력을 잠근 해시와 일치하는지도 스크립트에서 확인됨
+	if err := tx.verifyScripts(prevOutputs); err != nil {
+		return nil, fmt.Errorf("transaction does not verify: %w", err)
+	}
+
+	return &tx, nil
+}
+
+// 부분 서명 트랜잭션을 바이트 슬라이스로 직렬화
+func (psbt *PartiallySignedTransaction) Serialize() []byte {
+	e := &encoder{}
+	e.writeByte(txEncodingVersion)
+	psbt.Tx.encode(e)
+
+	e.writeUvarint(uint64(len(psbt.Inputs)))
+	for i := range psbt.Inputs {
+		input := &psbt.Inputs[i]
+		input.PrevOutput.encode(e)
+		e.writeBytes(input.RedeemScript)
+
+		e.writeUvarint(uint64(len(input.PartialSigs)))
+		for _, sig := range input.PartialSigs {
+			e.writeBytes(sig.PubKey)
+			e.writeBytes(sig.Signature)
+		}
+	}
+
+	return e.Bytes()
+}
+
+// 바이트 슬라이스를 부분 서명 트랜잭션으로 역직렬화
+func DeserializePSBT(data []byte) (*PartiallySignedTransaction, error) {
+	psbt := &PartiallySignedTransaction{Tx: &Transaction{}}
+
+	d := newDecoder(data)
+	d.readVersion()
+	psbt.Tx.decode(d)
+
+	psbt.Inputs = make([]PSBTInput, d.readCount())
+	for i := range psbt.Inputs {
+		input := &psbt.Inputs[i]
+		input.PrevOutput.decode(d)
+		if redeemScript := d.readBytes(); len(redeemScript) > 0 {
+			input.RedeemScript = redeemScript
+		}
+
+		input.PartialSigs = make([]PartialSig, d.readCount())
+		for j := range input.PartialSigs {
+			input.PartialSigs[j].PubKey = d.readBytes()
+			input.PartialSigs[j].Signature = d.readBytes()
+		}
+	}
+
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+
+	if len(psbt.Inputs) != len(psbt.Tx.Inputs) {
+		return nil, errors.New("input metadata does not match the inputs")
+	}
+	// 입력과 출력이 바뀌지 않았는지 확인
+	if !bytes.Equal(psbt.Tx.ID, psbt.Tx.Hash()) {
+		return nil, errors.New("transaction ID does not match its contents")
+	}
+
+	return psbt, nil
+}
@@ -0,0 +1,96 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// 파일의 이전 출력이 UTXO 집합의 출력과 다른 경우의 에러
+var ErrPrevOutputMismatch = errors.New("previous output does not match the UTXO set")
+
+// 다른 기기에서 서명할 수 있도록 이전 출력과 함께 전달하는 트랜잭션
+type RawTransaction struct {
+	Tx *Transaction
+	// 각 입력이 소비하는 이전 출력(입력과 같은 순서)
+	PrevOutputs []TxOutput
+}
+
+// 원시 트랜잭션을 바이트 슬라이스로 직렬화
+func (raw *RawTransaction) Serialize() []byte {
+	e := &encoder{}
+	e.writeByte(txEncodingVersion)
+	raw.Tx.encode(e)
+
+	e.writeUvarint(uint64(len(raw.PrevOutputs)))
+	for i := range raw.PrevOutputs {
+		raw.PrevOutputs[i].encode(e)
+	}
+
+	return e.Bytes()
+}
+
+// 바이트 슬라이스를 원시 트랜잭션으로 역직렬화
+func DeserializeRawTransaction(data []byte) (*RawTransaction, error) {
+	raw := &RawTransaction{Tx: &Transaction{}}
+
+	d := newDecoder(data)
+	d.readVersion()
+	raw.Tx.decode(d)
+
+	raw.PrevOutputs = make([]TxOutput, d.readCount())
+	for i := range raw.PrevOutputs {
+		raw.PrevOutputs[i].decode(d)
+	}
+
+	if err := d.finish(); err != nil {
+		return nil, err
+	}
+
+	// 이전 출력은 입력마다 하나씩 있어야 함
+	if len(raw.PrevOutputs) != len(raw.Tx.Inputs) {
+		return nil, errors.New("previous outputs do not match the inputs")
+	}
+	// 입력과 출력이 바뀌지 않았는지 확인(ID는 잠금 해제 스크립트 없이 계산됨)
+	unsigned := raw.Tx.TrimmedCopy()
+	if !bytes.Equal(raw.Tx.ID, unsigned.Hash()) {
+		return nil, errors.New("transaction ID does not match its contents")
+	}
+
+	return raw, nil
+}
+
+// 모든 입력이 올바르게 서명되었는지 확인
+func (raw *RawTransaction) IsSigned() bool {
+	return raw.Tx.VerifyWithPrevOutputs(raw.PrevOutputs)
+}
+
+// 이전 출력을 UTXO 집합에서 확인하고 트랜잭션의 수수료를 반환
+func (raw *RawTransaction) CheckFee(UTXO UTXOSet) (int, error) {
+	return checkPrevOutputs(raw.Tx, raw.PrevOutputs, UTXO)
+}
+
+// 서명 해시는 이전 출력의 금액을 포함하지 않으므로 트랜잭션과 함께 전달된 이전 출력의 금액은 믿을 수 없음
+// 서명하기 전에 각 입력의 이전 출력이 UTXO 집합의 출력과 같은지 확인하고, UTXO 집합의 금액으로 수수료를 계산하는 함수
+func checkPrevOutputs(tx *Transaction, prevOutputs []TxOutput, UTXO UTXOSet) (int, error) {
+	fee := 0
+	for inId, in := range tx.Inputs {
+		out, ok := UTXO.FindOutput(in.ID, in.Out)
+		if !ok {
+			return 0, fmt.Errorf("input %d spends %x:%d, which is not an unspent output", inId, in.ID, in.Out)
+		}
+		if out.Value != prevOutputs[inId].Value || !bytes.Equal(out.ScriptPubKey, prevOutputs[inId].ScriptPubKey) {
+			return 0, fmt.Errorf("%w: input %d spends %d, the transaction says %d", ErrPrevOutputMismatch, inId, out.Value, prevOutputs[inId].Value)
+		}
+		fee += out.Value
+	}
+
+	for _, out := range tx.Outputs {
+		fee -= out.Value
+	}
+	if fee < 0 {
+		return 0, fmt.Errorf("outputs exceed inputs by %d", -fee)
+	}
+
+	return fee, nil
+}
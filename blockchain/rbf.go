@@ -0,0 +1,54 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// 이 값 이하의 시퀀스 번호를 가진 입력이 있으면 수수료를 더 내는 트랜잭션으로 교체할 수 있음을 표시
+	MaxRBFSequence = MaxTxInSequenceNum - 2
+
+	// 교체 트랜잭션이 교체되는 트랜잭션들의 수수료 합계보다 더 내야 하는 1000바이트당 수수료
+	IncrementalRelayFeeRate FeeRate = 1
+)
+
+// 메모리 풀의 트랜잭션을 교체할 수 없는 경우의 에러
+var ErrReplacementRejected = errors.New("replacement transaction rejected")
+
+// 트랜잭션이 교체 가능함을 표시하는지 확인
+func (tx *Transaction) SignalsReplacement() bool {
+	for _, in := range tx.Inputs {
+		if in.Sequence <= MaxRBFSequence {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 수수료가 fee인 tx가 같은 출력을 사용하는 conflicts를 교체할 수 있는지 확인하는 함수
+// evictedFees는 conflicts와 그 출력을 사용하는 트랜잭션까지 교체로 제거되는 모든 트랜잭션의 수수료 합계
+// 충돌하는 트랜잭션이 모두 교체 가능함을 표시해야 하고, 제거되는 수수료에 tx의 크기만큼의 추가 수수료를 더 내야 함
+func CheckReplacement(tx *Transaction, fee int, conflicts []*Transaction, evictedFees int) error {
+	for _, conflict := range conflicts {
+		if !conflict.SignalsReplacement() {
+			return fmt.Errorf("%w: conflicting transaction %x does not signal replaceability", ErrReplacementRejected, conflict.ID)
+		}
+	}
+
+	required := evictedFees + IncrementalRelayFeeRate.FeeForSize(tx.EstimatedSize())
+	if fee < required {
+		return fmt.Errorf("%w: fee %d is less than %d (fees of replaced transactions %d plus relay fee)", ErrReplacementRejected, fee, required, evictedFees)
+	}
+
+	return nil
+}
+
+// 크기가 같은 트랜잭션으로 수수료가 oldFee인 tx를 교체하는 데 필요한 최소 수수료율
+func BumpedFeeRate(oldFee int, tx *Transaction) FeeRate {
+	size := tx.EstimatedSize()
+	required := oldFee + IncrementalRelayFeeRate.FeeForSize(size)
+
+	return FeeRate((required*1000 + size - 1) / size)
+}
@@ -0,0 +1,267 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dgraph-io/badger"
+)
+
+var (
+	// 스키마 버전을 저장하는 키
+	schemaVersionKey = []byte("schema-version")
+	// 마지막 블록 해시를 저장하는 키
+	lastHashKey = []byte("lh")
+)
+
+// 데이터베이스 스키마 마이그레이션 정보
+type migration struct {
+	// 마이그레이션 후의 스키마 버전
+	Version int
+	// 마이그레이션 설명
+	Description string
+	// 마이그레이션 수행 함수
+	Migrate func(chain *BlockChain) error
+}
+
+// 스키마 버전 순서대로 정렬된 마이그레이션 목록
+// 새로운 저장 형식을 도입할 때는 이 목록의 끝에 마이그레이션을 추가
+// 블록은 다시 기록하지 않음: 블록 해시와 서명은 블록을 만든 형식의 직렬화로 계산되므로
+// 이전 형식의 블록은 원래 형식 그대로 두고 읽을 때만 현재 구조체로 변환함(legacy.go)
+var migrations = []migration{
+	{1, "record schema version", func(chain *BlockChain) error { return nil }},
+	{2, "convert JSON encoded UTXO entries to binary encoding", migrateJSONToBinary},
+	{3, "convert UTXO entry outputs to locking scripts", migrateToScripts},
+	{4, "re-encode UTXO entries for transaction version, lock time and input sequence numbers", migrateToLockTimes},
+	{5, "record block height and coinbase flag in UTXO entries", migrateToUTXOHeights},
+	{6, "store UTXO entries per output instead of per transaction", migrateToOutpointKeys},
+	{7, "index UTXO entries by address hash", migrateToAddressIndex},
+}
+
+// 현재 코드가 사용하는 데이터베이스 스키마 버전
+var SchemaVersion = migrations[len(migrations)-1].Version
+
+// 데이터베이스 정보
+type DBInfo struct {
+	SchemaVersion int
+	Blocks        int
+	UTXOs         int
+	// 주소 색인 키 개수
+	AddressIndex int
+	Other        int
+}
+
+// 데이터베이스에 저장된 스키마 버전을 가져오는 함수(버전 키가 없으면 0)
+func getSchemaVersion(db *badger.DB) (int, error) {
+	version := 0
+
+	err := db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(schemaVersionKey)
+		// 버전 키가 없으면 버전 정보가 없던 초기 스키마
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		if len(value) != 8 {
+			return fmt.Errorf("invalid schema version value: %x", value)
+		}
+		version = int(binary.BigEndian.Uint64(value))
+
+		return nil
+	})
+
+	return version, err
+}
+
+// 스키마 버전을 데이터베이스에 저장하는 함수
+func setSchemaVersion(db *badger.DB, version int) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(version))
+
+	return db.Update(func(txn *badger.Txn) error {
+		return txn.Set(schemaVersionKey, value)
+	})
+}
+
+// 데이터베이스를 현재 스키마 버전으로 업그레이드하는 함수
+func (chain *BlockChain) Migrate() error {
+	// 저장된 스키마 버전 확인
+	version, err := getSchemaVersion(chain.Database)
+	if err != nil {
+		return err
+	}
+
+	// 코드보다 새로운 스키마는 처리할 수 없음
+	if version > SchemaVersion {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, SchemaVersion)
+	}
+
+	// 저장된 버전 이후의 마이그레이션을 순서대로 실행
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+
+		fmt.Printf("Migrating database to schema version %d: %s\n", m.Version, m.Description)
+		if err := m.Migrate(chain); err != nil {
+			return fmt.Errorf("migration to schema version %d failed: %w", m.Version, err)
+		}
+
+		// 마이그레이션마다 버전을 기록하여 중단되어도 이어서 진행할 수 있도록 함
+		if err := setSchemaVersion(chain.Database, m.Version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 데이터베이스의 스키마 버전과 키 개수를 조회하는 함수
+func (chain *BlockChain) DBInfo() DBInfo {
+	var info DBInfo
+
+	version, err := getSchemaVersion(chain.Database)
+	Handle(err)
+	info.SchemaVersion = version
+
+	err = chain.Database.View(func(txn *badger.Txn) error {
+		// 값은 필요 없으므로 키만 순회
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().Key()
+
+			// 키 종류에 따라 개수 집계
+			switch {
+			case bytes.HasPrefix(key, utxoPrefix):
+				info.UTXOs++
+			case bytes.HasPrefix(key, addressIndexPrefix):
+				info.AddressIndex++
+			case bytes.Equal(key, lastHashKey), bytes.Equal(key, schemaVersionKey):
+				info.Other++
+			default:
+				info.Blocks++
+			}
+		}
+
+		return nil
+	})
+	Handle(err)
+
+	return info
+}
+
+// 데이터베이스의 모든 값을 순회하며 변환 함수가 바꾼 값만 다시 기록하는 함수
+// convert 함수는 새 값과 변경 여부를 반환
+func rewriteValues(db *badger.DB, convert func(key, value []byte) ([]byte, bool, error)) error {
+	// 대량의 쓰기를 나누어 커밋하기 위해 WriteBatch 사용
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+
+	err := db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			key := item.KeyCopy(nil)
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+
+			newValue, changed, err := convert(key, value)
+			if err != nil {
+				return fmt.Errorf("key %x: %w", key, err)
+			}
+			if changed {
+				if err := wb.Set(key, newValue); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return wb.Flush()
+}
+
+// JSON으로 저장된 UTXO 항목을 바이너리 형식으로 다시 기록하는 마이그레이션
+// JSON 블록은 JSON 그대로 두고 읽을 때 변환(블록 해시와 서명이 JSON 직렬화로 계산되어 있음)
+func migrateJSONToBinary(chain *BlockChain) error {
+	return rewriteValues(chain.Database, func(key, value []byte) ([]byte, bool, error) {
+		// JSON 객체가 아닌 UTXO 항목(이미 변환된 값)과 블록 등 다른 값은 건너뜀
+		if !bytes.HasPrefix(key, utxoPrefix) || len(value) == 0 || value[0] != jsonEncodingVersion {
+			return nil, false, nil
+		}
+
+		var outs struct{ Outputs []legacyJSONTxOutput }
+		if err := json.Unmarshal(value, &outs); err != nil {
+			return nil, false, err
+		}
+		return TxOutputs{Outputs: convertLegacyOutputs(outs.Outputs)}.Serialize(), true, nil
+	})
+}
+
+// 공개 키 해시를 저장하던 버전 1 형식의 UTXO 항목을 스크립트 형식으로 다시 기록하는 마이그레이션
+// 버전 1 데이터는 읽을 때 스크립트로 변환되므로 다시 직렬화하면 됨
+// 버전 1 블록은 원래 형식 그대로 둠
+func migrateToScripts(chain *BlockChain) error {
+	return reencodeValues(chain, legacyEncodingVersion)
+}
+
+// 버전 2 형식의 UTXO 항목을 다시 기록하는 마이그레이션
+// 버전 2 데이터는 읽을 때 버전 1, 잠금 없음, 최종 시퀀스 번호로 채워지며, 버전 2 블록은 원래 형식 그대로 둠
+func migrateToLockTimes(chain *BlockChain) error {
+	return reencodeValues(chain, scriptEncodingVersion)
+}
+
+// UTXO 항목에 출력을 만든 블록 높이와 코인베이스 여부를 기록하는 마이그레이션
+// 높이와 코인베이스 여부는 블록에서만 알 수 있으므로 UTXO 집합을 다시 생성(블록 형식은 바뀌지 않음)
+func migrateToUTXOHeights(chain *BlockChain) error {
+	UTXOSet{chain}.Reindex()
+
+	return nil
+}
+
+// 트랜잭션별 UTXO 항목을 출력마다(트랜잭션 ID + 출력 인덱스) 저장하는 마이그레이션
+// 이전 항목은 사용된 출력을 빼면서 남은 출력의 원래 인덱스를 잃었으므로 변환하지 않고 블록에서 UTXO 집합을 다시 생성
+func migrateToOutpointKeys(chain *BlockChain) error {
+	UTXOSet{chain}.Reindex()
+
+	return nil
+}
+
+// 주소 해시별 UTXO 색인을 만드는 마이그레이션
+// UTXO 항목은 바뀌지 않으므로 저장된 항목에서 색인만 생성
+func migrateToAddressIndex(chain *BlockChain) error {
+	return UTXOSet{chain}.reindexAddresses()
+}
+
+// 주어진 형식 버전으로 직렬화된 UTXO 항목을 현재 형식으로 다시 직렬화하는 함수
+func reencodeValues(chain *BlockChain, version byte) error {
+	return rewriteValues(chain.Database, func(key, value []byte) ([]byte, bool, error) {
+		// 해당 형식 버전이 아닌 UTXO 항목(이미 변환된 값)과 블록 등 다른 값은 건너뜀
+		if !bytes.HasPrefix(key, utxoPrefix) || len(value) == 0 || value[0] != version {
+			return nil, false, nil
+		}
+
+		return DeserializeOutputs(value).Serialize(), true, nil
+	})
+}
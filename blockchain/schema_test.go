@@ -0,0 +1,78 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dgraph-io/badger"
+)
+
+// 스키마 버전 정보가 없는 데이터베이스에 주어진 값들을 기록하고 블록체인으로 여는 함수
+func openOldDatabase(t *testing.T, values map[string][]byte, lastHash []byte) *BlockChain {
+	t.Helper()
+
+	db, err := openDB(t.TempDir(), badger.DefaultOptions(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = db.Update(func(txn *badger.Txn) error {
+		for key, value := range values {
+			if err := txn.Set([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		return txn.Set(lastHashKey, lastHash)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &BlockChain{lastHash, db}
+}
+
+func TestMigrateKeepsJSONBlocksVerifiable(t *testing.T) {
+	data := oldTestBlock(t, jsonEncodingVersion)
+	block := Deserialize(data)
+	spend := block.Transactions[1]
+
+	// JSON 시절의 UTXO 항목은 트랜잭션 ID를 키로 남은 출력 목록을 저장
+	utxo := []byte(`{"Outputs":[{"Value":20,"PubKeyHash":null}]}`)
+	chain := openOldDatabase(t, map[string][]byte{
+		string(block.Hash):                      data,
+		string(append(utxoPrefix, spend.ID...)): utxo,
+	}, block.Hash)
+
+	if err := chain.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 블록은 원래 JSON 그대로 남아 있고 작업 증명과 서명을 검증할 수 있어야 함
+	migrated := chain.Iterator().Next()
+	if !bytes.Equal(migrated.Serialize(), data) {
+		t.Fatal("migration rewrote the JSON block")
+	}
+	if !NewProof(migrated).Validate() {
+		t.Fatal("migrated block fails proof of work")
+	}
+	coinbase := migrated.Transactions[0]
+	if err := migrated.Transactions[1].verifyScripts([]TxOutput{coinbase.Outputs[0]}); err != nil {
+		t.Fatalf("migrated transaction fails signature check: %v", err)
+	}
+
+	// UTXO 집합은 블록에서 다시 만들어져 사용된 코인베이스 출력은 없어야 함
+	set := UTXOSet{chain}
+	if _, ok := set.FindEntry(coinbase.ID, 0); ok {
+		t.Fatal("spent coinbase output is still in the UTXO set")
+	}
+	entry, ok := set.FindEntry(spend.ID, 0)
+	if !ok || entry.Output.Value != 20 || entry.Coinbase {
+		t.Fatalf("unexpected UTXO entry for the spend: %+v, %v", entry, ok)
+	}
+
+	version, err := getSchemaVersion(chain.Database)
+	if err != nil || version != SchemaVersion {
+		t.Fatalf("schema version %d (%v), want %d", version, err, SchemaVersion)
+	}
+}
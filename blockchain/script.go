@@ -0,0 +1,701 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 스크립트 실행 제한
+const (
+	// 스크립트의 최대 크기(바이트)
+	maxScriptSize = 10000
+	// 스택에 넣을 수 있는 데이터의 최대 크기(바이트)
+	maxElementSize = 520
+	// 스택의 최대 깊이
+	maxStackSize = 1000
+	// 스크립트 하나에서 실행할 수 있는 데이터 push 외 명령어의 최대 개수
+	maxOpsPerScript = 201
+	// 다중 서명에 사용할 수 있는 공개 키의 최대 개수
+	maxMultiSigKeys = 16
+	// 스크립트 숫자의 최대 길이(바이트)
+	maxScriptNumLength = 4
+	// 잠금 시간 숫자의 최대 길이(바이트, 부호 비트 때문에 uint32보다 1바이트 김)
+	maxLockTimeNumLength = 5
+)
+
+// 스크립트 명령어(opcode)
+const (
+	// 빈 데이터(거짓)를 넣음
+	Op0 byte = 0x00
+	// 0x01~0x4b는 뒤따르는 해당 길이의 데이터를 넣음
+	OpPushData1 byte = 0x4c
+	OpPushData2 byte = 0x4d
+	// 1~16 숫자를 넣음
+	Op1  byte = 0x51
+	Op16 byte = 0x60
+
+	// 흐름 제어
+	OpIf     byte = 0x63
+	OpNotIf  byte = 0x64
+	OpElse   byte = 0x67
+	OpEndIf  byte = 0x68
+	OpVerify byte = 0x69
+	OpReturn byte = 0x6a
+
+	// 스택 조작
+	OpDrop byte = 0x75
+	OpDup  byte = 0x76
+	OpSwap byte = 0x7c
+	OpSize byte = 0x82
+
+	// 비교
+	OpEqual       byte = 0x87
+	OpEqualVerify byte = 0x88
+
+	// 해시와 서명
+	OpSHA256              byte = 0xa8
+	OpHash160             byte = 0xa9
+	OpCheckSig            byte = 0xac
+	OpCheckSigVerify      byte = 0xad
+	OpCheckMultiSig       byte = 0xae
+	OpCheckMultiSigVerify byte = 0xaf
+
+	// 잠금 시간
+	OpCheckLockTimeVerify byte = 0xb1
+	OpCheckSequenceVerify byte = 0xb2
+)
+
+// 디스어셈블에 사용하는 명령어 이름
+var opcodeNames = map[byte]string{
+	Op0:                   "OP_0",
+	OpIf:                  "OP_IF",
+	OpNotIf:               "OP_NOTIF",
+	OpElse:                "OP_ELSE",
+	OpEndIf:               "OP_ENDIF",
+	OpVerify:              "OP_VERIFY",
+	OpReturn:              "OP_RETURN",
+	OpDrop:                "OP_DROP",
+	OpDup:                 "OP_DUP",
+	OpSwap:                "OP_SWAP",
+	OpSize:                "OP_SIZE",
+	OpEqual:               "OP_EQUAL",
+	OpEqualVerify:         "OP_EQUALVERIFY",
+	OpSHA256:              "OP_SHA256",
+	OpHash160:             "OP_HASH160",
+	OpCheckSig:            "OP_CHECKSIG",
+	OpCheckSigVerify:      "OP_CHECKSIGVERIFY",
+	OpCheckMultiSig:       "OP_CHECKMULTISIG",
+	OpCheckMultiSigVerify: "OP_CHECKMULTISIGVERIFY",
+	OpCheckLockTimeVerify: "OP_CHECKLOCKTIMEVERIFY",
+	OpCheckSequenceVerify: "OP_CHECKSEQUENCEVERIFY",
+}
+
+var (
+	// 스크립트 실행 결과가 거짓인 경우의 에러
+	ErrScriptFailed = errors.New("script evaluated to false")
+	// 스크립트 형식이 올바르지 않은 경우의 에러
+	ErrMalformedScript = errors.New("malformed script")
+)
+
+// 파싱된 스크립트 명령어와 명령어가 넣는 데이터
+type scriptOp struct {
+	opcode byte
+	data   []byte
+}
+
+// 데이터를 넣는 명령어인지 여부
+func (op scriptOp) isPush() bool {
+	return op.opcode <= OpPushData2 || (op.opcode >= Op1 && op.opcode <= Op16)
+}
+
+// 스크립트를 명령어 목록으로 파싱하는 함수
+func parseScript(script []byte) ([]scriptOp, error) {
+	var ops []scriptOp
+
+	for i := 0; i < len(script); {
+		opcode := script[i]
+		i++
+
+		// 데이터를 넣는 명령어는 길이만큼 데이터를 읽음
+		length := -1
+		switch {
+		case opcode > Op0 && opcode < OpPushData1:
+			length = int(opcode)
+		case opcode == OpPushData1:
+			if i+1 > len(script) {
+				return nil, ErrMalformedScript
+			}
+			length = int(script[i])
+			i++
+		case opcode == OpPushData2:
+			if i+2 > len(script) {
+				return nil, ErrMalformedScript
+			}
+			length = int(binary.LittleEndian.Uint16(script[i:]))
+			i += 2
+		}
+
+		op := scriptOp{opcode: opcode}
+		if length >= 0 {
+			if i+length > len(script) {
+				return nil, ErrMalformedScript
+			}
+			op.data = script[i : i+length]
+			i += length
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+// 데이터를 넣는 명령어로만 이루어진 스크립트인지 여부
+func isPushOnly(ops []scriptOp) bool {
+	for _, op := range ops {
+		if !op.isPush() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 스크립트를 사람이 읽을 수 있는 문자열로 변환하는 함수
+func DisassembleScript(script []byte) string {
+	ops, err := parseScript(script)
+	if err != nil {
+		return fmt.Sprintf("[error: %v] %x", err, script)
+	}
+
+	var words []string
+	for _, op := range ops {
+		// 넣는 데이터는 16진수로 표시
+		if op.opcode != Op0 && op.opcode <= OpPushData2 {
+			words = append(words, fmt.Sprintf("%x", op.data))
+		} else {
+			words = append(words, opcodeName(op.opcode))
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// 명령어 이름을 반환하는 함수
+func opcodeName(opcode byte) string {
+	if name, ok := opcodeNames[opcode]; ok {
+		return name
+	}
+	if opcode >= Op1 && opcode <= Op16 {
+		return fmt.Sprintf("OP_%d", opcode-Op1+1)
+	}
+	if opcode <= OpPushData2 {
+		return fmt.Sprintf("OP_PUSH_%#x", opcode)
+	}
+	return fmt.Sprintf("OP_UNKNOWN_%#x", opcode)
+}
+
+// 흐름 제어 명령어인지 여부
+func isConditional(opcode byte) bool {
+	return opcode == OpIf || opcode == OpNotIf || opcode == OpElse || opcode == OpEndIf
+}
+
+// 스크립트를 만드는 빌더
+type ScriptBuilder struct {
+	script []byte
+}
+
+// 새로운 스크립트 빌더 생성
+func NewScriptBuilder() *ScriptBuilder {
+	return &ScriptBuilder{}
+}
+
+// 명령어 추가
+func (b *ScriptBuilder) AddOp(opcode byte) *ScriptBuilder {
+	b.script = append(b.script, opcode)
+	return b
+}
+
+// 데이터 길이에 맞는 push 명령어로 데이터 추가
+func (b *ScriptBuilder) AddData(data []byte) *ScriptBuilder {
+	switch {
+	case len(data) == 0:
+		b.script = append(b.script, Op0)
+	case len(data) < int(OpPushData1):
+		b.script = append(b.script, byte(len(data)))
+	case len(data) <= 0xff:
+		b.script = append(b.script, OpPushData1, byte(len(data)))
+	default:
+		length := make([]byte, 2)
+		binary.LittleEndian.PutUint16(length, uint16(len(data)))
+		b.script = append(append(b.script, OpPushData2), length...)
+	}
+	b.script = append(b.script, data...)
+
+	return b
+}
+
+// 만들어진 스크립트 반환
+func (b *ScriptBuilder) Script() []byte {
+	return b.script
+}
+
+// 정수를 스크립트 숫자 형식(부호 비트가 있는 리틀 엔디언)으로 변환하는 함수
+func scriptNumBytes(n int) []byte {
+	if n == 0 {
+		return nil
+	}
+
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+
+	var result []byte
+	for n > 0 {
+		result = append(result, byte(n&0xff))
+		n >>= 8
+	}
+
+	// 최상위 비트가 이미 사용 중이면 부호를 위한 바이트 추가
+	if result[len(result)-1]&0x80 != 0 {
+		extra := byte(0x00)
+		if negative {
+			extra = 0x80
+		}
+		result = append(result, extra)
+	} else if negative {
+		result[len(result)-1] |= 0x80
+	}
+
+	return result
+}
+
+// 스크립트 숫자 형식의 데이터를 정수로 변환하는 함수
+func scriptNum(data []byte) (int, error) {
+	return parseScriptNum(data, maxScriptNumLength)
+}
+
+// 최대 길이를 지정하여 스크립트 숫자 형식의 데이터를 정수로 변환하는 함수
+func parseScriptNum(data []byte, maxLength int) (int, error) {
+	if len(data) > maxLength {
+		return 0, fmt.Errorf("number of %d bytes exceeds the limit", len(data))
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	n := 0
+	for i, b := range data {
+		n |= int(b) << (8 * i)
+	}
+
+	// 마지막 바이트의 최상위 비트는 부호
+	if data[len(data)-1]&0x80 != 0 {
+		n &^= 0x80 << (8 * (len(data) - 1))
+		n = -n
+	}
+
+	return n, nil
+}
+
+// 스택 데이터를 참/거짓으로 해석하는 함수(0과 음의 0은 거짓)
+func asBool(data []byte) bool {
+	for i, b := range data {
+		if b != 0 {
+			// 마지막 바이트의 부호 비트만 있는 경우는 음의 0
+			if i == len(data)-1 && b == 0x80 {
+				return false
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// 참/거짓을 스택 데이터로 변환하는 함수
+func fromBool(v bool) []byte {
+	if v {
+		return []byte{1}
+	}
+	return nil
+}
+
+// 스크립트를 실행하는 스택 기반 인터프리터
+type scriptEngine struct {
+	stack [][]byte
+	// 중첩된 OP_IF의 실행 여부
+	condStack []bool
+	// 서명 검증 함수(트랜잭션 입력의 서명 대상 해시를 알고 있음)
+	checkSig func(sig, pubKey []byte) bool
+	// 잠금 시간 확인에 사용하는 트랜잭션과 검증 중인 입력 번호
+	tx   *Transaction
+	inId int
+}
+
+// 스택에 데이터 추가
+func (vm *scriptEngine) push(data []byte) error {
+	if len(data) > maxElementSize {
+		return fmt.Errorf("push of %d bytes exceeds the element size limit", len(data))
+	}
+	if len(vm.stack) >= maxStackSize {
+		return errors.New("stack size limit exceeded")
+	}
+	vm.stack = append(vm.stack, data)
+	return nil
+}
+
+// 스택에서 데이터를 꺼냄
+func (vm *scriptEngine) pop() ([]byte, error) {
+	if len(vm.stack) == 0 {
+		return nil, errors.New("stack is empty")
+	}
+	data := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return data, nil
+}
+
+// 현재 실행 중인 분기인지 여부
+func (vm *scriptEngine) executing() bool {
+	for _, cond := range vm.condStack {
+		if !cond {
+			return false
+		}
+	}
+	return true
+}
+
+// 스크립트 하나를 실행하는 함수
+func (vm *scriptEngine) execute(script []byte) error {
+	if len(script) > maxScriptSize {
+		return fmt.Errorf("script size %d exceeds the limit", len(script))
+	}
+
+	ops, err := parseScript(script)
+	if err != nil {
+		return err
+	}
+
+	opCount := 0
+	for _, op := range ops {
+		if !op.isPush() {
+			opCount++
+			if opCount > maxOpsPerScript {
+				return errors.New("operation limit exceeded")
+			}
+		}
+
+		// 실행하지 않는 분기에서는 흐름 제어 명령어만 처리
+		if !vm.executing() && !isConditional(op.opcode) {
+			continue
+		}
+
+		if err := vm.step(op); err != nil {
+			return fmt.Errorf("%s: %w", opcodeName(op.opcode), err)
+		}
+	}
+
+	if len(vm.condStack) != 0 {
+		return errors.New("unbalanced conditional")
+	}
+
+	return nil
+}
+
+// 명령어 하나를 실행하는 함수
+func (vm *scriptEngine) step(op scriptOp) error {
+	switch {
+	case op.opcode >= Op1 && op.opcode <= Op16:
+		return vm.push(scriptNumBytes(int(op.opcode - Op1 + 1)))
+	case op.isPush():
+		return vm.push(op.data)
+	}
+
+	switch op.opcode {
+	case OpIf, OpNotIf:
+		cond := false
+		if vm.executing() {
+			top, err := vm.pop()
+			if err != nil {
+				return err
+			}
+			cond = asBool(top) == (op.opcode == OpIf)
+		}
+		vm.condStack = append(vm.condStack, cond)
+
+	case OpElse:
+		if len(vm.condStack) == 0 {
+			return errors.New("OP_ELSE without OP_IF")
+		}
+		vm.condStack[len(vm.condStack)-1] = !vm.condStack[len(vm.condStack)-1]
+
+	case OpEndIf:
+		if len(vm.condStack) == 0 {
+			return errors.New("OP_ENDIF without OP_IF")
+		}
+		vm.condStack = vm.condStack[:len(vm.condStack)-1]
+
+	case OpVerify:
+		top, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		if !asBool(top) {
+			return ErrScriptFailed
+		}
+
+	case OpReturn:
+		return errors.New("output is unspendable")
+
+	case OpDrop:
+		_, err := vm.pop()
+		return err
+
+	case OpDup:
+		if len(vm.stack) == 0 {
+			return errors.New("stack is empty")
+		}
+		return vm.push(vm.stack[len(vm.stack)-1])
+
+	case OpSwap:
+		if len(vm.stack) < 2 {
+			return errors.New("stack has fewer than 2 items")
+		}
+		n := len(vm.stack)
+		vm.stack[n-1], vm.stack[n-2] = vm.stack[n-2], vm.stack[n-1]
+
+	case OpSize:
+		if len(vm.stack) == 0 {
+			return errors.New("stack is empty")
+		}
+		return vm.push(scriptNumBytes(len(vm.stack[len(vm.stack)-1])))
+
+	case OpEqual, OpEqualVerify:
+		a, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		b, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		equal := bytes.Equal(a, b)
+		if op.opcode == OpEqualVerify {
+			if !equal {
+				return ErrScriptFailed
+			}
+			return nil
+		}
+		return vm.push(fromBool(equal))
+
+	case OpSHA256:
+		data, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(data)
+		return vm.push(hash[:])
+
+	case OpHash160:
+		data, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		return vm.push(wallet.PublicKeyHash(data))
+
+	case OpCheckSig, OpCheckSigVerify:
+		pubKey, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		sig, err := vm.pop()
+		if err != nil {
+			return err
+		}
+		valid := len(sig) > 0 && len(pubKey) > 0 && vm.checkSig(sig, pubKey)
+		if op.opcode == OpCheckSigVerify {
+			if !valid {
+				return ErrScriptFailed
+			}
+			return nil
+		}
+		return vm.push(fromBool(valid))
+
+	case OpCheckMultiSig, OpCheckMultiSigVerify:
+		valid, err := vm.checkMultiSig()
+		if err != nil {
+			return err
+		}
+		if op.opcode == OpCheckMultiSigVerify {
+			if !valid {
+				return ErrScriptFailed
+			}
+			return nil
+		}
+		return vm.push(fromBool(valid))
+
+	case OpCheckLockTimeVerify, OpCheckSequenceVerify:
+		// 스택 맨 위의 잠금 값은 꺼내지 않고 확인만 함
+		if len(vm.stack) == 0 {
+			return errors.New("stack is empty")
+		}
+		value, err := parseScriptNum(vm.stack[len(vm.stack)-1], maxLockTimeNumLength)
+		if err != nil {
+			return err
+		}
+		if value < 0 {
+			return errors.New("negative lock time")
+		}
+		if vm.tx == nil {
+			return errors.New("lock time check needs a transaction")
+		}
+		if op.opcode == OpCheckLockTimeVerify {
+			return vm.tx.checkLockTime(vm.inId, int64(value))
+		}
+		return vm.tx.checkSequence(vm.inId, int64(value))
+
+	default:
+		return errors.New("unknown opcode")
+	}
+
+	return nil
+}
+
+// 스택에서 정수를 꺼냄
+func (vm *scriptEngine) popInt() (int, error) {
+	data, err := vm.pop()
+	if err != nil {
+		return 0, err
+	}
+	return scriptNum(data)
+}
+
+// 스택의 <서명 1> ... <서명 M> M <공개 키 1> ... <공개 키 N> N 을 꺼내 다중 서명을 검증하는 함수
+// 서명은 공개 키와 같은 순서여야 함
+func (vm *scriptEngine) checkMultiSig() (bool, error) {
+	numKeys, err := vm.popInt()
+	if err != nil {
+		return false, err
+	}
+	if numKeys < 0 || numKeys > maxMultiSigKeys {
+		return false, fmt.Errorf("invalid number of public keys %d", numKeys)
+	}
+
+	pubKeys := make([][]byte, numKeys)
+	for i := numKeys - 1; i >= 0; i-- {
+		if pubKeys[i], err = vm.pop(); err != nil {
+			return false, err
+		}
+	}
+
+	numSigs, err := vm.popInt()
+	if err != nil {
+		return false, err
+	}
+	if numSigs < 0 || numSigs > numKeys {
+		return false, fmt.Errorf("invalid number of signatures %d", numSigs)
+	}
+
+	sigs := make([][]byte, numSigs)
+	for i := numSigs - 1; i >= 0; i-- {
+		if sigs[i], err = vm.pop(); err != nil {
+			return false, err
+		}
+	}
+
+	// 각 서명에 맞는 공개 키를 순서대로 찾음
+	keyIdx := 0
+	for _, sig := range sigs {
+		for ; keyIdx < len(pubKeys); keyIdx++ {
+			if len(sig) > 0 && len(pubKeys[keyIdx]) > 0 && vm.checkSig(sig, pubKeys[keyIdx]) {
+				break
+			}
+		}
+		// 남은 공개 키 중에 맞는 키가 없음
+		if keyIdx == len(pubKeys) {
+			return false, nil
+		}
+		keyIdx++
+	}
+
+	return true, nil
+}
+
+// 입력의 잠금 해제 스크립트와 이전 출력의 잠금 스크립트를 차례로 실행하여 검증하는 함수
+// 잠금 해제 스크립트는 데이터만 넣을 수 있고, 실행 후 스택 맨 위의 값이 참이어야 함
+// 잠금 스크립트가 스크립트 해시(P2SH)이면 잠금 해제 스크립트의 마지막 데이터를 redeem script로 실행
+// tx와 inId는 잠금 시간 명령어가 확인할 트랜잭션과 입력 번호
+func VerifyScript(scriptSig, scriptPubKey []byte, tx *Transaction, inId int, checkSig func(sig, pubKey []byte) bool) error {
+	ops, err := parseScript(scriptSig)
+	if err != nil {
+		return err
+	}
+	if !isPushOnly(ops) {
+		return errors.New("unlocking script is not push only")
+	}
+
+	vm := &scriptEngine{checkSig: checkSig, tx: tx, inId: inId}
+	if err := vm.execute(scriptSig); err != nil {
+		return err
+	}
+	// redeem script 실행을 위해 잠금 해제 스크립트 실행 후의 스택 보관
+	sigStack := append([][]byte{}, vm.stack...)
+
+	if err := vm.execute(scriptPubKey); err != nil {
+		return err
+	}
+	if len(vm.stack) == 0 || !asBool(vm.stack[len(vm.stack)-1]) {
+		return ErrScriptFailed
+	}
+
+	if ExtractScriptHash(scriptPubKey) == nil {
+		return nil
+	}
+
+	// 해시가 일치한 redeem script를 나머지 데이터로 실행
+	vm.stack = sigStack
+	redeemScript, err := vm.pop()
+	if err != nil {
+		return err
+	}
+	if err := vm.execute(redeemScript); err != nil {
+		return fmt.Errorf("redeem script: %w", err)
+	}
+	if len(vm.stack) == 0 || !asBool(vm.stack[len(vm.stack)-1]) {
+		return ErrScriptFailed
+	}
+
+	return nil
+}
+
+// 스크립트의 서명 검증 연산 수를 세는 함수
+// accurate이면 OP_CHECKMULTISIG 바로 앞의 OP_n으로 공개 키 수를 세고, 아니면 최대 공개 키 수로 계산
+func countSigOps(script []byte, accurate bool) int {
+	ops, err := parseScript(script)
+	if err != nil {
+		return 0
+	}
+
+	sigOps := 0
+	for i, op := range ops {
+		switch op.opcode {
+		case OpCheckSig, OpCheckSigVerify:
+			sigOps++
+		case OpCheckMultiSig, OpCheckMultiSigVerify:
+			if accurate && i > 0 && ops[i-1].opcode >= Op1 && ops[i-1].opcode <= Op16 {
+				sigOps += int(ops[i-1].opcode - Op1 + 1)
+			} else {
+				sigOps += maxMultiSigKeys
+			}
+		}
+	}
+
+	return sigOps
+}
@@ -0,0 +1,153 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 테스트에서 사용하는 서명 검증 함수(서명이 "sig:"와 공개 키를 이은 값이면 올바른 서명)
+func testCheckSig(sig, pubKey []byte) bool {
+	return bytes.Equal(sig, testSig(pubKey))
+}
+
+// 공개 키에 대한 테스트 서명
+func testSig(pubKey []byte) []byte {
+	return append([]byte("sig:"), pubKey...)
+}
+
+// 명령어를 이어 붙인 스크립트
+func ops(opcodes ...byte) []byte {
+	return opcodes
+}
+
+// 명령어를 n번 반복한 스크립트
+func repeatOps(n int, opcodes ...byte) []byte {
+	return bytes.Repeat(opcodes, n)
+}
+
+func TestParseScript(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []byte
+		ops    int
+		err    bool
+	}{
+		{"empty", nil, 0, false},
+		{"direct push", []byte{0x02, 0xaa, 0xbb, OpDup}, 2, false},
+		{"pushdata1", []byte{OpPushData1, 0x01, 0xaa}, 1, false},
+		{"pushdata2", []byte{OpPushData2, 0x01, 0x00, 0xaa}, 1, false},
+		{"truncated direct push", []byte{0x05, 0xaa, 0xbb}, 0, true},
+		{"pushdata1 without length", []byte{OpPushData1}, 0, true},
+		{"truncated pushdata1", []byte{OpPushData1, 0x03, 0xaa}, 0, true},
+		{"pushdata2 with short length", []byte{OpPushData2, 0x01}, 0, true},
+		{"truncated pushdata2", []byte{OpPushData2, 0x00, 0x01, 0xaa}, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := parseScript(test.script)
+			if (err != nil) != test.err {
+				t.Fatalf("parseScript: %v, want error %v", err, test.err)
+			}
+			if err != nil && !errors.Is(err, ErrMalformedScript) {
+				t.Fatalf("got %v, want %v", err, ErrMalformedScript)
+			}
+			if err == nil && len(parsed) != test.ops {
+				t.Fatalf("parsed %d ops, want %d", len(parsed), test.ops)
+			}
+		})
+	}
+}
+
+func TestVerifyScript(t *testing.T) {
+	pubKey, otherKey := bytes.Repeat([]byte{0x02}, 33), bytes.Repeat([]byte{0x03}, 33)
+	p2pkh := PayToPubKeyHashScript(wallet.PublicKeyHash(pubKey))
+
+	redeemScript := NewScriptBuilder().AddData(pubKey).AddOp(OpCheckSig).Script()
+	p2sh := PayToScriptHashScript(wallet.PublicKeyHash(redeemScript))
+	falseRedeemScript := NewScriptBuilder().AddOp(Op0).Script()
+
+	// 크기 제한 테스트에 사용하는 큰 데이터를 넣고 버리는 스크립트
+	bigPushDrop := NewScriptBuilder().AddData(bytes.Repeat([]byte{1}, maxElementSize)).AddOp(OpDrop).Script()
+
+	// OP_IF OP_IF 2 OP_ELSE 3 OP_ENDIF OP_ELSE 4 OP_ENDIF 3 OP_EQUAL
+	nested := ops(OpIf, OpIf, Op1+1, OpElse, Op1+2, OpEndIf, OpElse, Op1+3, OpEndIf, Op1+2, OpEqual)
+
+	tests := []struct {
+		name         string
+		scriptSig    []byte
+		scriptPubKey []byte
+		ok           bool
+	}{
+		{"p2pkh", PayToPubKeyHashUnlockScript(testSig(pubKey), pubKey), p2pkh, true},
+		{"p2pkh with wrong signature", PayToPubKeyHashUnlockScript(testSig(otherKey), pubKey), p2pkh, false},
+		{"p2pkh with wrong public key", PayToPubKeyHashUnlockScript(testSig(otherKey), otherKey), p2pkh, false},
+		{"p2pkh with empty signature", PayToPubKeyHashUnlockScript(nil, pubKey), p2pkh, false},
+
+		{"p2sh", NewScriptBuilder().AddData(testSig(pubKey)).AddData(redeemScript).Script(), p2sh, true},
+		{"p2sh with wrong signature", NewScriptBuilder().AddData(testSig(otherKey)).AddData(redeemScript).Script(), p2sh, false},
+		{"p2sh with wrong redeem script", NewScriptBuilder().AddData(testSig(pubKey)).AddData(falseRedeemScript).Script(), p2sh, false},
+		{"p2sh redeem script evaluating to false", NewScriptBuilder().AddData(falseRedeemScript).Script(), PayToScriptHashScript(wallet.PublicKeyHash(falseRedeemScript)), false},
+		{"p2sh without redeem script", nil, p2sh, false},
+
+		{"nested if, inner else", ops(Op0, Op1), nested, true},
+		{"nested if, inner if", ops(Op1, Op1), nested, false},
+		{"nested if, outer else", ops(Op0), nested, false},
+		{"notif", ops(Op0), ops(OpNotIf, Op1, OpElse, Op0, OpEndIf), true},
+		{"unexecuted branch is skipped", nil, ops(Op0, OpIf, OpReturn, OpEndIf, Op1), true},
+		{"if without endif", ops(Op1), ops(OpIf, Op1), false},
+		{"else without if", ops(Op1), ops(OpElse, Op1), false},
+		{"endif without if", ops(Op1), ops(OpEndIf, Op1), false},
+		{"conditional in scriptSig", ops(Op1, OpIf), ops(OpEndIf), false},
+		{"if with empty stack", nil, ops(OpIf, Op1, OpEndIf), false},
+
+		{"op limit reached", ops(Op1), append(ops(OpDup), repeatOps(maxOpsPerScript/2, OpDup, OpDrop)...), true},
+		{"op limit exceeded", ops(Op1), append(ops(OpDup, OpDup), repeatOps(maxOpsPerScript/2, OpDup, OpDrop)...), false},
+		{"stack limit reached", repeatOps(maxStackSize, Op1), nil, true},
+		{"stack limit exceeded", repeatOps(maxStackSize+1, Op1), nil, false},
+		{"element size limit reached", NewScriptBuilder().AddData(bytes.Repeat([]byte{1}, maxElementSize)).Script(), nil, true},
+		{"element size limit exceeded", NewScriptBuilder().AddData(bytes.Repeat([]byte{1}, maxElementSize+1)).Script(), nil, false},
+		{"script size limit reached", nil, append(bytes.Repeat(bigPushDrop, maxScriptSize/len(bigPushDrop)), Op1), true},
+		{"script size limit exceeded", nil, append(bytes.Repeat(bigPushDrop, maxScriptSize/len(bigPushDrop)+1), Op1), false},
+
+		{"truncated push in scriptSig", []byte{0x05, 0x01}, ops(Op1), false},
+		{"truncated push in scriptPubKey", ops(Op1), []byte{OpPushData1, 0x02, 0x01}, false},
+		{"non-push scriptSig", ops(Op1, OpDup), ops(OpEqual), false},
+		{"non-push p2sh scriptSig", append(NewScriptBuilder().AddData(testSig(pubKey)).AddData(redeemScript).Script(), OpDup, OpDrop), p2sh, false},
+
+		{"op_return", ops(Op1), ops(OpReturn), false},
+		{"unknown opcode", ops(Op1), ops(0xff), false},
+		{"empty stack", nil, nil, false},
+		{"false result", ops(Op0), nil, false},
+		{"negative zero is false", []byte{0x01, 0x80}, nil, false},
+		{"verify", ops(Op1), ops(Op1, OpVerify), true},
+		{"verify false", ops(Op1), ops(Op0, OpVerify), false},
+		{"swap and size", NewScriptBuilder().AddData([]byte("abc")).AddOp(Op1).Script(), ops(OpSwap, OpSize, Op1+2, OpEqualVerify, OpDrop), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyScript(test.scriptSig, test.scriptPubKey, nil, 0, testCheckSig)
+			if (err == nil) != test.ok {
+				t.Fatalf("VerifyScript: %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+}
+
+func TestScriptNum(t *testing.T) {
+	for _, n := range []int{0, 1, -1, 127, 128, -128, 255, 256, 32767, -32768, 1<<31 - 1} {
+		got, err := scriptNum(scriptNumBytes(n))
+		if err != nil || got != n {
+			t.Fatalf("scriptNum(scriptNumBytes(%d)) = %d, %v", n, got, err)
+		}
+	}
+
+	// 최대 길이를 넘는 숫자는 거부
+	if _, err := scriptNum([]byte{1, 2, 3, 4, 5}); err == nil {
+		t.Fatal("scriptNum accepted a 5 byte number")
+	}
+}
@@ -0,0 +1,216 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 표준 스크립트 종류
+type ScriptClass int
+
+const (
+	// 표준 템플릿에 해당하지 않는 스크립트
+	NonStandardScript ScriptClass = iota
+	// 공개 키 해시로 잠긴 스크립트(P2PKH)
+	PubKeyHashScript
+	// 스크립트 해시로 잠긴 스크립트(P2SH)
+	ScriptHashScript
+	// M-of-N 다중 서명 스크립트
+	MultiSigScript
+	// 데이터를 기록하는 사용할 수 없는 스크립트(OP_RETURN)
+	NullDataScript
+)
+
+// 공개 키 해시 길이(RIPEMD-160)
+const pubKeyHashLength = 20
+
+// 데이터 출력에 기록할 수 있는 데이터의 최대 크기(바이트)
+const MaxDataCarrierSize = 80
+
+// 스크립트 종류의 이름
+func (class ScriptClass) String() string {
+	switch class {
+	case PubKeyHashScript:
+		return "pubkeyhash"
+	case ScriptHashScript:
+		return "scripthash"
+	case MultiSigScript:
+		return "multisig"
+	case NullDataScript:
+		return "nulldata"
+	default:
+		return "nonstandard"
+	}
+}
+
+// 잠금 스크립트의 종류를 판별하는 함수
+func ClassifyScript(script []byte) ScriptClass {
+	if ExtractPubKeyHash(script) != nil {
+		return PubKeyHashScript
+	}
+	if ExtractScriptHash(script) != nil {
+		return ScriptHashScript
+	}
+	if m, _ := ExtractMultiSig(script); m > 0 {
+		return MultiSigScript
+	}
+	if ExtractNullData(script) != nil {
+		return NullDataScript
+	}
+
+	return NonStandardScript
+}
+
+// 공개 키 해시로 잠그는 스크립트 생성
+// OP_DUP OP_HASH160 <공개 키 해시> OP_EQUALVERIFY OP_CHECKSIG
+func PayToPubKeyHashScript(pubKeyHash []byte) []byte {
+	return NewScriptBuilder().
+		AddOp(OpDup).
+		AddOp(OpHash160).
+		AddData(pubKeyHash).
+		AddOp(OpEqualVerify).
+		AddOp(OpCheckSig).
+		Script()
+}
+
+// 공개 키 해시로 잠긴 출력의 잠금 해제 스크립트 생성
+// <서명> <공개 키>
+func PayToPubKeyHashUnlockScript(sig, pubKey []byte) []byte {
+	return NewScriptBuilder().AddData(sig).AddData(pubKey).Script()
+}
+
+// P2PKH 잠금 스크립트에서 공개 키 해시를 추출하는 함수(다른 종류의 스크립트는 nil)
+func ExtractPubKeyHash(script []byte) []byte {
+	ops, err := parseScript(script)
+	if err != nil || len(ops) != 5 {
+		return nil
+	}
+
+	if ops[0].opcode != OpDup || ops[1].opcode != OpHash160 ||
+		ops[2].opcode != pubKeyHashLength || ops[3].opcode != OpEqualVerify || ops[4].opcode != OpCheckSig {
+		return nil
+	}
+
+	return ops[2].data
+}
+
+// P2PKH 잠금 해제 스크립트에서 공개 키를 추출하는 함수(다른 형식은 nil)
+func extractUnlockPubKey(script []byte) []byte {
+	ops, err := parseScript(script)
+	if err != nil || len(ops) != 2 || !isPushOnly(ops) {
+		return nil
+	}
+
+	return ops[1].data
+}
+
+// 스크립트 해시로 잠그는 스크립트 생성
+// OP_HASH160 <redeem script 해시> OP_EQUAL
+func PayToScriptHashScript(scriptHash []byte) []byte {
+	return NewScriptBuilder().
+		AddOp(OpHash160).
+		AddData(scriptHash).
+		AddOp(OpEqual).
+		Script()
+}
+
+// P2SH 잠금 스크립트에서 스크립트 해시를 추출하는 함수(다른 종류의 스크립트는 nil)
+func ExtractScriptHash(script []byte) []byte {
+	ops, err := parseScript(script)
+	if err != nil || len(ops) != 3 {
+		return nil
+	}
+
+	if ops[0].opcode != OpHash160 || ops[1].opcode != pubKeyHashLength || ops[2].opcode != OpEqual {
+		return nil
+	}
+
+	return ops[1].data
+}
+
+// M-of-N 다중 서명 스크립트 생성
+// M <공개 키 1> ... <공개 키 N> N OP_CHECKMULTISIG
+func NewMultiSigScript(m int, pubKeys [][]byte) ([]byte, error) {
+	if len(pubKeys) == 0 || len(pubKeys) > maxMultiSigKeys {
+		return nil, fmt.Errorf("number of public keys must be between 1 and %d", maxMultiSigKeys)
+	}
+	if m < 1 || m > len(pubKeys) {
+		return nil, fmt.Errorf("required signatures must be between 1 and %d", len(pubKeys))
+	}
+
+	builder := NewScriptBuilder().AddOp(Op1 + byte(m-1))
+	for _, pubKey := range pubKeys {
+		builder.AddData(pubKey)
+	}
+	script := builder.AddOp(Op1 + byte(len(pubKeys)-1)).AddOp(OpCheckMultiSig).Script()
+
+	// P2SH로 사용할 때 잠금 해제 스크립트에 넣을 수 있는 크기여야 함
+	if len(script) > maxElementSize {
+		return nil, errors.New("too many public keys for a redeem script")
+	}
+
+	return script, nil
+}
+
+// 다중 서명 스크립트에서 필요한 서명 수와 공개 키 목록을 추출하는 함수(다른 종류의 스크립트는 0)
+func ExtractMultiSig(script []byte) (int, [][]byte) {
+	ops, err := parseScript(script)
+	if err != nil || len(ops) < 4 || ops[len(ops)-1].opcode != OpCheckMultiSig {
+		return 0, nil
+	}
+
+	first, last := ops[0].opcode, ops[len(ops)-2].opcode
+	if first < Op1 || first > Op16 || last < Op1 || last > Op16 {
+		return 0, nil
+	}
+
+	m, n := int(first-Op1+1), int(last-Op1+1)
+	keys := ops[1 : len(ops)-2]
+	if len(keys) != n || m > n {
+		return 0, nil
+	}
+
+	var pubKeys [][]byte
+	for _, op := range keys {
+		if op.opcode == Op0 || op.opcode > OpPushData2 {
+			return 0, nil
+		}
+		pubKeys = append(pubKeys, op.data)
+	}
+
+	return m, pubKeys
+}
+
+// 다중 서명 redeem script를 사용하는 P2SH 출력의 잠금 해제 스크립트 생성
+// <서명 1> ... <서명 M> <redeem script>
+func MultiSigUnlockScript(sigs [][]byte, redeemScript []byte) []byte {
+	builder := NewScriptBuilder()
+	for _, sig := range sigs {
+		builder.AddData(sig)
+	}
+
+	return builder.AddData(redeemScript).Script()
+}
+
+// 데이터를 기록하는 사용할 수 없는 스크립트 생성
+// OP_RETURN <데이터>
+func NewNullDataScript(data []byte) ([]byte, error) {
+	if len(data) == 0 || len(data) > MaxDataCarrierSize {
+		return nil, fmt.Errorf("data must be between 1 and %d bytes", MaxDataCarrierSize)
+	}
+
+	return NewScriptBuilder().AddOp(OpReturn).AddData(data).Script(), nil
+}
+
+// 데이터 스크립트에서 기록된 데이터를 추출하는 함수(다른 종류의 스크립트나 크기 제한을 넘는 데이터는 nil)
+func ExtractNullData(script []byte) []byte {
+	ops, err := parseScript(script)
+	if err != nil || len(ops) != 2 || ops[0].opcode != OpReturn {
+		return nil
+	}
+	if ops[1].opcode == Op0 || ops[1].opcode > OpPushData2 || len(ops[1].data) > MaxDataCarrierSize {
+		return nil
+	}
+
+	return ops[1].data
+}
@@ -0,0 +1,178 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"sort"
+)
+
+const (
+	// 코인베이스 트랜잭션을 위해 블록 템플릿에서 남겨 두는 크기와 서명 검증 연산 수
+	coinbaseReserveSize   = 1000
+	coinbaseReserveSigOps = 100
+)
+
+// 다음 블록에 포함할 트랜잭션 목록
+type BlockTemplate struct {
+	// 이전 블록 해시와 새 블록의 높이
+	PrevHash []byte
+	Height   int
+	// 코인베이스를 제외한 트랜잭션(부모 트랜잭션은 항상 자식보다 앞에 옴)
+	Transactions []*Transaction
+	// 각 트랜잭션의 수수료
+	Fees []int
+	// 코인베이스가 받을 수 있는 금액(채굴 보상과 수수료 합계)
+	CoinbaseValue int
+	// 코인베이스를 제외한 트랜잭션의 크기와 서명 검증 연산 수 합계
+	Size   int
+	SigOps int
+}
+
+// 블록 템플릿 후보 트랜잭션
+type templateTx struct {
+	tx     *Transaction
+	fee    int
+	size   int
+	sigOps int
+	// 후보 중 이 트랜잭션이 출력을 사용하는 부모 트랜잭션
+	parents []*templateTx
+	// 모든 조상 트랜잭션 수(부모는 항상 자식보다 작음)
+	ancestorCount int
+	included      bool
+}
+
+// 블록에 아직 포함되지 않은 자신과 모든 조상 트랜잭션(패키지)을 반환하는 함수
+func (c *templateTx) pendingPackage() []*templateTx {
+	seen := make(map[*templateTx]bool)
+	var pkg []*templateTx
+
+	var visit func(t *templateTx)
+	visit = func(t *templateTx) {
+		if t.included || seen[t] {
+			return
+		}
+		seen[t] = true
+		pkg = append(pkg, t)
+		for _, parent := range t.parents {
+			visit(parent)
+		}
+	}
+	visit(c)
+
+	return pkg
+}
+
+// 후보 트랜잭션으로 다음 블록의 템플릿을 만드는 함수
+// 코인베이스 후보와 UTXO 집합과 다른 후보의 출력으로 적용할 수 없거나 서명이 잘못되었거나, 잠금이 풀리지 않았거나
+// 성숙하지 않은 코인베이스 출력을 사용하는 트랜잭션은 제외하고,
+// 아직 포함되지 않은 조상까지 묶은 패키지의 수수료율이 높은 순서로 크기와 서명 검증 연산 수 제한 안에서 선택
+// 자식의 수수료가 높으면 수수료가 낮은 부모도 함께 포함됨
+func (chain *BlockChain) NewBlockTemplate(candidates []*Transaction) *BlockTemplate {
+	// 부모가 먼저 적용되도록 반복하며 후보를 검증하여 뷰에 적용하고 수수료와 서명 검증 연산 수 계산
+	view := NewUTXOView(UTXOSet{chain})
+	pool := make(map[string]*templateTx)
+	var order []*templateTx
+
+	pending := append([]*Transaction{}, candidates...)
+	for progress := true; progress; {
+		progress = false
+		var rest []*Transaction
+		for _, tx := range pending {
+			// 코인베이스는 템플릿이 직접 만들므로 후보에서 제외
+			if tx.IsCoinbase() {
+				continue
+			}
+
+			sigOps := view.SigOps(tx)
+			if err := view.CheckInputs(tx); err != nil {
+				rest = append(rest, tx)
+				continue
+			}
+			fee, err := view.Connect(tx)
+			if err != nil {
+				rest = append(rest, tx)
+				continue
+			}
+
+			c := &templateTx{tx: tx, fee: fee, size: len(tx.Serialize()), sigOps: sigOps}
+			pool[hex.EncodeToString(tx.ID)] = c
+			order = append(order, c)
+			progress = true
+		}
+		pending = rest
+	}
+
+	// 후보 사이의 부모 관계와 조상 수 계산(order는 부모가 자식보다 앞에 있음)
+	for _, c := range order {
+		ancestors := make(map[*templateTx]bool)
+		for _, in := range c.tx.Inputs {
+			parent, ok := pool[hex.EncodeToString(in.ID)]
+			if !ok || ancestors[parent] {
+				continue
+			}
+			c.parents = append(c.parents, parent)
+			for _, ancestor := range parent.pendingPackage() {
+				ancestors[ancestor] = true
+			}
+		}
+		c.ancestorCount = len(ancestors)
+	}
+
+	template := &BlockTemplate{
+		PrevHash:      chain.LastHash,
+		Height:        chain.GetBestHeight() + 1,
+		CoinbaseValue: ActiveParams.Subsidy,
+	}
+	maxSize := MaxBlockSize - coinbaseReserveSize
+	maxSigOps := MaxBlockSigOps - coinbaseReserveSigOps
+
+	for {
+		// 제한 안에 들어가는 패키지 중 수수료율이 가장 높은 패키지 선택
+		var best []*templateTx
+		bestFee, bestSize := 0, 0
+		for _, c := range order {
+			if c.included {
+				continue
+			}
+
+			pkg := c.pendingPackage()
+			fee, size, sigOps := 0, 0, 0
+			for _, t := range pkg {
+				fee += t.fee
+				size += t.size
+				sigOps += t.sigOps
+			}
+			if template.Size+size > maxSize || template.SigOps+sigOps > maxSigOps {
+				continue
+			}
+
+			if best == nil || fee*bestSize > bestFee*size {
+				best, bestFee, bestSize = pkg, fee, size
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		// 조상 수가 적은 트랜잭션부터 추가하여 부모가 자식보다 앞에 오도록 함
+		sort.SliceStable(best, func(i, j int) bool { return best[i].ancestorCount < best[j].ancestorCount })
+		for _, t := range best {
+			t.included = true
+			template.Transactions = append(template.Transactions, t.tx)
+			template.Fees = append(template.Fees, t.fee)
+			template.CoinbaseValue += t.fee
+			template.Size += t.size
+			template.SigOps += t.sigOps
+		}
+	}
+
+	return template
+}
+
+// 템플릿의 트랜잭션 수수료를 모두 받는 코인베이스 트랜잭션을 만들어 블록에 포함할 트랜잭션 목록을 반환하는 함수
+func (t *BlockTemplate) BlockTransactions(to string) []*Transaction {
+	cbTx := CoinbaseTx(to, "")
+	cbTx.Outputs[0].Value = t.CoinbaseValue
+	cbTx.ID = cbTx.Hash()
+
+	return append([]*Transaction{cbTx}, t.Transactions...)
+}
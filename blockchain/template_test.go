@@ -0,0 +1,116 @@
+package blockchain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 수수료가 없는 부모와 수수료를 내는 자식(CPFP)을 만드는 함수
+func parentAndChild(t *testing.T, chain *BlockChain, miner *wallet.Wallet) (*Transaction, *Transaction) {
+	t.Helper()
+
+	alice, bob := wallet.MakeWallet(), wallet.MakeWallet()
+
+	// 높이 1 블록의 채굴 보상을 수수료 없이 alice에게 전송
+	parent := spendTx(t, miner, coinbaseAt(chain, 1), 0, *NewTXOutput(ActiveParams.Subsidy, string(alice.Address())))
+
+	// 아직 블록에 포함되지 않은 부모의 출력을 사용하고 수수료 5를 내는 자식
+	child := spendTx(t, alice, parent, 0, *NewTXOutput(ActiveParams.Subsidy-5, string(bob.Address())))
+
+	return parent, child
+}
+
+func TestBlockTemplateIncludesParentBeforeChild(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	parent, child := parentAndChild(t, chain, miner)
+
+	// 자식이 먼저 주어져도 부모가 앞에 오고 자식의 수수료가 코인베이스에 더해져야 함
+	template := chain.NewBlockTemplate([]*Transaction{child, parent})
+	if len(template.Transactions) != 2 {
+		t.Fatalf("template has %d transactions, want 2", len(template.Transactions))
+	}
+	if string(template.Transactions[0].ID) != string(parent.ID) || string(template.Transactions[1].ID) != string(child.ID) {
+		t.Fatalf("template does not order the parent before the child")
+	}
+	if template.CoinbaseValue != ActiveParams.Subsidy+5 {
+		t.Fatalf("coinbase value %d, want %d", template.CoinbaseValue, ActiveParams.Subsidy+5)
+	}
+}
+
+func TestMineParentAndChildTogether(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	parent, child := parentAndChild(t, chain, miner)
+
+	template := chain.NewBlockTemplate([]*Transaction{child, parent})
+	block := chain.MineBlock(template.BlockTransactions(string(miner.Address())))
+	UTXOSet := UTXOSet{chain}
+	UTXOSet.Update(block)
+
+	// 부모의 출력은 같은 블록의 자식이 사용했고 자식의 출력은 블록 높이와 함께 남아 있어야 함
+	if _, ok := UTXOSet.FindEntry(parent.ID, 0); ok {
+		t.Fatalf("parent output is still unspent")
+	}
+	utxo, ok := UTXOSet.FindEntry(child.ID, 0)
+	if !ok {
+		t.Fatalf("child output is missing")
+	}
+	if utxo.Height != block.Height || utxo.Coinbase || utxo.Output.Value != ActiveParams.Subsidy-5 {
+		t.Fatalf("child output %+v, want height %d and value %d", utxo, block.Height, ActiveParams.Subsidy-5)
+	}
+
+	// 블록에서 UTXO 집합을 다시 만들어도 같은 결과여야 함
+	UTXOSet.Reindex()
+	if _, ok := UTXOSet.FindEntry(parent.ID, 0); ok {
+		t.Fatalf("parent output is unspent after reindexing")
+	}
+	if _, ok := UTXOSet.FindEntry(child.ID, 0); !ok {
+		t.Fatalf("child output is missing after reindexing")
+	}
+}
+
+func TestConnectBlockWithParentAndChild(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	parent, child := parentAndChild(t, chain, miner)
+
+	cbTx := CoinbaseTx(string(miner.Address()), "")
+	cbTx.Outputs[0].Value = ActiveParams.Subsidy + 5
+	cbTx.ID = cbTx.Hash()
+
+	// 자식이 부모보다 앞에 있으면 자식이 사용하는 출력이 아직 없으므로 거부
+	block := CreateBlock([]*Transaction{cbTx, child, parent}, chain.LastHash, chain.GetBestHeight()+1, chain.NextBlockTime())
+	if err := chain.ValidateBlock(block); !errors.Is(err, ErrMissingOutput) {
+		t.Fatalf("block with the child before its parent: got %v, want %v", err, ErrMissingOutput)
+	}
+
+	// 다른 노드가 채굴한 블록처럼 검증을 거쳐 연결
+	block = CreateBlock([]*Transaction{cbTx, parent, child}, chain.LastHash, chain.GetBestHeight()+1, chain.NextBlockTime())
+	if err := chain.ConnectBlock(block); err != nil {
+		t.Fatalf("ConnectBlock: %v", err)
+	}
+	if _, ok := (UTXOSet{chain}).FindEntry(child.ID, 0); !ok {
+		t.Fatalf("child output is missing")
+	}
+}
+
+func TestBlockTemplateSkipsCoinbaseCandidates(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	parent, _ := parentAndChild(t, chain, miner)
+
+	// 중계된 코인베이스가 후보에 있어도 템플릿과 채굴한 블록에는 코인베이스가 하나만 있어야 함
+	relayed := CoinbaseTx(string(miner.Address()), "")
+	template := chain.NewBlockTemplate([]*Transaction{relayed, parent})
+	if len(template.Transactions) != 1 || string(template.Transactions[0].ID) != string(parent.ID) {
+		t.Fatalf("template has %d transactions, want only the parent", len(template.Transactions))
+	}
+
+	block := chain.MineBlock(template.BlockTransactions(string(miner.Address())))
+	if len(block.Transactions) != 2 || block.Transactions[1].IsCoinbase() {
+		t.Fatalf("mined block has an extra coinbase")
+	}
+}
@@ -0,0 +1,54 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 임시 디렉터리에 regtest 블록체인을 만들고 코인베이스 출력이 성숙할 때까지 miner에게 블록을 채굴하는 함수
+func newTestChain(t *testing.T, miner *wallet.Wallet) *BlockChain {
+	t.Helper()
+
+	if err := SelectParams(RegTestParams.Name); err != nil {
+		t.Fatal(err)
+	}
+	dataDir := ActiveParams.DataDir
+	ActiveParams.DataDir = t.TempDir()
+	t.Cleanup(func() { ActiveParams.DataDir = dataDir })
+
+	chain := InitBlockChain("test")
+	t.Cleanup(func() { chain.Database.Close() })
+	UTXOSet{chain}.Reindex()
+
+	// 첫 번째 채굴 보상을 사용할 수 있을 때까지 블록 생성
+	if _, err := chain.Generate(ActiveParams.CoinbaseMaturity+1, string(miner.Address())); err != nil {
+		t.Fatal(err)
+	}
+
+	return chain
+}
+
+// 주어진 출력을 사용하는 트랜잭션을 만들고 지갑의 개인 키로 서명하는 함수
+func spendTx(t *testing.T, w *wallet.Wallet, prev *Transaction, vout int, outputs ...TxOutput) *Transaction {
+	t.Helper()
+
+	tx := &Transaction{Version: TxVersion, Inputs: []TxInput{{prev.ID, vout, nil, MaxTxInSequenceNum}}, Outputs: outputs}
+	tx.ID = tx.Hash()
+
+	tx.Sign(w.DeserializePrivateKey(w.PrivateKey), map[string]Transaction{hex.EncodeToString(prev.ID): *prev})
+
+	return tx
+}
+
+// 주어진 높이 블록의 코인베이스 트랜잭션을 찾는 함수
+func coinbaseAt(chain *BlockChain, height int) *Transaction {
+	iter := chain.Iterator()
+	block := iter.Next()
+	for block.Height > height {
+		block = iter.Next()
+	}
+
+	return block.Transactions[0]
+}
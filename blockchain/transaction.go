@@ -1,12 +1,13 @@
 package blockchain
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -17,9 +18,17 @@ import (
 
 // 트랜잭션에 대한 정보
 type Transaction struct {
-	ID      []byte
+	ID []byte
+	// 트랜잭션 형식 버전(버전 2부터 입력의 시퀀스 번호로 상대 잠금 적용)
+	Version int
 	Inputs  []TxInput
 	Outputs []TxOutput
+	// 트랜잭션을 블록에 포함할 수 있는 가장 이른 블록 높이 또는 유닉스 시간(0이면 잠금 없음)
+	LockTime uint32
+
+	// 이전 형식(JSON, 버전 1, 2)으로 만들어진 트랜잭션의 형식 버전(현재 형식이면 0)
+	// 블록 해시와 서명 대상 해시가 원래 형식으로 계산되도록 직렬화할 때 사용
+	encoding byte
 }
 
 // 트랜잭션의 해시값 계산 함수
@@ -39,13 +48,39 @@ func (tx *Transaction) Hash() []byte {
 	return hash[:]
 }
 
+// 내용으로 트랜잭션 ID를 계산하는 함수
+// 서명하기 전에 ID를 정하므로 잠금 해제 스크립트를 뺀 복사본으로 계산하고, 코인베이스는 입력의 데이터까지 포함
+func (tx *Transaction) ComputeID() []byte {
+	if tx.IsCoinbase() {
+		return tx.Hash()
+	}
+
+	txCopy := tx.TrimmedCopy()
+	return txCopy.Hash()
+}
+
+// 트랜잭션 ID가 내용과 일치하는지 확인하는 함수
+func (tx *Transaction) CheckID() error {
+	if !bytes.Equal(tx.ID, tx.ComputeID()) {
+		return fmt.Errorf("%w: %x", ErrInvalidTxID, tx.ID)
+	}
+
+	return nil
+}
+
 // 트랜잭션을 바이스 슬라이스로 직렬화 함수
 func (tx Transaction) Serialize() []byte {
-	data, err := json.Marshal(tx)
-	if err != nil {
-		log.Panic(err)
+	// 이전 형식으로 만들어진 트랜잭션은 원래 형식으로 직렬화
+	if tx.encoding != 0 {
+		return tx.serializeLegacy()
 	}
-	return data
+
+	e := &encoder{}
+	// 형식 버전을 기록한 뒤 트랜잭션을 바이너리로 직렬화
+	e.writeByte(txEncodingVersion)
+	tx.encode(e)
+
+	return e.Bytes()
 }
 
 // 주어진 바이트 배열을 디코딩 하여 Transaction 구조체로 변환하는 함수
@@ -53,9 +88,16 @@ func DeserializeTransaction(data []byte) Transaction {
 	// 디코딩한 결과를 저장할 Transaction 구조체 선언
 	var transaction Transaction
 
-	// JSON 데이터를 Transaction 구조체로 디코딩
-	err := json.Unmarshal(data, &transaction)
-	if err != nil {
+	// 스키마 버전 2 이전의 JSON 형식
+	if len(data) > 0 && data[0] == jsonEncodingVersion {
+		return deserializeJSONTransaction(data)
+	}
+
+	// 바이너리 데이터를 Transaction 구조체로 디코딩
+	d := newDecoder(data)
+	d.readVersion()
+	transaction.decode(d)
+	if err := d.finish(); err != nil {
 		log.Panic(err)
 	}
 
@@ -75,68 +117,187 @@ func CoinbaseTx(to, data string) *Transaction {
 
 	// 트랜잭션의 입력을 설정
 	// 빈 바이트 슬라이스와 -1 값을 가지는 데이터를 사용
-	txin := TxInput{[]byte{}, -1, nil, []byte(data)}
+	// 코인베이스 입력의 잠금 해제 스크립트에는 임의의 데이터를 넣음
+	txin := TxInput{[]byte{}, -1, []byte(data), MaxTxInSequenceNum}
 
-	// 20 단위의 코인을 수신자에게 지급
-	txout := NewTXOutput(20, to)
+	// 네트워크의 채굴 보상만큼 코인을 수신자에게 지급
+	txout := NewTXOutput(ActiveParams.Subsidy, to)
 
 	// 트랜잭션을 생성하고 ID를 설정
-	tx := Transaction{nil, []TxInput{txin}, []TxOutput{*txout}}
+	tx := Transaction{Version: TxVersion, Inputs: []TxInput{txin}, Outputs: []TxOutput{*txout}}
 	tx.ID = tx.Hash()
 
 	// 생성된 트랜잭션 반환
 	return &tx
 }
 
+// 지불 대상 주소와 금액
+type Payment struct {
+	Address string
+	Amount  int
+}
+
 // 새로운 일반 트랜잭션 생성(자금 전송)
-func NewTransaction(w *wallet.Wallet, to string, amount int, UTXO *UTXOSet) *Transaction {
+func NewTransaction(wallets []*wallet.Wallet, selected []UnspentOutput, payments []Payment, selector CoinSelector, rate FeeRate, lockTime uint32, rbf bool, data []byte, UTXO *UTXOSet) *Transaction {
+	tx, prevOutputs := NewUnsignedTransaction(wallets, selected, payments, selector, rate, lockTime, rbf, data, UTXO)
+
+	// 각 입력을 소유한 지갑의 개인 키로 서명
+	keys := make(map[string]*ecdsa.PrivateKey)
+	for _, w := range wallets {
+		keys[hex.EncodeToString(wallet.PublicKeyHash(w.PublicKey))] = w.DeserializePrivateKey(w.PrivateKey)
+	}
+
+	if err := tx.SignWithKeys(keys, prevOutputs); err != nil {
+		log.Panic(err)
+	}
+
+	return tx
+}
+
+// 서명되지 않은 트랜잭션과 각 입력이 소비하는 이전 출력을 생성하는 함수
+// 개인 키가 필요 없으므로 잠긴 지갑으로도 생성 가능
+// 여러 지갑의 UTXO를 사용할 수 있으며, selected가 비어 있으면 selector로 지갑들의 UTXO에서 선택
+// 지불 대상마다 출력을 하나씩 만들고, 입력 합계에서 지불 금액과 잔돈을 뺀 나머지가 수수료가 됨
+// 잔돈은 첫 번째 입력의 소유자에게 반환
+// lockTime이 0이 아니면 해당 블록 높이 또는 유닉스 시간 이후에만 블록에 포함될 수 있음
+// rbf이면 블록에 포함되기 전에 수수료를 높인 트랜잭션으로 교체할 수 있음을 표시
+// data가 있으면 데이터를 기록하는 출력을 마지막 출력으로 추가
+func NewUnsignedTransaction(wallets []*wallet.Wallet, selected []UnspentOutput, payments []Payment, selector CoinSelector, rate FeeRate, lockTime uint32, rbf bool, data []byte, UTXO *UTXOSet) (*Transaction, []TxOutput) {
 	var inputs []TxInput   // 입력값을 저장할 수 있는 슬라이스 선언
 	var outputs []TxOutput // 출력값을 저장할 수 있는 슬라이스 선언
 
-	pubKeyHash := wallet.PublicKeyHash(w.PublicKey)
+	// 출력값을 생성하여 수신자에게 보내는 슬라이스를 추가
+	amount := 0
+	for _, payment := range payments {
+		outputs = append(outputs, *NewTXOutput(payment.Amount, payment.Address))
+		amount += payment.Amount
+	}
+
+	// 잔돈을 제외한 출력(수수료 계산에 사용)
+	feeOutputs := append([]TxOutput{}, outputs...)
+	var dataOutput *TxOutput
+	if data != nil {
+		var err error
+		if dataOutput, err = NewDataOutput(data); err != nil {
+			log.Panic("Error: ", err)
+		}
+		feeOutputs = append(feeOutputs, *dataOutput)
+	}
+
+	// 공개 키 해시로 지갑을 찾을 수 있도록 맵 생성
+	owners := make(map[string]*wallet.Wallet)
+	var pubKeyHashes [][]byte
+	for _, w := range wallets {
+		pubKeyHash := wallet.PublicKeyHash(w.PublicKey)
+		owners[hex.EncodeToString(pubKeyHash)] = w
+		pubKeyHashes = append(pubKeyHashes, pubKeyHash)
+	}
 
-	// 지출 가능한 출력값 찾음
-	// 총 잔액과 사용할 수 있는 출력 반환
-	acc, validOutputs := UTXO.FindSpendableOutputs(pubKeyHash, amount)
+	// 지갑들의 사용되지 않은 출력값 찾음
+	unspent := UTXO.FindUnspentOutputs(pubKeyHashes)
+	// 다음 블록에서 사용할 수 없는 성숙하지 않은 코인베이스 출력은 선택하지 않음
+	height := UTXO.Blockchain.GetBestHeight() + 1
+
+	var selection CoinSelection
+	var err error
+	if len(selected) > 0 {
+		// 직접 선택한 출력값이 지갑의 UTXO인지 확인
+		available := make(map[string]UnspentOutput)
+		for _, utxo := range unspent {
+			available[utxo.Outpoint()] = utxo
+		}
+
+		var spend []UnspentOutput
+		for _, utxo := range selected {
+			found, ok := available[utxo.Outpoint()]
+			if !ok {
+				log.Panicf("Error: %s is not an unspent output of the wallet", utxo.Outpoint())
+			}
+			if !found.IsMature(height) {
+				log.Panicf("Error: %s is an immature coinbase output, spendable at block %d", utxo.Outpoint(), found.Height+ActiveParams.CoinbaseMaturity)
+			}
+			delete(available, utxo.Outpoint())
+			spend = append(spend, found)
+		}
+		selection, err = NewCoinSelection(spend, amount, feeOutputs, rate)
+	} else {
+		selection, err = selector.Select(matureOutputs(unspent, height), amount, feeOutputs, rate)
+	}
 
 	// 잔액이 충분하지 않으면 프로그램 중단
-	if acc < amount {
-		log.Panic("Error: not enough funds")
+	if err != nil {
+		log.Panic("Error: ", err)
 	}
+	spend := selection.Inputs
 
-	// 유효한 출력값을 반복하여 입력값 생성
-	for txid, outs := range validOutputs {
-		// 트랜잭션 ID를 디코딩하여 바이트 슬라이스로 변환
-		txID, err := hex.DecodeString(txid)
-		Handle(err)
+	// LockTime은 최종 시퀀스 번호가 아닌 입력이 있어야 적용됨
+	sequence := MaxTxInSequenceNum
+	if lockTime != 0 {
+		sequence = MaxTxInSequenceNum - 1
+	}
+	// 교체 가능함을 표시하는 시퀀스 번호도 최종이 아니므로 LockTime이 함께 적용됨
+	if rbf {
+		sequence = MaxRBFSequence
+	}
 
-		// 각 출력값에 대한 입력값을 생성하여 슬라이스에 추가
-		for _, out := range outs {
-			input := TxInput{txID, out, nil, w.PublicKey}
-			inputs = append(inputs, input)
-		}
+	// 선택된 출력값으로 잠금 해제 스크립트가 비어 있는 입력값 생성
+	for _, utxo := range spend {
+		inputs = append(inputs, TxInput{utxo.TxID, utxo.Vout, nil, sequence})
 	}
 
-	from := fmt.Sprintf("%s", w.Address())
+	from := fmt.Sprintf("%s", owners[hex.EncodeToString(spend[0].Output.PubKeyHash())].Address())
 
-	// 출력값을 생성하여 수신자에게 보내는 슬라이스를 추가
-	outputs = append(outputs, *NewTXOutput(amount, to))
+	// 잔돈이 있는 경우, 나머지 잔액을 송신자에게 반환하는 출력값 생성
+	if selection.Change > 0 {
+		outputs = append(outputs, *NewTXOutput(selection.Change, from))
+	}
 
-	// 잔액이 소비된 경우, 나머지 잔액을 송신자에게 반환하는 출력값 생성
-	if acc > amount {
-		outputs = append(outputs, *NewTXOutput(acc-amount, from))
+	// 데이터 출력은 UTXO 집합에 들어가지 않으므로 다른 출력의 번호가 바뀌지 않도록 마지막에 추가
+	if dataOutput != nil {
+		outputs = append(outputs, *dataOutput)
 	}
 
 	// 새로운 트랜잭션을 생성하고 ID를 설정
-	tx := Transaction{nil, inputs, outputs}
+	tx := Transaction{Version: TxVersion, Inputs: inputs, Outputs: outputs, LockTime: lockTime}
 
 	tx.ID = tx.Hash()
 
-	privateKey := w.DeserializePrivateKey(w.PrivateKey)
+	return &tx, UTXO.Blockchain.FindPrevOutputs(&tx)
+}
 
-	UTXO.Blockchain.SignTransaction(&tx, privateKey)
+// NewUnsignedTransaction으로 만든 트랜잭션에서 잔돈 출력의 인덱스를 반환하는 함수(잔돈이 없으면 -1)
+// 잔돈 출력은 payments개의 지불 출력 바로 다음에, 데이터 출력보다 앞에 추가됨
+func ChangeOutputIndex(tx *Transaction, payments int) int {
+	if payments < len(tx.Outputs) && !tx.Outputs[payments].IsUnspendable() {
+		return payments
+	}
 
-	return &tx
+	return -1
+}
+
+// 트랜잭션 출력의 형식을 확인하는 함수
+// 데이터 출력은 크기 제한 이내의 데이터 하나만 기록하고 금액이 0이어야 하며, 트랜잭션마다 하나만 허용
+func (tx *Transaction) CheckOutputs() error {
+	dataOutputs := 0
+	for i, out := range tx.Outputs {
+		if !out.IsUnspendable() {
+			continue
+		}
+
+		if ClassifyScript(out.ScriptPubKey) != NullDataScript {
+			return fmt.Errorf("output %d: data output is malformed or larger than %d bytes", i, MaxDataCarrierSize)
+		}
+		if out.Value != 0 {
+			return fmt.Errorf("output %d: data output carries value %d", i, out.Value)
+		}
+		dataOutputs++
+	}
+
+	if dataOutputs > 1 {
+		return fmt.Errorf("transaction has %d data outputs, at most 1 is allowed", dataOutputs)
+	}
+
+	return nil
 }
 
 // 트랜잭션이 코인베이스인지 여부 확인
@@ -151,39 +312,94 @@ func (tx *Transaction) Sign(privKey *ecdsa.PrivateKey, prevTXs map[string]Transa
 		return
 	}
 
+	tx.sign(func([]byte) *ecdsa.PrivateKey { return privKey }, tx.prevOutputs(prevTXs))
+}
+
+// 입력마다 이전 출력을 잠근 공개 키 해시에 해당하는 개인 키로 서명하는 함수
+// keys는 16진수 공개 키 해시를 키로 사용하고, prevOutputs는 각 입력이 소비하는 이전 출력
+func (tx *Transaction) SignWithKeys(keys map[string]*ecdsa.PrivateKey, prevOutputs []TxOutput) error {
+	if len(prevOutputs) != len(tx.Inputs) {
+		return errors.New("previous outputs do not match the inputs")
+	}
+
+	// 모든 입력의 개인 키가 있는지 먼저 확인
+	for inId := range tx.Inputs {
+		pubKeyHash := prevOutputs[inId].PubKeyHash()
+		if pubKeyHash == nil {
+			return fmt.Errorf("input %d does not spend a pay-to-pubkey-hash output", inId)
+		}
+		if _, ok := keys[hex.EncodeToString(pubKeyHash)]; !ok {
+			return fmt.Errorf("no private key for input %d", inId)
+		}
+	}
+
+	tx.sign(func(pubKeyHash []byte) *ecdsa.PrivateKey {
+		return keys[hex.EncodeToString(pubKeyHash)]
+	}, prevOutputs)
+
+	return nil
+}
+
+// 각 입력이 소비하는 이전 출력을 이전 트랜잭션에서 찾는 함수
+func (tx *Transaction) prevOutputs(prevTXs map[string]Transaction) []TxOutput {
+	var prevOutputs []TxOutput
+
 	// 트랜잭션의 각 입력값에 대해 이전 트랜잭션 확인
 	for _, in := range tx.Inputs {
+		prevTX := prevTXs[hex.EncodeToString(in.ID)]
 		// 이전 트랜잭션을 찾지 못하면 에러 발생
-		if prevTXs[hex.EncodeToString(in.ID)].ID == nil {
+		if prevTX.ID == nil || in.Out < 0 || in.Out >= len(prevTX.Outputs) {
 			log.Panic("ERROR: Previous transaction is not correct")
 		}
+		prevOutputs = append(prevOutputs, prevTX.Outputs[in.Out])
 	}
 
+	return prevOutputs
+}
+
+// 이전 출력의 공개 키 해시로 서명에 사용할 개인 키를 찾아 P2PKH 잠금 해제 스크립트를 만드는 함수
+func (tx *Transaction) sign(keyFor func(pubKeyHash []byte) *ecdsa.PrivateKey, prevOutputs []TxOutput) {
 	// 트랜잭션의 복사본 생성
 	txCopy := tx.TrimmedCopy()
 
 	// 트랜잭션의 각 입력값에 대해 서명 생성
-	for inId, in := range txCopy.Inputs {
-		// 이전 트랜잭션 가져옴
-		prevTX := prevTXs[hex.EncodeToString(in.ID)]
-		// 서명 및 공개키 초기화
-		txCopy.Inputs[inId].Signature = nil
-		txCopy.Inputs[inId].PubKey = prevTX.Outputs[in.Out].PubKeyHash
-		// 트랜잭션의 ID 업데이트
-		txCopy.ID = txCopy.Hash()
-		txCopy.Inputs[inId].PubKey = nil
+	for inId := range txCopy.Inputs {
+		// 서명할 데이터 해시
+		hash := txCopy.signatureHash(inId, prevOutputs[inId])
 
 		// 개인 키를 사용하여 서명 생성
-		r, s, err := ecdsa.Sign(rand.Reader, privKey, txCopy.ID)
-		Handle(err)
-		signature := append(r.Bytes(), s.Bytes()...)
-
-		// 생성된 서명을 트랜잭션의 입력값에 추가
-		tx.Inputs[inId].Signature = signature
+		privKey := keyFor(prevOutputs[inId].PubKeyHash())
 
+		// 서명과 공개 키로 잠금 해제 스크립트 생성
+		tx.Inputs[inId].ScriptSig = PayToPubKeyHashUnlockScript(signHash(privKey, hash), serializePubKey(privKey))
 	}
 }
 
+// 공개 키를 X, Y 각각 32바이트로 이어 붙인 바이트 슬라이스로 변환하는 함수
+func serializePubKey(privKey *ecdsa.PrivateKey) []byte {
+	return append(privKey.X.FillBytes(make([]byte, 32)), privKey.Y.FillBytes(make([]byte, 32))...)
+}
+
+// 해시에 서명하여 r과 s를 32바이트씩 이어 붙인 서명을 반환하는 함수
+// 검증 시 서명을 절반으로 나누므로 길이를 고정
+func signHash(privKey *ecdsa.PrivateKey, hash []byte) []byte {
+	r, s, err := ecdsa.Sign(rand.Reader, privKey, hash)
+	Handle(err)
+
+	return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+}
+
+// 입력의 서명 대상 해시를 계산하는 함수(txCopy는 TrimmedCopy로 만든 복사본)
+// 서명하는 입력의 잠금 해제 스크립트 자리에 이전 출력의 잠금 스크립트를 넣고 해시
+func (txCopy *Transaction) signatureHash(inId int, prevOutput TxOutput) []byte {
+	txCopy.Inputs[inId].ScriptSig = prevOutput.ScriptPubKey
+	// 트랜잭션의 ID 업데이트
+	txCopy.ID = txCopy.Hash()
+	txCopy.Inputs[inId].ScriptSig = nil
+
+	return txCopy.ID
+}
+
 // 트랜잭션 유효성 검증 함수
 func (tx *Transaction) Verify(prevTXs map[string]Transaction) bool {
 	// 코인베이스 트랜잭션이면 항상 유효
@@ -191,61 +407,77 @@ func (tx *Transaction) Verify(prevTXs map[string]Transaction) bool {
 		return true
 	}
 
-	// 트랜잭션의 각 입력값에 대해 이전 트랜잭션 확인
-	for _, in := range tx.Inputs {
-		// 이전 트랜잭션을 찾지 못하면 에러 발생
-		if prevTXs[hex.EncodeToString(in.ID)].ID == nil {
-			log.Panic("Previous transaction not correct")
-		}
+	return tx.VerifyWithPrevOutputs(tx.prevOutputs(prevTXs))
+}
+
+// 각 입력이 소비하는 이전 출력으로 서명을 검증하는 함수
+func (tx *Transaction) VerifyWithPrevOutputs(prevOutputs []TxOutput) bool {
+	if err := tx.verifyScripts(prevOutputs); err != nil {
+		fmt.Println("false")
+		return false
+	}
+	fmt.Println("true")
+	// 모든 입력값의 스크립트가 유효하면 true 반환
+	return true
+}
+
+// 각 입력의 잠금 해제 스크립트로 이전 출력의 잠금 스크립트를 실행하여 검증하는 함수
+func (tx *Transaction) verifyScripts(prevOutputs []TxOutput) error {
+	if len(prevOutputs) != len(tx.Inputs) {
+		return errors.New("previous outputs do not match the inputs")
 	}
 
 	// 트랜잭션의 복사본 생성
 	txCopy := tx.TrimmedCopy()
-	// 타원 곡선(p256) 생성
-	curve := elliptic.P256()
 
-	// 트랜잭션의 각 입력값에 대해 서명을 확인
+	// 트랜잭션의 각 입력값에 대해 스크립트 실행
 	for inId, in := range tx.Inputs {
+		// 서명된 데이터 해시
+		hash := txCopy.signatureHash(inId, prevOutputs[inId])
 
-		// 이전 트랜잭션 가져옴
-		prevTx := prevTXs[hex.EncodeToString(in.ID)]
-		// 서명 및 공개키를 초기화
-		txCopy.Inputs[inId].Signature = nil
-		txCopy.Inputs[inId].PubKey = prevTx.Outputs[in.Out].PubKeyHash
-		// 트랜잭션의 ID를 업데이트
-		txCopy.ID = txCopy.Hash()
-		txCopy.Inputs[inId].PubKey = nil
-
-		// 서명과 공개키 추출
-		r := big.Int{}
-		s := big.Int{}
-
-		sigLen := len(in.Signature)
-		r.SetBytes(in.Signature[:(sigLen / 2)])
-		s.SetBytes(in.Signature[(sigLen / 2):])
-
-		x := big.Int{}
-		y := big.Int{}
-
-		keyLen := len(in.PubKey)
-		x.SetBytes(in.PubKey[:(keyLen / 2)])
-		y.SetBytes(in.PubKey[(keyLen / 2):])
-
-		// 타원 곡선 공개키 생성
-		rawPubKey := ecdsa.PublicKey{Curve: curve, X: &x, Y: &y}
-
-		// 서명의 유효성 검증
-		if !ecdsa.Verify(&rawPubKey, txCopy.ID, &r, &s) {
-			fmt.Println("false")
-			return false
+		checkSig := func(sig, pubKey []byte) bool {
+			return verifySignature(hash, sig, pubKey)
+		}
+		if err := VerifyScript(in.ScriptSig, prevOutputs[inId].ScriptPubKey, tx, inId, checkSig); err != nil {
+			return fmt.Errorf("input %d: %w", inId, err)
 		}
 	}
-	fmt.Println("true")
-	// 모든 입력값의 서명이 유효하면 true 반환
-	return true
+
+	return nil
 }
 
-// 트랜잭션의 복사본을 생성, 입력값의 서명과 공개키 제거 함수
+// 해시에 대한 서명을 공개 키로 검증하는 함수
+// 서명은 r, s를, 공개 키는 X, Y를 절반씩 이어 붙인 형식
+func verifySignature(hash, sig, pubKey []byte) bool {
+	// 서명과 공개키 추출
+	r := big.Int{}
+	s := big.Int{}
+
+	sigLen := len(sig)
+	r.SetBytes(sig[:(sigLen / 2)])
+	s.SetBytes(sig[(sigLen / 2):])
+
+	x := big.Int{}
+	y := big.Int{}
+
+	keyLen := len(pubKey)
+	x.SetBytes(pubKey[:(keyLen / 2)])
+	y.SetBytes(pubKey[(keyLen / 2):])
+
+	// 곡선 위의 점이 아닌 공개 키는 유효하지 않음
+	curve := elliptic.P256()
+	if !curve.IsOnCurve(&x, &y) {
+		return false
+	}
+
+	// 타원 곡선 공개키 생성
+	rawPubKey := ecdsa.PublicKey{Curve: curve, X: &x, Y: &y}
+
+	// 서명의 유효성 검증
+	return ecdsa.Verify(&rawPubKey, hash, &r, &s)
+}
+
+// 트랜잭션의 복사본을 생성, 입력값의 잠금 해제 스크립트 제거 함수
 func (tx *Transaction) TrimmedCopy() Transaction {
 	// 입력값과 출력값을 저장할 빈 슬라이스 초기화
 	var inputs []TxInput
@@ -253,17 +485,17 @@ func (tx *Transaction) TrimmedCopy() Transaction {
 
 	// 원본 트랜잭션의 각 입력값에 대해 새로운 TxInput을 생성하여 inputs 슬라이스에 추가
 	for _, in := range tx.Inputs {
-		// 서명과 공개키는 nil로 초기화
-		inputs = append(inputs, TxInput{in.ID, in.Out, nil, nil})
+		// 잠금 해제 스크립트는 nil로 초기화
+		inputs = append(inputs, TxInput{in.ID, in.Out, nil, in.Sequence})
 	}
 
 	// 원본 트랜잭션의 각 출력값에 대해 새로운 TxOutput을 생성하여 outputs 슬라이스에 추가
 	for _, out := range tx.Outputs {
-		outputs = append(outputs, TxOutput{out.Value, out.PubKeyHash})
+		outputs = append(outputs, TxOutput{out.Value, out.ScriptPubKey})
 	}
 
 	// 입력값과 출력값을 가지고 있는 새로운 트랜잭션을 생성
-	txCopy := Transaction{tx.ID, inputs, outputs}
+	txCopy := Transaction{tx.ID, tx.Version, inputs, outputs, tx.LockTime, tx.encoding}
 
 	// 새로운 트랜잭션의 복사본 반환
 	return txCopy
@@ -275,21 +507,35 @@ func (tx Transaction) String() string {
 	var lines []string
 	// 트랜잭션의 ID를 포함한 문자열을 추가
 	lines = append(lines, fmt.Sprintf("--- Transaction %x:", tx.ID))
+	lines = append(lines, fmt.Sprintf("     Version:  %d", tx.Version))
+	if tx.LockTime != 0 {
+		lines = append(lines, fmt.Sprintf("     LockTime: %d", tx.LockTime))
+	}
 
 	// 각 입력값에 대한 정보를 문자열에 추가
 	for i, input := range tx.Inputs {
 		lines = append(lines, fmt.Sprintf("     Input %d:", i))
 		lines = append(lines, fmt.Sprintf("       TXID:     %x", input.ID))
 		lines = append(lines, fmt.Sprintf("       Out:       %d", input.Out))
-		lines = append(lines, fmt.Sprintf("       Signature: %x", input.Signature))
-		lines = append(lines, fmt.Sprintf("       PubKey:    %x", input.PubKey))
+		if input.Sequence != MaxTxInSequenceNum {
+			lines = append(lines, fmt.Sprintf("       Sequence:  %#x", input.Sequence))
+		}
+		// 코인베이스 입력의 잠금 해제 스크립트는 임의의 데이터
+		if tx.IsCoinbase() {
+			lines = append(lines, fmt.Sprintf("       Coinbase:  %x", input.ScriptSig))
+		} else {
+			lines = append(lines, fmt.Sprintf("       Script:    %s", DisassembleScript(input.ScriptSig)))
+		}
 	}
 
 	// 각 출력값에 대한 정보를 문자열에 추가
 	for i, output := range tx.Outputs {
 		lines = append(lines, fmt.Sprintf("     Output %d:", i))
 		lines = append(lines, fmt.Sprintf("       Value:  %d", output.Value))
-		lines = append(lines, fmt.Sprintf("       Script: %x", output.PubKeyHash))
+		lines = append(lines, fmt.Sprintf("       Script: %s", DisassembleScript(output.ScriptPubKey)))
+		if data := ExtractNullData(output.ScriptPubKey); data != nil {
+			lines = append(lines, fmt.Sprintf("       Data:   %x", data))
+		}
 	}
 
 	// 모든 정보를 개행 문자로 구분하여 하나의 문자열로 결합
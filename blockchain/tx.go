@@ -2,7 +2,6 @@ package blockchain
 
 import (
 	"bytes"
-	"encoding/json"
 	"log"
 
 	"github.com/Kim-DaeHan/go-blockchain/wallet"
@@ -10,29 +9,41 @@ import (
 
 // 트랜잭션의 출력에 대한 정보
 type TxOutput struct {
-	Value      int
-	PubKeyHash []byte
+	Value int
+	// 출력을 사용하기 위해 만족해야 하는 잠금 스크립트
+	ScriptPubKey []byte
 }
 
+// 트랜잭션별로 사용되지 않은 출력 목록을 저장하던 이전 UTXO 항목(마이그레이션에서 사용)
+// 사용된 출력을 빼고 다시 저장하면 남은 출력의 인덱스가 바뀌므로 버전 5부터 출력마다 저장
 type TxOutputs struct {
 	Outputs []TxOutput
+	// 출력을 만든 트랜잭션이 포함된 블록 높이
+	Height int
+	// 출력을 만든 트랜잭션이 코인베이스인지 여부
+	Coinbase bool
 }
 
 // 트랜잭션 입력에 대한 정보
 type TxInput struct {
-	ID        []byte
-	Out       int
-	Signature []byte
-	PubKey    []byte
+	ID  []byte
+	Out int
+	// 이전 출력의 잠금 스크립트를 만족시키는 잠금 해제 스크립트(코인베이스는 임의의 데이터)
+	ScriptSig []byte
+	// 시퀀스 번호(MaxTxInSequenceNum이 아니면 LockTime이 적용되고, 버전 2 이상의 트랜잭션에서는 상대 잠금을 나타냄)
+	Sequence uint32
 }
 
 // 주어진 공개 키 해시가 현재 트랜잭션 입력에 사용된 키와 일치하는지 검증하는 함수
 func (in *TxInput) UsesKey(pubKeyHash []byte) bool {
-	// 입력의 공개 키 해시
-	lockingHash := wallet.PublicKeyHash(in.PubKey)
+	// 잠금 해제 스크립트에 포함된 공개 키
+	pubKey := extractUnlockPubKey(in.ScriptSig)
+	if pubKey == nil {
+		return false
+	}
 
 	// 주어진 공개 키 해시와 입력의 공개 키 해시 비교하여 일치 여부
-	return bytes.Equal(lockingHash, pubKeyHash)
+	return bytes.Equal(wallet.PublicKeyHash(pubKey), pubKeyHash)
 }
 
 // 주어진 주소에 해당하는 키를 사용하여 출력을 잠금
@@ -41,14 +52,42 @@ func (out *TxOutput) Lock(address []byte) {
 	pubKeyHash := wallet.Base58Decode(address)
 	// 첫 번째 문자는 버전 정보이므로 제거하고, 마지막 4바이트는 체크섬이므로 제거
 	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-4]
-	// 출력의 공개 키 해시를 설정
-	out.PubKeyHash = pubKeyHash
+
+	// 스크립트 해시 주소는 스크립트 해시로 잠금
+	if wallet.IsScriptHashAddress(string(address)) {
+		out.ScriptPubKey = PayToScriptHashScript(pubKeyHash)
+		return
+	}
+
+	// 공개 키 해시로 잠그는 표준 스크립트 설정
+	out.ScriptPubKey = PayToPubKeyHashScript(pubKeyHash)
+}
+
+// 출력을 잠근 공개 키 해시를 반환(P2PKH가 아닌 출력은 nil)
+func (out *TxOutput) PubKeyHash() []byte {
+	return ExtractPubKeyHash(out.ScriptPubKey)
+}
+
+// 출력을 잠근 스크립트 해시를 반환(P2SH가 아닌 출력은 nil)
+func (out *TxOutput) ScriptHash() []byte {
+	return ExtractScriptHash(out.ScriptPubKey)
+}
+
+// 출력 주소의 해시(P2PKH는 공개 키 해시, P2SH는 스크립트 해시)를 반환
+// 주소로 나타낼 수 없는 출력은 nil
+func (out *TxOutput) AddressHash() []byte {
+	if pubKeyHash := out.PubKeyHash(); pubKeyHash != nil {
+		return pubKeyHash
+	}
+
+	return out.ScriptHash()
 }
 
 // 현재 트랜잭션 출력이 주어진 공개 키 해시로 잠겨 있는지 여부 확인
 func (out *TxOutput) IsLockedWithKey(pubKeyHash []byte) bool {
-	// 현재 트랜잭션 출력의 공개 키 해시와 주어진 공개 키 해시를 비교하여 일치 여부 반환
-	return bytes.Equal(out.PubKeyHash, pubKeyHash)
+	// 잠금 스크립트의 공개 키 해시와 주어진 공개 키 해시를 비교하여 일치 여부 반환
+	lockingHash := out.PubKeyHash()
+	return lockingHash != nil && bytes.Equal(lockingHash, pubKeyHash)
 }
 
 // 새로운 트랜잭션 출력을 생성
@@ -61,17 +100,39 @@ func NewTXOutput(value int, address string) *TxOutput {
 	return txo
 }
 
+// 데이터를 기록하는 금액이 0인 출력을 생성
+func NewDataOutput(data []byte) (*TxOutput, error) {
+	script, err := NewNullDataScript(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TxOutput{0, script}, nil
+}
+
+// OP_RETURN으로 시작하여 어떤 잠금 해제 스크립트로도 사용할 수 없는 출력인지 여부
+// 사용할 수 없는 출력은 UTXO 집합에 넣지 않음
+func (out *TxOutput) IsUnspendable() bool {
+	return len(out.ScriptPubKey) > 0 && out.ScriptPubKey[0] == OpReturn
+}
+
 // TxOutputs 구조체를 직렬화하여 바이트 슬라이스로 반환
 func (outs TxOutputs) Serialize() []byte {
-	// TxOutputs 구조체를 JSON으로 직렬화
-	data, err := json.Marshal(outs)
-
-	// 직렬화 중 에러가 발생하면 패닉
-	if err != nil {
-		log.Panic(err)
+	e := &encoder{}
+	// 형식 버전과 출력 개수를 기록한 뒤 각 출력을 바이너리로 직렬화
+	e.writeByte(utxoHeightEncodingVersion)
+	e.writeUvarint(uint64(len(outs.Outputs)))
+	for i := range outs.Outputs {
+		outs.Outputs[i].encode(e)
+	}
+	e.writeVarint(int64(outs.Height))
+	if outs.Coinbase {
+		e.writeByte(1)
+	} else {
+		e.writeByte(0)
 	}
 
-	return data
+	return e.Bytes()
 }
 
 // 주어진 바이트 슬라이스를 Txoutputs 구조체로 역직렬화
@@ -79,11 +140,21 @@ func DeserializeOutputs(data []byte) TxOutputs {
 	// 역직렬화된 데이터를 담을 변수 선언
 	var outputs TxOutputs
 
-	// JSON 바이트 슬라이스를 TxOutputs 구조체로 변환
-	err := json.Unmarshal(data, &outputs)
+	// 바이너리 데이터를 TxOutputs 구조체로 변환
+	d := newDecoder(data)
+	d.readVersion()
+	outputs.Outputs = make([]TxOutput, d.readCount())
+	for i := range outputs.Outputs {
+		outputs.Outputs[i].decode(d)
+	}
+	// 버전 4 이전의 항목은 높이와 코인베이스 여부가 없음(마이그레이션에서 UTXO 집합을 다시 생성)
+	if d.version >= utxoHeightEncodingVersion {
+		outputs.Height = d.readInt()
+		outputs.Coinbase = d.readByte() != 0
+	}
 
 	// 역직렬화 중 에러가 발생하면 패닉
-	if err != nil {
+	if err := d.finish(); err != nil {
 		log.Panic(err)
 	}
 
@@ -2,8 +2,12 @@ package blockchain
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
 
 	"github.com/dgraph-io/badger"
 )
@@ -11,6 +15,8 @@ import (
 var (
 	// UTXO 키의 접두사
 	utxoPrefix = []byte("utxo-")
+	// 주소 해시별 UTXO 색인 키의 접두사
+	addressIndexPrefix = []byte("addr-")
 	// utxoPrefix의 길이
 	// prefixLength = len(utxoPrefix)
 )
@@ -20,95 +26,218 @@ type UTXOSet struct {
 	Blockchain *BlockChain
 }
 
-// 주어진 공개키 해시와 금액에 대해 지출 가능한 UTXO를 찾음
-func (u UTXOSet) FindSpendableOutputs(pubKeyHash []byte, amount int) (int, map[string][]int) {
-	// 지출 가능한 UTXO 저장할 맵
-	unspentOuts := make(map[string][]int)
-	// 누적된 금액
-	accumulated := 0
-	// DB 참조
-	db := u.Blockchain.Database
+// 지갑에서 사용할 수 있는 UTXO 정보
+type UnspentOutput struct {
+	TxID   []byte
+	Vout   int
+	Output TxOutput
+	// 출력을 만든 트랜잭션이 포함된 블록 높이
+	Height int
+	// 코인베이스 트랜잭션의 출력인지 여부
+	Coinbase bool
+}
 
-	// 데이터베이스를 읽기 모드로
-	err := db.View(func(txn *badger.Txn) error {
-		// 기본 Iterator 옵션 설정
-		opts := badger.DefaultIteratorOptions
+// "트랜잭션ID:출력 인덱스" 형식의 문자열을 반환
+func (utxo UnspentOutput) Outpoint() string {
+	return fmt.Sprintf("%x:%d", utxo.TxID, utxo.Vout)
+}
 
-		// Iterator 생성
+// 주어진 높이의 블록에서 사용할 수 있는지 확인(코인베이스 출력은 성숙 깊이 이후에만 사용 가능)
+func (utxo UnspentOutput) IsMature(height int) bool {
+	return !utxo.Coinbase || height-utxo.Height >= ActiveParams.CoinbaseMaturity
+}
+
+// "트랜잭션ID:출력 인덱스" 형식의 문자열을 파싱하는 함수
+func ParseOutpoint(outpoint string) (UnspentOutput, error) {
+	parts := strings.Split(outpoint, ":")
+	if len(parts) != 2 {
+		return UnspentOutput{}, fmt.Errorf("invalid outpoint %q", outpoint)
+	}
+
+	txID, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return UnspentOutput{}, fmt.Errorf("invalid outpoint %q: %w", outpoint, err)
+	}
+	vout, err := strconv.Atoi(parts[1])
+	if err != nil || vout < 0 {
+		return UnspentOutput{}, fmt.Errorf("invalid outpoint %q", outpoint)
+	}
+
+	return UnspentOutput{TxID: txID, Vout: vout}, nil
+}
+
+// UTXO 항목의 키 생성(utxoPrefix + 트랜잭션 ID + 4바이트 출력 인덱스)
+// 출력마다 키가 있으므로 일부 출력이 사용되어도 남은 출력의 인덱스가 바뀌지 않음
+func utxoKey(txID []byte, vout int) []byte {
+	key := make([]byte, len(utxoPrefix)+len(txID)+4)
+	copy(key, utxoPrefix)
+	copy(key[len(utxoPrefix):], txID)
+	binary.BigEndian.PutUint32(key[len(utxoPrefix)+len(txID):], uint32(vout))
+
+	return key
+}
+
+// UTXO 항목의 키에서 트랜잭션 ID와 출력 인덱스를 추출하는 함수
+func parseUTXOKey(key []byte) ([]byte, int) {
+	outpoint := bytes.TrimPrefix(key, utxoPrefix)
+	split := len(outpoint) - 4
+
+	return outpoint[:split], int(binary.BigEndian.Uint32(outpoint[split:]))
+}
+
+// 주소 해시로 잠긴 UTXO 색인 키의 공통 접두사(addressIndexPrefix + 주소 해시 길이 + 주소 해시)
+func addressIndexKeyPrefix(addressHash []byte) []byte {
+	prefix := make([]byte, 0, len(addressIndexPrefix)+1+len(addressHash))
+	prefix = append(prefix, addressIndexPrefix...)
+	prefix = append(prefix, byte(len(addressHash)))
+
+	return append(prefix, addressHash...)
+}
+
+// UTXO의 주소 색인 키 생성(주소 해시 접두사 + 트랜잭션 ID + 4바이트 출력 인덱스)
+// 같은 주소로 잠긴 UTXO의 색인 키가 이어지므로 전체 UTXO 집합을 순회하지 않고 찾을 수 있음
+// 공개 키 해시나 스크립트 해시로 잠기지 않은 출력은 색인하지 않으므로 nil 반환
+func (utxo UnspentOutput) addressIndexKey() []byte {
+	addressHash := utxo.Output.AddressHash()
+	if addressHash == nil {
+		return nil
+	}
+
+	return append(addressIndexKeyPrefix(addressHash), utxoKey(utxo.TxID, utxo.Vout)[len(utxoPrefix):]...)
+}
+
+// UTXO 항목의 값(출력, 블록 높이, 코인베이스 여부)을 직렬화
+func (utxo UnspentOutput) serializeEntry() []byte {
+	e := &encoder{}
+	e.writeByte(encodingVersion)
+	utxo.Output.encode(e)
+	e.writeVarint(int64(utxo.Height))
+	if utxo.Coinbase {
+		e.writeByte(1)
+	} else {
+		e.writeByte(0)
+	}
+
+	return e.Bytes()
+}
+
+// UTXO 항목의 키와 값을 UnspentOutput으로 역직렬화
+func deserializeUTXOEntry(key, value []byte) UnspentOutput {
+	var utxo UnspentOutput
+	utxo.TxID, utxo.Vout = parseUTXOKey(key)
+
+	d := newDecoder(value)
+	d.readVersion()
+	utxo.Output.decode(d)
+	utxo.Height = d.readInt()
+	utxo.Coinbase = d.readByte() != 0
+
+	// 역직렬화 중 에러가 발생하면 패닉
+	if err := d.finish(); err != nil {
+		log.Panic(err)
+	}
+
+	return utxo
+}
+
+// 주어진 트랜잭션 ID와 출력 인덱스의 UTXO를 찾음
+func (u UTXOSet) FindOutput(txID []byte, vout int) (TxOutput, bool) {
+	utxo, found := u.FindEntry(txID, vout)
+
+	return utxo.Output, found
+}
+
+// 주어진 트랜잭션 ID와 출력 인덱스의 UTXO 항목을 블록 높이, 코인베이스 여부와 함께 찾음
+func (u UTXOSet) FindEntry(txID []byte, vout int) (UnspentOutput, bool) {
+	var utxo UnspentOutput
+	found := false
+
+	err := u.Blockchain.Database.View(func(txn *badger.Txn) error {
+		key := utxoKey(txID, vout)
+		item, err := txn.Get(key)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		v, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		utxo = deserializeUTXOEntry(key, v)
+		found = true
+
+		return nil
+	})
+	Handle(err)
+
+	return utxo, found
+}
+
+// 주어진 트랜잭션 ID의 출력이 UTXO 집합에 남아 있는지 확인
+func (u UTXOSet) HasTransaction(txID []byte) bool {
+	prefix := append(append([]byte{}, utxoPrefix...), txID...)
+	found := false
+
+	err := u.Blockchain.Database.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
 		it := txn.NewIterator(opts)
-		// 함수 종료 시 Iterator 닫음
 		defer it.Close()
 
-		// utxoPrefix로 시작하는 UTXO를 반복(Seek() 함수는 Badger데이터베이스에서 특정 키를 찾는 메서드)
-		for it.Seek(utxoPrefix); it.ValidForPrefix(utxoPrefix); it.Next() {
-			// 현재 아이템을 가져옴
-			item := it.Item()
-			// 아이템의 키를 가져옴
-			k := item.Key()
-			// 아이템의 값과 에러를 가져옴
-			v, err := item.ValueCopy(nil)
-			Handle(err)
-			// utxoPrefix를 제거
-			k = bytes.TrimPrefix(k, utxoPrefix)
-			// 키를 16진수 문자열로 인코딩
-			txID := hex.EncodeToString(k)
-			// 값에서 출력을 역직렬화
-			outs := DeserializeOutputs(v)
-
-			// 출력을 반복
-			for outIdx, out := range outs.Outputs {
-				// 출력이 주어진 공개키 해시로 잠겨있고 누적도니 금액이 지정된 금액보다 작으면
-				if out.IsLockedWithKey(pubKeyHash) && accumulated < amount {
-					// 누적된 금액을 증가
-					accumulated += out.Value
-					// 지출 가능한 UTXO 맵에 추가
-					unspentOuts[txID] = append(unspentOuts[txID], outIdx)
-				}
+		// 이 ID로 시작하는 더 긴 ID의 키도 접두사가 같으므로 키 길이로 구분
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if len(it.Item().Key()) == len(prefix)+4 {
+				found = true
+				break
 			}
 		}
-		// 에러가 없음을 반환
+
 		return nil
 	})
 	Handle(err)
-	// 누적된 금액과 지출 가능한 UTXO 맵 반환
-	return accumulated, unspentOuts
+
+	return found
 }
 
-// 주어진 공개키 해시에 대한 모든 UTXO를 찾음
-func (u UTXOSet) FindUnspentTransactions(pubKeyHash []byte) []TxOutput {
-	// UTXO 목록을 저장할 슬라이스
-	var UTXOs []TxOutput
+// 주어진 공개키 해시(또는 스크립트 해시)들로 잠긴 모든 UTXO를 찾음
+// 주소 색인에서 해당 주소의 키만 탐색하고 UTXO 항목을 조회
+func (u UTXOSet) FindUnspentOutputs(pubKeyHashes [][]byte) []UnspentOutput {
+	var UTXOs []UnspentOutput
 
-	// DB 참조
-	db := u.Blockchain.Database
+	// 같은 공개 키 해시가 여러 번 주어져도 한 번만 탐색
+	searched := make(map[string]bool)
 
-	// 데이터 베이스 읽기 모드
-	err := db.View(func(txn *badger.Txn) error {
-		// 기본 Iterator 옵션 설정
+	err := u.Blockchain.Database.View(func(txn *badger.Txn) error {
+		// 색인 키만 필요하므로 값 사전 로딩 비활성화
 		opts := badger.DefaultIteratorOptions
-
-		// Iterator 생성
+		opts.PrefetchValues = false
 		it := txn.NewIterator(opts)
-		// 함수 종료 시 Iterator를 닫음
 		defer it.Close()
 
-		// utxoPrefix로 시작하는 UTXO를 반복(Seek() 함수는 Badger데이터베이스에서 특정 키를 찾는 메서드)
-		for it.Seek(utxoPrefix); it.ValidForPrefix(utxoPrefix); it.Next() {
-			// Iterator의 현재 아이템
-			item := it.Item()
-			// 아이템의 값과 에러 가져옴
-			v, err := item.ValueCopy(nil)
-			Handle(err)
-			// 값에서 출력을 역직렬화
-			outs := DeserializeOutputs(v)
-
-			// 출력 반복
-			for _, out := range outs.Outputs {
-				// 출력이 주어진 공개키 해시로 잠겨있다면
-				if out.IsLockedWithKey(pubKeyHash) {
-					// UTXO 목록에 추가
-					UTXOs = append(UTXOs, out)
+		for _, pubKeyHash := range pubKeyHashes {
+			if len(pubKeyHash) == 0 || searched[string(pubKeyHash)] {
+				continue
+			}
+			searched[string(pubKeyHash)] = true
+
+			prefix := addressIndexKeyPrefix(pubKeyHash)
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				// 색인 키의 출력 위치로 UTXO 항목 조회
+				key := append(append([]byte{}, utxoPrefix...), it.Item().Key()[len(prefix):]...)
+				item, err := txn.Get(key)
+				if err != nil {
+					return fmt.Errorf("address index entry %x: %w", it.Item().Key(), err)
+				}
+				v, err := item.ValueCopy(nil)
+				if err != nil {
+					return err
 				}
+
+				UTXOs = append(UTXOs, deserializeUTXOEntry(key, v))
 			}
 		}
 
@@ -119,8 +248,39 @@ func (u UTXOSet) FindUnspentTransactions(pubKeyHash []byte) []TxOutput {
 	return UTXOs
 }
 
-// UTXO 데이터베이스에 저장된 트랜잭션 수를 반환
-func (u UTXOSet) CountTransactions() int {
+// 주어진 공개키 해시(또는 스크립트 해시)들로 잠긴 UTXO 중 다음 블록에서 사용할 수 있는 것만 찾음
+// 성숙 깊이에 도달하지 않은 코인베이스 출력은 제외
+func (u UTXOSet) FindSpendableOutputs(pubKeyHashes [][]byte) []UnspentOutput {
+	return matureOutputs(u.FindUnspentOutputs(pubKeyHashes), u.Blockchain.GetBestHeight()+1)
+}
+
+// 주어진 높이의 블록에서 사용할 수 있는 출력만 골라내는 함수
+func matureOutputs(utxos []UnspentOutput, height int) []UnspentOutput {
+	var mature []UnspentOutput
+	for _, utxo := range utxos {
+		if utxo.IsMature(height) {
+			mature = append(mature, utxo)
+		}
+	}
+
+	return mature
+}
+
+// 주어진 공개키 해시에 대한 모든 UTXO를 찾음
+func (u UTXOSet) FindUnspentTransactions(pubKeyHash []byte) []TxOutput {
+	// UTXO 목록을 저장할 슬라이스
+	var UTXOs []TxOutput
+
+	// 주소 색인으로 찾은 UTXO의 출력만 반환
+	for _, utxo := range u.FindUnspentOutputs([][]byte{pubKeyHash}) {
+		UTXOs = append(UTXOs, utxo.Output)
+	}
+
+	return UTXOs
+}
+
+// UTXO 데이터베이스에 저장된 출력 수를 반환
+func (u UTXOSet) CountOutputs() int {
 	// DB 참조
 	db := u.Blockchain.Database
 	// 카운터 초기화
@@ -156,32 +316,67 @@ func (u UTXOSet) Reindex() {
 	// 데이터베이스 참조
 	db := u.Blockchain.Database
 
-	// 이전 인덱스 삭제
+	// 이전 인덱스와 주소 색인 삭제
 	u.DeleteByPrefix(utxoPrefix)
+	u.DeleteByPrefix(addressIndexPrefix)
 
 	// UTXO 다시 찾음
 	UTXO := u.Blockchain.FindUTXO()
 
-	// 데이터베이스 쓰기 모드
-	err := db.Update(func(txn *badger.Txn) error {
-		// UTXO 반복
-		for txId, outs := range UTXO {
-			// 키를 16진수 문자열에서 바이트로 디코딩
-			key, err := hex.DecodeString(txId)
+	// 데이터베이스 쓰기 모드(많은 항목을 나누어 커밋하기 위해 WriteBatch 사용)
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+
+	// UTXO 반복
+	for _, utxo := range UTXO {
+		// 출력마다 키와 값을 데이터베이스에 설정
+		err := wb.Set(utxoKey(utxo.TxID, utxo.Vout), utxo.serializeEntry())
+		Handle(err)
+
+		// 주소 색인에 추가
+		if key := utxo.addressIndexKey(); key != nil {
+			Handle(wb.Set(key, []byte{}))
+		}
+	}
+	Handle(wb.Flush())
+}
+
+// 저장된 UTXO 항목으로 주소 색인을 다시 생성
+func (u UTXOSet) reindexAddresses() error {
+	db := u.Blockchain.Database
+
+	// 이전 주소 색인 삭제
+	u.DeleteByPrefix(addressIndexPrefix)
+
+	wb := db.NewWriteBatch()
+	defer wb.Cancel()
+
+	err := db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(utxoPrefix); it.ValidForPrefix(utxoPrefix); it.Next() {
+			item := it.Item()
+			v, err := item.ValueCopy(nil)
 			if err != nil {
 				return err
 			}
-			// 키에 utxoPrefix를 추가
-			key = append(utxoPrefix, key...)
 
-			// 키와 값을 데이터베이스에 설정
-			err = txn.Set(key, outs.Serialize())
-			Handle(err)
+			utxo := deserializeUTXOEntry(item.KeyCopy(nil), v)
+			if key := utxo.addressIndexKey(); key != nil {
+				if err := wb.Set(key, []byte{}); err != nil {
+					return err
+				}
+			}
 		}
 
 		return nil
 	})
-	Handle(err)
+	if err != nil {
+		return err
+	}
+
+	return wb.Flush()
 }
 
 // 주어진 블록에 대한 UTXO데이터베이스 업데이트를 수행
@@ -195,57 +390,44 @@ func (u *UTXOSet) Update(block *Block) {
 		for _, tx := range block.Transactions {
 			// 코인베이스 트랜잭션이 아니라면
 			if !tx.IsCoinbase() {
-				// 각 입력에 대해 반복
+				// 각 입력이 사용한 출력의 항목을 삭제
 				for _, in := range tx.Inputs {
-					// 업데이트된 출력을 저장할 구조체 생성
-					updatedOuts := TxOutputs{}
-					// 입력 ID에 utxoPrefix를 추가
-					inID := append(utxoPrefix, in.ID...)
-					// 입력 ID를 사용하여 데이터베이스에서 값을 가져옴
+					inID := utxoKey(in.ID, in.Out)
+					// 사용되지 않은 출력이 아니면 패닉
 					item, err := txn.Get(inID)
 					Handle(err)
-					// 값을 가져옴
 					v, err := item.ValueCopy(nil)
 					Handle(err)
 
-					// 값에서 출력을 역직렬화
-					outs := DeserializeOutputs(v)
-
-					// 출력을 반복
-					for outIdx, out := range outs.Outputs {
-						// 현재 출력이 입력의 인덱스와 다르다면
-						if outIdx != in.Out {
-							// 업데이트된 출력 목록에 추가
-							updatedOuts.Outputs = append(updatedOuts.Outputs, out)
-						}
+					if err := txn.Delete(inID); err != nil {
+						log.Panic(err)
 					}
 
-					// 업데이트된 출력 목록이 비어있다면
-					if len(updatedOuts.Outputs) == 0 {
-						// 입력 ID를 사용하여 데이터베이스에서 해당 값 삭제를 시도
-						if err := txn.Delete(inID); err != nil {
-							log.Panic(err)
-						}
-						// 그렇지 않다면
-					} else {
-						// 업데이트된 출력 목록을 데이터베이스에 설정
-						if err := txn.Set(inID, updatedOuts.Serialize()); err != nil {
+					// 사용된 출력을 주소 색인에서도 삭제
+					if key := deserializeUTXOEntry(inID, v).addressIndexKey(); key != nil {
+						if err := txn.Delete(key); err != nil {
 							log.Panic(err)
 						}
 					}
 				}
 			}
 
-			// 새로운 출력에 저장할 구조체를 생성
-			newOutputs := TxOutputs{}
-			// 새로운 출력 목록을 설정
-			newOutputs.Outputs = append(newOutputs.Outputs, tx.Outputs...)
+			// 새로운 출력마다 항목을 생성(사용할 수 없는 데이터 출력은 제외)
+			for outIdx, out := range tx.Outputs {
+				if out.IsUnspendable() {
+					continue
+				}
 
-			// 트랜잭션 ID에 utxoPrefix를 추가
-			txID := append(utxoPrefix, tx.ID...)
-			// 트랜잭션 ID와 새로운 출력을 데이터베이스에 설정
-			if err := txn.Set(txID, newOutputs.Serialize()); err != nil {
-				log.Panic(err)
+				// 블록 높이와 코인베이스 여부를 함께 기록
+				utxo := UnspentOutput{tx.ID, outIdx, out, block.Height, tx.IsCoinbase()}
+				if err := txn.Set(utxoKey(tx.ID, outIdx), utxo.serializeEntry()); err != nil {
+					log.Panic(err)
+				}
+				if key := utxo.addressIndexKey(); key != nil {
+					if err := txn.Set(key, []byte{}); err != nil {
+						log.Panic(err)
+					}
+				}
 			}
 		}
 
@@ -0,0 +1,156 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+	"github.com/dgraph-io/badger"
+)
+
+// 두 출력을 가진 코인베이스를 채굴하고 성숙시킨 뒤 출력 1만 사용하는 함수
+// 코인베이스와 그 블록 높이를 반환
+func spendSecondOutput(t *testing.T, chain *BlockChain, miner *wallet.Wallet) (*Transaction, int) {
+	t.Helper()
+
+	alice, bob := wallet.MakeWallet(), wallet.MakeWallet()
+	UTXOSet := UTXOSet{chain}
+
+	cbTx := CoinbaseTx(string(miner.Address()), "")
+	cbTx.Outputs = []TxOutput{*NewTXOutput(ActiveParams.Subsidy-5, string(alice.Address())), *NewTXOutput(5, string(bob.Address()))}
+	cbTx.ID = cbTx.Hash()
+	block := chain.MineBlock([]*Transaction{cbTx})
+	UTXOSet.Update(block)
+
+	// 코인베이스 출력을 사용할 수 있을 때까지 블록 생성
+	if _, err := chain.Generate(ActiveParams.CoinbaseMaturity, string(miner.Address())); err != nil {
+		t.Fatal(err)
+	}
+
+	spend := spendTx(t, bob, cbTx, 1, *NewTXOutput(4, string(alice.Address())))
+	template := chain.NewBlockTemplate([]*Transaction{spend})
+	if len(template.Transactions) != 1 {
+		t.Fatalf("template has %d transactions, want the spend of output 1", len(template.Transactions))
+	}
+	UTXOSet.Update(chain.MineBlock(template.BlockTransactions(string(miner.Address()))))
+
+	return cbTx, block.Height
+}
+
+// 출력 0만 원래 인덱스, 금액, 높이, 코인베이스 여부와 함께 남아 있는지 확인하는 함수
+func checkFirstOutputRemains(t *testing.T, set UTXOSet, cbTx *Transaction, height int) {
+	t.Helper()
+
+	if _, ok := set.FindEntry(cbTx.ID, 1); ok {
+		t.Fatalf("spent output 1 is still in the UTXO set")
+	}
+
+	utxo, ok := set.FindEntry(cbTx.ID, 0)
+	if !ok {
+		t.Fatalf("output 0 is missing from the UTXO set")
+	}
+	want := UnspentOutput{cbTx.ID, 0, cbTx.Outputs[0], height, true}
+	if utxo.Vout != want.Vout || utxo.Output.Value != want.Output.Value || utxo.Height != want.Height || utxo.Coinbase != want.Coinbase {
+		t.Fatalf("output 0 is %+v, want %+v", utxo, want)
+	}
+}
+
+func TestSpendingSecondOutputKeepsFirst(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	cbTx, height := spendSecondOutput(t, chain, miner)
+
+	// 블록마다 갱신한 UTXO 집합과 블록에서 다시 만든 UTXO 집합 모두 확인
+	set := UTXOSet{chain}
+	checkFirstOutputRemains(t, set, cbTx, height)
+	set.Reindex()
+	checkFirstOutputRemains(t, set, cbTx, height)
+}
+
+func TestMigrateToOutpointKeys(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	cbTx, height := spendSecondOutput(t, chain, miner)
+
+	// 스키마 버전 5의 트랜잭션별 항목으로 되돌림
+	// 출력 1을 사용한 뒤 남은 출력 0만 저장되어 원래 인덱스를 알 수 없음
+	set := UTXOSet{chain}
+	set.DeleteByPrefix(utxoPrefix)
+	oldKey := append(append([]byte{}, utxoPrefix...), cbTx.ID...)
+	oldEntry := TxOutputs{Outputs: cbTx.Outputs[:1], Height: height, Coinbase: true}
+	err := chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(oldKey, oldEntry.Serialize())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := setSchemaVersion(chain.Database, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chain.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 이전 항목은 지워지고 출력마다의 항목이 블록에서 다시 만들어져야 함
+	err = chain.Database.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(oldKey)
+		return err
+	})
+	if err != badger.ErrKeyNotFound {
+		t.Fatalf("per-transaction entry was not removed: %v", err)
+	}
+	checkFirstOutputRemains(t, set, cbTx, height)
+}
+
+// 주소 색인으로 출력 0의 주소(Alice)에 남은 두 출력을 찾고 출력 1의 주소(Bob)는 비어 있는지 확인하는 함수
+func checkAddressIndex(t *testing.T, set UTXOSet, cbTx *Transaction) {
+	t.Helper()
+
+	alice, bob := cbTx.Outputs[0].AddressHash(), cbTx.Outputs[1].AddressHash()
+
+	total := 0
+	for _, utxo := range set.FindUnspentOutputs([][]byte{alice, alice}) {
+		total += utxo.Output.Value
+	}
+	if want := ActiveParams.Subsidy - 5 + 4; total != want {
+		t.Fatalf("found %d for the first output's address, want %d", total, want)
+	}
+	if utxos := set.FindUnspentOutputs([][]byte{bob}); len(utxos) != 0 {
+		t.Fatalf("found %d outputs for the spent output's address, want none", len(utxos))
+	}
+
+	// 모든 출력이 주소로 잠겨 있으므로 색인 키와 UTXO 항목의 개수가 같아야 함
+	if info := set.Blockchain.DBInfo(); info.AddressIndex != info.UTXOs {
+		t.Fatalf("%d address index entries for %d UTXO entries", info.AddressIndex, info.UTXOs)
+	}
+}
+
+func TestFindUnspentOutputsByAddress(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	cbTx, _ := spendSecondOutput(t, chain, miner)
+
+	set := UTXOSet{chain}
+	checkAddressIndex(t, set, cbTx)
+	set.Reindex()
+	checkAddressIndex(t, set, cbTx)
+}
+
+func TestMigrateToAddressIndex(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	cbTx, _ := spendSecondOutput(t, chain, miner)
+
+	// 주소 색인이 없던 스키마 버전 6으로 되돌림
+	set := UTXOSet{chain}
+	set.DeleteByPrefix(addressIndexPrefix)
+	if err := setSchemaVersion(chain.Database, 6); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chain.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkAddressIndex(t, set, cbTx)
+}
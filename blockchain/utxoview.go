@@ -0,0 +1,268 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+var (
+	// 존재하지 않거나 이미 사용된 출력을 사용하는 트랜잭션의 에러
+	ErrMissingOutput = errors.New("transaction spends a missing or already spent output")
+	// 같은 출력을 두 번 사용하는 트랜잭션의 에러
+	ErrDoubleSpend = errors.New("output is spent twice")
+	// 출력 금액이 입력 금액보다 큰 트랜잭션의 에러
+	ErrInsufficientInput = errors.New("outputs exceed inputs")
+	// 금액이 잘못된 출력의 에러
+	ErrInvalidValue = errors.New("output value is invalid")
+	// 트랜잭션 크기 합계가 제한을 넘는 블록의 에러
+	ErrBlockTooLarge = errors.New("block exceeds the size limit")
+	// 서명 검증 연산 수가 제한을 넘는 블록의 에러
+	ErrTooManySigOps = errors.New("block exceeds the signature operation limit")
+	// ID가 내용으로 계산한 해시와 다른 트랜잭션의 에러
+	ErrInvalidTxID = errors.New("transaction ID does not match its contents")
+	// 이미 사용되지 않은 출력이 있는 트랜잭션과 ID가 같은 트랜잭션의 에러
+	ErrDuplicateTx = errors.New("transaction ID is already in use")
+	// 첫 트랜잭션만 코인베이스가 아닌 블록의 에러
+	ErrBadCoinbase = errors.New("block must start with exactly one coinbase transaction")
+)
+
+const (
+	// 한 출력 또는 트랜잭션 출력 합계의 최댓값(합계가 넘치지 않도록 제한)
+	maxOutputValue = 1 << 53
+
+	// 블록에 포함된 트랜잭션의 직렬화 크기 합계 제한(바이트)
+	MaxBlockSize = 1000000
+	// 블록에 포함된 트랜잭션의 서명 검증 연산 수 합계 제한
+	MaxBlockSigOps = 20000
+)
+
+// UTXO 집합 위에 트랜잭션을 차례로 적용하며 사용된 출력과 새로 생긴 출력을 추적하는 뷰
+// 데이터베이스는 바꾸지 않으므로 블록을 저장하기 전에 트랜잭션 목록을 검증할 때 사용
+type UTXOView struct {
+	set UTXOSet
+	// 뷰에 적용한 트랜잭션이 포함될 블록 높이(UTXO 집합의 다음 블록)
+	height int
+	// 시간 잠금의 기준이 되는 마지막 블록까지의 중간 시간
+	medianTime int64
+	// 뷰에 적용한 트랜잭션이 만든 출력("트랜잭션ID:출력 인덱스"를 키로 사용)
+	added map[string]UnspentOutput
+	// 뷰에 적용한 트랜잭션이 사용한 출력
+	spent map[string]bool
+	// 뷰에 적용한 트랜잭션의 ID(16진수)
+	txs map[string]bool
+}
+
+// UTXO 집합의 현재 상태에서 시작하는 뷰를 생성
+func NewUTXOView(set UTXOSet) *UTXOView {
+	bestHeight := set.Blockchain.GetBestHeight()
+
+	return &UTXOView{set, bestHeight + 1, set.Blockchain.MedianTimePast(bestHeight), make(map[string]UnspentOutput), make(map[string]bool), make(map[string]bool)}
+}
+
+// 뷰에서 사용되지 않은 출력을 블록 높이, 코인베이스 여부와 함께 찾는 함수
+// 뷰에 적용한 트랜잭션의 출력은 뷰의 블록 높이에 포함되는 것으로 봄
+func (v *UTXOView) fetch(outpoint UnspentOutput) (UnspentOutput, bool) {
+	key := outpoint.Outpoint()
+	if v.spent[key] {
+		return UnspentOutput{}, false
+	}
+	if utxo, ok := v.added[key]; ok {
+		return utxo, true
+	}
+
+	return v.set.FindEntry(outpoint.TxID, outpoint.Vout)
+}
+
+// 뷰의 출력으로 트랜잭션 입력의 잠금 해제 스크립트와 서명을 검증하는 함수
+// 사용하는 출력이 뷰에 남아 있도록 Connect 전에 호출해야 함
+func (v *UTXOView) VerifyScripts(tx *Transaction) error {
+	if tx.IsCoinbase() {
+		return nil
+	}
+
+	var prevOutputs []TxOutput
+	for inId, in := range tx.Inputs {
+		outpoint := UnspentOutput{TxID: in.ID, Vout: in.Out}
+		if v.spent[outpoint.Outpoint()] {
+			return fmt.Errorf("%w: input %d spends %s, already spent by an earlier transaction", ErrDoubleSpend, inId, outpoint.Outpoint())
+		}
+		prev, ok := v.fetch(outpoint)
+		if !ok {
+			return fmt.Errorf("%w: input %d spends %s", ErrMissingOutput, inId, outpoint.Outpoint())
+		}
+		prevOutputs = append(prevOutputs, prev.Output)
+	}
+
+	return tx.verifyScripts(prevOutputs)
+}
+
+// 트랜잭션을 뷰의 블록 높이의 블록에 포함할 수 있는지 서명과 잠금, 코인베이스 성숙 깊이를 확인하는 함수
+// 사용하는 출력이 뷰에 남아 있도록 Connect 전에 호출해야 함
+func (v *UTXOView) CheckInputs(tx *Transaction) error {
+	if err := v.VerifyScripts(tx); err != nil {
+		return err
+	}
+	if err := v.CheckLocks(tx); err != nil {
+		return err
+	}
+
+	return v.CheckCoinbaseMaturity(tx)
+}
+
+// 트랜잭션의 서명 검증 연산 수를 세는 함수
+// P2SH 입력의 redeem script까지 세려면 사용하는 출력이 뷰에 남아 있도록 Connect 전에 호출해야 함
+func (v *UTXOView) SigOps(tx *Transaction) int {
+	sigOps := 0
+	for _, out := range tx.Outputs {
+		sigOps += countSigOps(out.ScriptPubKey, false)
+	}
+	if tx.IsCoinbase() {
+		return sigOps
+	}
+
+	for _, in := range tx.Inputs {
+		sigOps += countSigOps(in.ScriptSig, false)
+
+		// 스크립트 해시 출력을 사용하는 입력은 잠금 해제 스크립트의 마지막 데이터인 redeem script도 실행됨
+		prev, ok := v.fetch(UnspentOutput{TxID: in.ID, Vout: in.Out})
+		if !ok || ExtractScriptHash(prev.Output.ScriptPubKey) == nil {
+			continue
+		}
+		if ops, err := parseScript(in.ScriptSig); err == nil && len(ops) > 0 {
+			sigOps += countSigOps(ops[len(ops)-1].data, true)
+		}
+	}
+
+	return sigOps
+}
+
+// 트랜잭션 ID가 내용과 일치하고 뷰와 UTXO 집합의 다른 트랜잭션과 겹치지 않는지 확인하는 함수
+// 위조되거나 중복된 ID로 다른 트랜잭션의 UTXO 항목을 덮어쓰지 못하도록 함
+func (v *UTXOView) CheckID(tx *Transaction) error {
+	if err := tx.CheckID(); err != nil {
+		return err
+	}
+	if v.txs[hex.EncodeToString(tx.ID)] || v.set.HasTransaction(tx.ID) {
+		return fmt.Errorf("%w: %x", ErrDuplicateTx, tx.ID)
+	}
+
+	return nil
+}
+
+// 트랜잭션의 ID와 입력, 출력 금액을 확인하고 뷰에 적용하는 함수(수수료 반환)
+// 코인베이스는 입력을 확인하지 않고 출력만 추가하며, 에러가 발생하면 뷰는 바뀌지 않음
+func (v *UTXOView) Connect(tx *Transaction) (int, error) {
+	if err := v.CheckID(tx); err != nil {
+		return 0, err
+	}
+
+	outputValue := 0
+	for i, out := range tx.Outputs {
+		if out.Value < 0 || out.Value > maxOutputValue-outputValue {
+			return 0, fmt.Errorf("%w: output %d has value %d", ErrInvalidValue, i, out.Value)
+		}
+		outputValue += out.Value
+	}
+
+	inputValue := 0
+	if !tx.IsCoinbase() {
+		inputs := make(map[string]bool)
+		for i, in := range tx.Inputs {
+			outpoint := UnspentOutput{TxID: in.ID, Vout: in.Out}
+			key := outpoint.Outpoint()
+
+			// 한 트랜잭션 안에서 같은 출력을 두 번 사용하면 거부
+			if inputs[key] {
+				return 0, fmt.Errorf("%w: input %d spends %s again", ErrDoubleSpend, i, key)
+			}
+			inputs[key] = true
+
+			// 앞의 트랜잭션이 이미 사용한 출력이면 이중 지불
+			if v.spent[key] {
+				return 0, fmt.Errorf("%w: input %d spends %s, already spent by an earlier transaction", ErrDoubleSpend, i, key)
+			}
+
+			prev, ok := v.fetch(outpoint)
+			if !ok {
+				return 0, fmt.Errorf("%w: input %d spends %s", ErrMissingOutput, i, key)
+			}
+			inputValue += prev.Output.Value
+		}
+
+		if inputValue < outputValue {
+			return 0, fmt.Errorf("%w: inputs %d, outputs %d", ErrInsufficientInput, inputValue, outputValue)
+		}
+	}
+
+	// 검증이 끝난 뒤에만 뷰를 바꿈
+	v.txs[hex.EncodeToString(tx.ID)] = true
+	if !tx.IsCoinbase() {
+		for _, in := range tx.Inputs {
+			v.spent[UnspentOutput{TxID: in.ID, Vout: in.Out}.Outpoint()] = true
+		}
+	}
+	for outIdx, out := range tx.Outputs {
+		if !out.IsUnspendable() {
+			v.added[UnspentOutput{TxID: tx.ID, Vout: outIdx}.Outpoint()] = UnspentOutput{tx.ID, outIdx, out, v.height, tx.IsCoinbase()}
+		}
+	}
+
+	if tx.IsCoinbase() {
+		return 0, nil
+	}
+
+	return inputValue - outputValue, nil
+}
+
+// 트랜잭션 목록을 UTXO 집합 위에서 순서대로 적용하여 ID와 서명, 이중 지불, 금액, 잠금, 코인베이스 성숙 깊이를 확인하는 함수
+// 앞의 트랜잭션이 만든 출력도 사용할 수 있으므로 부모와 자식 트랜잭션을 같은 블록에 포함할 수 있음
+// 첫 트랜잭션만 코인베이스여야 하며, 코인베이스의 출력 합계는 채굴 보상과 수수료 합계를 넘을 수 없고, 크기와 서명 검증 연산 수는 블록 제한 이내여야 함
+func (u UTXOSet) CheckTransactions(txs []*Transaction) error {
+	if len(txs) == 0 || !txs[0].IsCoinbase() {
+		return ErrBadCoinbase
+	}
+	for i, tx := range txs[1:] {
+		if tx.IsCoinbase() {
+			return fmt.Errorf("%w: transaction %d is a coinbase", ErrBadCoinbase, i+1)
+		}
+	}
+
+	view := NewUTXOView(u)
+
+	fees, coinbaseValue := 0, 0
+	size, sigOps := 0, 0
+	for _, tx := range txs {
+		if size += len(tx.Serialize()); size > MaxBlockSize {
+			return fmt.Errorf("%w: %d bytes, limit is %d", ErrBlockTooLarge, size, MaxBlockSize)
+		}
+		if sigOps += view.SigOps(tx); sigOps > MaxBlockSigOps {
+			return fmt.Errorf("%w: %d, limit is %d", ErrTooManySigOps, sigOps, MaxBlockSigOps)
+		}
+
+		// ID를 먼저 확인하고 사용하는 출력이 뷰에서 사라지기 전에 서명과 잠금, 성숙 깊이 확인
+		if err := view.CheckID(tx); err != nil {
+			return fmt.Errorf("transaction %x: %w", tx.ID, err)
+		}
+		if err := view.CheckInputs(tx); err != nil {
+			return fmt.Errorf("transaction %x: %w", tx.ID, err)
+		}
+		fee, err := view.Connect(tx)
+		if err != nil {
+			return fmt.Errorf("transaction %x: %w", tx.ID, err)
+		}
+		fees += fee
+
+		if tx.IsCoinbase() {
+			for _, out := range tx.Outputs {
+				coinbaseValue += out.Value
+			}
+		}
+	}
+
+	if coinbaseValue > ActiveParams.Subsidy+fees {
+		return fmt.Errorf("%w: coinbase pays %d, subsidy and fees are %d", ErrInvalidValue, coinbaseValue, ActiveParams.Subsidy+fees)
+	}
+
+	return nil
+}
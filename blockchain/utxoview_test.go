@@ -0,0 +1,99 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 두 입력이 같은 출력을 사용하는 트랜잭션을 만드는 함수
+func doubleInputTx(t *testing.T, w *wallet.Wallet, prev *Transaction, to string) *Transaction {
+	t.Helper()
+
+	in := TxInput{prev.ID, 0, nil, MaxTxInSequenceNum}
+	tx := &Transaction{Version: TxVersion, Inputs: []TxInput{in, in}, Outputs: []TxOutput{*NewTXOutput(ActiveParams.Subsidy, to)}}
+	tx.ID = tx.Hash()
+	tx.Sign(w.DeserializePrivateKey(w.PrivateKey), map[string]Transaction{hex.EncodeToString(prev.ID): *prev})
+
+	return tx
+}
+
+func TestCheckTransactions(t *testing.T) {
+	miner, alice := wallet.MakeWallet(), wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	set := UTXOSet{chain}
+
+	funding := coinbaseAt(chain, 1)
+	to := string(alice.Address())
+	cb := func() *Transaction { return CoinbaseTx(string(miner.Address()), "") }
+
+	spend := spendTx(t, miner, funding, 0, *NewTXOutput(ActiveParams.Subsidy, to))
+	conflict := spendTx(t, miner, funding, 0, *NewTXOutput(ActiveParams.Subsidy-1, to))
+	overspend := spendTx(t, miner, funding, 0, *NewTXOutput(ActiveParams.Subsidy+1, to))
+	missing := &Transaction{Version: TxVersion, Inputs: []TxInput{{funding.ID, 1, nil, MaxTxInSequenceNum}}, Outputs: []TxOutput{*NewTXOutput(1, to)}}
+	missing.ID = missing.Hash()
+
+	// 다른 트랜잭션의 ID를 가진 트랜잭션(UTXO 항목을 덮어쓰려는 시도)
+	forged := spendTx(t, miner, funding, 0, *NewTXOutput(ActiveParams.Subsidy, to))
+	forged.ID = coinbaseAt(chain, 2).ID
+
+	// 코인베이스 보상보다 많이 지급하는 코인베이스
+	greedy := cb()
+	greedy.Outputs[0].Value = ActiveParams.Subsidy + 1
+	greedy.ID = greedy.Hash()
+
+	tests := []struct {
+		name string
+		txs  []*Transaction
+		err  error
+	}{
+		{"valid", []*Transaction{cb(), spend}, nil},
+		{"double spend in a block", []*Transaction{cb(), spend, conflict}, ErrDoubleSpend},
+		{"double spend in a transaction", []*Transaction{cb(), doubleInputTx(t, miner, funding, to)}, ErrDoubleSpend},
+		{"overspend", []*Transaction{cb(), overspend}, ErrInsufficientInput},
+		{"missing output", []*Transaction{cb(), missing}, ErrMissingOutput},
+		{"coinbase pays too much", []*Transaction{greedy}, ErrInvalidValue},
+		{"forged ID", []*Transaction{cb(), forged}, ErrInvalidTxID},
+		{"duplicate transaction in a block", []*Transaction{cb(), spend, spend}, ErrDuplicateTx},
+		{"duplicate of an unspent transaction", []*Transaction{coinbaseAt(chain, 2)}, ErrDuplicateTx},
+		{"no coinbase", []*Transaction{spend}, ErrBadCoinbase},
+		{"coinbase not first", []*Transaction{spend, cb()}, ErrBadCoinbase},
+		{"two coinbases", []*Transaction{cb(), cb()}, ErrBadCoinbase},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := set.CheckTransactions(test.txs)
+			if test.err == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestConnectBlockRejectsForgedID(t *testing.T) {
+	miner, alice := wallet.MakeWallet(), wallet.MakeWallet()
+	chain := newTestChain(t, miner)
+	set := UTXOSet{chain}
+
+	// 피해자의 사용되지 않은 코인베이스와 같은 ID를 가진 트랜잭션을 담은 블록
+	victim := coinbaseAt(chain, 2)
+	forged := spendTx(t, miner, coinbaseAt(chain, 1), 0, *NewTXOutput(ActiveParams.Subsidy, string(alice.Address())))
+	forged.ID = victim.ID
+
+	block := CreateBlock([]*Transaction{CoinbaseTx(string(miner.Address()), ""), forged}, chain.LastHash, chain.GetBestHeight()+1, chain.NextBlockTime())
+	if err := chain.ConnectBlock(block); !errors.Is(err, ErrInvalidTxID) {
+		t.Fatalf("got error %v, want %v", err, ErrInvalidTxID)
+	}
+
+	// 피해자의 출력은 그대로 남아 있어야 함
+	utxo, ok := set.FindEntry(victim.ID, 0)
+	if !ok || utxo.Output.Value != victim.Outputs[0].Value || !utxo.Coinbase {
+		t.Fatalf("victim output %+v was overwritten", utxo)
+	}
+}
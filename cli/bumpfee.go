@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 노드에 전송한 지갑 트랜잭션을 같은 입력과 지불로 다시 만들고 더 높은 수수료로 서명하여 교체
+// 수수료는 보낼 때 기록한 잔돈 출력에서 내고 부족하면 같은 주소의 다른 UTXO를 추가하며, feeRate가 0이면 노드가 교체를 받아들이는 최소 수수료율 사용
+func (cli *CommandLine) bumpFee(txID string, feeRate int, nodeId string, mineNow bool) {
+	wallets, err := wallet.CreateWallets(nodeId)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// 잠긴 지갑으로는 서명하지 않음
+	if wallets.IsLocked() {
+		log.Panic(wallet.ErrWalletLocked)
+	}
+
+	data, change, ok := wallets.GetUnconfirmed(txID)
+	if !ok {
+		log.Panicf("Error: %s is not an unconfirmed wallet transaction", txID)
+	}
+	orig := blockchain.DeserializeTransaction(data)
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Database.Close()
+
+	// 이미 블록에 포함된 트랜잭션은 교체할 수 없으므로 지갑에서 삭제
+	if _, err := chain.FindTransaction(orig.ID); err == nil {
+		wallets.RemoveUnconfirmed(txID)
+		wallets.SaveFile(nodeId)
+		log.Panicf("Error: transaction %s is already confirmed", txID)
+	}
+	if !orig.SignalsReplacement() {
+		log.Panicf("Error: transaction %s does not signal replaceability (send it with -rbf)", txID)
+	}
+
+	// 원래 트랜잭션의 입력을 그대로 사용하고 입력을 소유한 지갑으로 서명
+	var selected []blockchain.UnspentOutput
+	var senders []*wallet.Wallet
+	owners := make(map[string]bool)
+	inputValue := 0
+	for inId, in := range orig.Inputs {
+		out, ok := UTXOSet.FindOutput(in.ID, in.Out)
+		if !ok {
+			log.Panicf("Error: input %x:%d is already spent", in.ID, in.Out)
+		}
+		inputValue += out.Value
+		selected = append(selected, blockchain.UnspentOutput{TxID: in.ID, Vout: in.Out, Output: out})
+
+		// 지갑의 키로 다시 서명할 수 있는 공개 키 해시 출력만 사용할 수 있음(스크립트 해시나 HTLC 출력은 서명할 수 없음)
+		pubKeyHash := blockchain.ExtractPubKeyHash(out.ScriptPubKey)
+		if pubKeyHash == nil {
+			log.Panicf("Error: input %d spends a %s output, bumpfee can only re-sign pay-to-pubkey-hash inputs", inId, blockchain.ClassifyScript(out.ScriptPubKey))
+		}
+		address := string(wallet.PubKeyHashToAddress(pubKeyHash))
+		if owners[address] {
+			continue
+		}
+		w, ok := wallets.Wallets[address]
+		if !ok || wallets.IsWatchOnly(address) {
+			log.Panicf("Error: %s is not in the wallet", address)
+		}
+		owners[address] = true
+		senders = append(senders, w)
+	}
+
+	// 트랜잭션을 만들 때 기록한 잔돈 출력은 새 수수료에 맞춰 다시 만들고 나머지 출력은 그대로 지불
+	// 잔돈은 NewTransaction이 첫 번째 입력의 소유자에게 반환하므로 원래와 같은 주소로 감
+	var payments []blockchain.Payment
+	var recorded []byte
+	// 잔돈을 제외한 출력(수수료 계산에 사용)
+	var outputs []blockchain.TxOutput
+	amount, outputValue := 0, 0
+	for i, out := range orig.Outputs {
+		outputValue += out.Value
+		if i == change {
+			continue
+		}
+		outputs = append(outputs, out)
+
+		if out.IsUnspendable() {
+			recorded = blockchain.ExtractNullData(out.ScriptPubKey)
+			continue
+		}
+		payments = append(payments, blockchain.Payment{Address: scriptAddress(out.ScriptPubKey), Amount: out.Value})
+		amount += out.Value
+	}
+	oldFee := inputValue - outputValue
+
+	rate := blockchain.FeeRate(feeRate)
+	if rate == 0 {
+		rate = blockchain.BumpedFeeRate(oldFee, &orig)
+	}
+
+	// 원래 입력으로 수수료를 낼 수 없으면 입력을 소유한 주소의 다른 UTXO를 큰 금액부터 추가
+	if _, err := blockchain.NewCoinSelection(selected, amount, outputs, rate); err != nil {
+		var pubKeyHashes [][]byte
+		for _, w := range senders {
+			pubKeyHashes = append(pubKeyHashes, wallet.PublicKeyHash(w.PublicKey))
+		}
+		extra := UTXOSet.FindSpendableOutputs(pubKeyHashes)
+		sort.Slice(extra, func(i, j int) bool { return extra[i].Output.Value > extra[j].Output.Value })
+
+		// 이 트랜잭션과 아직 블록에 포함되지 않은 다른 지갑 트랜잭션이 사용하는 출력은 제외
+		spent := make(map[string]bool)
+		for _, data := range wallets.Unconfirmed {
+			for _, in := range blockchain.DeserializeTransaction(data).Inputs {
+				spent[blockchain.UnspentOutput{TxID: in.ID, Vout: in.Out}.Outpoint()] = true
+			}
+		}
+
+		for _, utxo := range extra {
+			if spent[utxo.Outpoint()] {
+				continue
+			}
+			selected = append(selected, utxo)
+			inputValue += utxo.Output.Value
+			if _, err = blockchain.NewCoinSelection(selected, amount, outputs, rate); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			log.Panic("Error: ", err)
+		}
+	}
+
+	// 선택한 입력을 사용하므로 코인 선택 전략은 사용되지 않으며, 새 트랜잭션도 교체 가능함을 표시
+	tx := blockchain.NewTransaction(senders, selected, payments, nil, rate, orig.LockTime, true, recorded, &UTXOSet)
+
+	newFee := inputValue
+	for _, out := range tx.Outputs {
+		newFee -= out.Value
+	}
+	required := oldFee + blockchain.IncrementalRelayFeeRate.FeeForSize(tx.EstimatedSize())
+	if newFee < required {
+		log.Panicf("Error: fee %d is less than %d needed to replace fee %d, use a higher -feerate", newFee, required, oldFee)
+	}
+
+	submitTransaction(chain, &UTXOSet, tx, mineNow)
+
+	// 교체된 트랜잭션 대신 새 트랜잭션을 지갑에 저장
+	wallets.RemoveUnconfirmed(txID)
+	if !mineNow {
+		wallets.AddUnconfirmed(hex.EncodeToString(tx.ID), tx.Serialize(), blockchain.ChangeOutputIndex(tx, len(payments)))
+	}
+	wallets.SaveFile(nodeId)
+
+	fmt.Printf("Replaced %s (fee %d) with %x (fee %d)\n", txID, oldFee, tx.ID, newFee)
+}
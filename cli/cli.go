@@ -1,12 +1,18 @@
 package cli
 
 import (
+	"bufio"
+	"crypto/ecdsa"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"runtime"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Kim-DaeHan/go-blockchain/blockchain"
 	"github.com/Kim-DaeHan/go-blockchain/network"
@@ -19,14 +25,53 @@ type CommandLine struct{}
 // 명령어 사용법 출력
 func (cli *CommandLine) printUsage() {
 	fmt.Println("Usage:")
-	fmt.Println(" getbalance -address ADDRESS - get the balance for an address")
-	fmt.Println(" createblockchain -address ADDRESS creates a blockchain and sends genesis reward to address")
+	fmt.Println(" getbalance -address ADDRESS - get the balance for an address. Coinbase outputs are reported as immature until they are spendable")
+	fmt.Println(" createblockchain -address ADDRESS creates a blockchain from the network genesis block and mines a first block rewarding address (optional)")
 	fmt.Println(" printchain - Prints the blocks in the chain")
-	fmt.Println(" send -from FROM -to TO -amount AMOUNT -mine - Send amount of coins. Then -mine flag is set, mine off of this node")
-	fmt.Println(" createwallet - Creates a new Wallet")
+	fmt.Println(" sendmany -from FROM -to ADDRESS=AMOUNT,... -file FILE -utxos TXID:VOUT,... -strategy STRATEGY -feerate RATE -rbf -mine - Send to many addresses in one transaction. FILE is a JSON object of address to amount or a CSV of address,amount lines. -rbf lets bumpfee replace it")
+	fmt.Println(" createrawtx -from FROM -to ADDRESS=AMOUNT,... -utxos TXID:VOUT,... -strategy STRATEGY -feerate RATE -out FILE - Writes an unsigned transaction and the outputs it spends to FILE")
+	fmt.Println(" signrawtx -in FILE -out FILE - Checks the inputs and fee of a transaction file against the UTXO set and signs it with the wallet keys")
+	fmt.Println(" sendrawtx -in FILE -mine - Broadcasts a signed transaction file. Then -mine flag is set, mine off of this node")
+	fmt.Println(" createpsbt -inputs TXID:VOUT,... -to ADDRESS=AMOUNT,... -out FILE - Creates a partially signed transaction spending inputs owned by several parties")
+	fmt.Println(" signpsbt -in FILE -out FILE - Checks the inputs and fee against the UTXO set and signs the inputs of a partially signed transaction owned by the wallet")
+	fmt.Println(" combinepsbt -in FILE,FILE,... -out FILE - Verifies and combines the signatures of partially signed transactions")
+	fmt.Println(" decodepsbt -in FILE - Prints the inputs, outputs and signing state of a partially signed transaction")
+	fmt.Println(" finalizepsbt -in FILE -mine - Verifies a fully signed transaction and broadcasts it. Then -mine flag is set, mine off of this node")
+	fmt.Println(" initiateswap -from FROM -to TO -amount AMOUNT -locktime LOCKTIME -feerate RATE -mine - Starts an atomic swap: creates a secret and a contract TO can redeem with it, or FROM can refund after LOCKTIME")
+	fmt.Println(" participateswap -from FROM -to TO -amount AMOUNT -secrethash HASH -locktime LOCKTIME -feerate RATE -mine - Joins an atomic swap with a contract locked by the initiator's secret hash. Use a LOCKTIME before the initiator's")
+	fmt.Println(" auditswap -contract CONTRACT - Prints the terms of a swap contract and its unspent outputs on this chain")
+	fmt.Println(" redeemswap -contract CONTRACT -secret SECRET -to ADDRESS -feerate RATE -mine - Redeems a swap contract with its secret")
+	fmt.Println(" refundswap -contract CONTRACT -to ADDRESS -feerate RATE -mine - Refunds a swap contract after its lock time")
+	fmt.Println(" extractsecret -contract CONTRACT - Prints the secret revealed by the transaction that redeemed a swap contract")
+	fmt.Println(" notarize -file PATH -from FROM -feerate RATE -mine - Anchors the SHA-256 hash of a file on chain in a data output")
+	fmt.Println(" provefile -file PATH -out FILE - Writes a merkle proof that the hash of a notarized file is in a block")
+	fmt.Println(" verifyfileproof -file PATH -proof FILE - Verifies a file's inclusion proof and prints its block and confirmations")
+	fmt.Println(" getwalletbalance - get the total balance of every address in the wallet")
+	fmt.Println(" listunspent - Lists the unspent outputs of the wallet")
+	fmt.Println(" send -from FROM -utxos TXID:VOUT,... -to TO -amount AMOUNT -strategy STRATEGY -feerate RATE -locktime LOCKTIME -rbf -data HEX -mine - Send amount of coins. FROM is a comma separated address list or any, -utxos chooses the inputs. STRATEGY is bnb (default), largest, smallest or random, RATE is the fee per 1000 bytes. LOCKTIME is the block height (or unix time) after which the transaction can be mined, HEX is up to 80 bytes recorded in an unspendable data output. -rbf lets bumpfee replace it. Then -mine flag is set, mine off of this node")
+	fmt.Println(" bumpfee -txid TXID -feerate RATE -mine - Replaces an unconfirmed wallet transaction sent with -rbf with one paying a higher fee. Without -feerate the smallest fee the node accepts is used")
+	fmt.Println(" createwallet - Creates a new Wallet (derived from the HD seed, which is created with a mnemonic on first use)")
+	fmt.Println(" restorewallet -mnemonic MNEMONIC - Restores the HD wallet from a mnemonic and scans the chain for used addresses")
 	fmt.Println(" listaddresses - Lists the addresses in our wallet file")
+	fmt.Println(" importaddress -address ADDRESS -pubkeyhash HASH - Adds a watch-only address (or hex public key hash) to the wallet")
+	fmt.Println(" history - Prints the transactions and balance of all wallet addresses, including watch-only ones")
+	fmt.Println(" dumpprivkey -address ADDRESS - Prints the private key of an address in the wallet")
+	fmt.Println(" importprivkey -key KEY -rescan - Imports a private key into the wallet. Then -rescan flag is set, scan the chain for its outputs")
+	fmt.Println(" getpubkey -address ADDRESS - Prints the public key of an address in the wallet")
+	fmt.Println(" createmultisig -m M -pubkeys KEY,KEY,... - Adds an M-of-N multisig address made from public keys or wallet addresses")
+	fmt.Println(" signmessage -address ADDRESS -message MESSAGE - Signs a message with the private key of an address in the wallet")
+	fmt.Println(" verifymessage -address ADDRESS -signature SIGNATURE -message MESSAGE - Verifies that a message was signed by the owner of an address")
+	fmt.Println(" encryptwallet - Encrypts the private keys in the wallet file with a passphrase read from standard input")
+	fmt.Println(" walletpassphrase -timeout SECONDS - Reads the passphrase from standard input and keeps the wallet unlocked in the running node for SECONDS")
+	fmt.Println(" walletlock - Locks the wallet in the running node")
 	fmt.Println(" reindexutxo - Rebuilds the UTXO set")
+	fmt.Println(" dbinfo - Prints the database schema version and key counts")
+	fmt.Println(" exportchain -out FILE -from HEIGHT -to HEIGHT - Exports blocks in height order to a bootstrap file")
+	fmt.Println(" importchain -in FILE - Validates and imports blocks from a bootstrap file")
+	fmt.Println(" generate -n N -address ADDRESS - Immediately mines N blocks rewarding address (regtest only)")
+	fmt.Println(" getblocktemplate -node ADDRESS - Prints the transactions a running node would mine in its next block, ordered by package fee rate. ADDRESS defaults to the node of NODE_ID")
 	fmt.Println(" startnode -miner ADDRESS - Start a node with ID specified in NODE_ID env. var. -miner enables mining")
+	fmt.Println("The NETWORK env. var. selects mainnet (default), testnet or regtest")
 }
 
 // 명령행 인수를 유효성 검사
@@ -48,9 +93,33 @@ func (cli *CommandLine) StartNode(nodeID, minerAddress string) {
 			log.Panic("Wrong miner address!")
 		}
 	}
+
+	// 암호화된 지갑의 잠금 해제된 키를 노드가 실행되는 동안 메모리에만 보관
+	agent, err := wallet.StartAgent(nodeID)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer agent.Close()
+
 	network.StartServer(nodeID, minerAddress)
 }
 
+// 실행 중인 노드에 블록 템플릿을 요청하여 출력
+func (cli *CommandLine) getBlockTemplate(node string) {
+	template, err := network.RequestBlockTemplate(node)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	fmt.Printf("Height: %d\n", template.Height)
+	fmt.Printf("Previous block: %x\n", template.PrevHash)
+	fmt.Printf("Transactions: %d (%d bytes, %d sigops)\n", len(template.Transactions), template.Size, template.SigOps)
+	for i, tx := range template.Transactions {
+		fmt.Printf(" %x fee %d\n", tx.ID, template.Fees[i])
+	}
+	fmt.Printf("Coinbase value: %d\n", template.CoinbaseValue)
+}
+
 // UTXO 재색인
 func (cli *CommandLine) reindexUTXO(nodeId string) {
 	// 블록체인을 계속 사용하여 블록체인 객체 가져옴
@@ -62,10 +131,83 @@ func (cli *CommandLine) reindexUTXO(nodeId string) {
 	// UTXO 집합을 재색인
 	UTXOSet.Reindex()
 
-	// UTXO 집합에 있는 트랜잭션 수를 카운트
-	count := UTXOSet.CountTransactions()
-	// 재색인 완료 메시지와 트랜잭션 수를 출력
-	fmt.Printf("Done! There are %d transactions in the UTXO set.\n", count)
+	// UTXO 집합에 있는 출력 수를 카운트
+	count := UTXOSet.CountOutputs()
+	// 재색인 완료 메시지와 출력 수를 출력
+	fmt.Printf("Done! There are %d unspent outputs in the UTXO set.\n", count)
+}
+
+// 데이터베이스 정보 출력
+func (cli *CommandLine) dbInfo(nodeId string) {
+	// 블록체인을 열면서 필요한 마이그레이션이 함께 수행됨
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+
+	info := chain.DBInfo()
+
+	fmt.Printf("Schema version: %d (supported: %d)\n", info.SchemaVersion, blockchain.SchemaVersion)
+	fmt.Printf("Blocks: %d\n", info.Blocks)
+	fmt.Printf("UTXO entries: %d\n", info.UTXOs)
+	fmt.Printf("Address index entries: %d\n", info.AddressIndex)
+	fmt.Printf("Other keys: %d\n", info.Other)
+}
+
+// 블록을 즉시 채굴(regtest 전용)
+func (cli *CommandLine) generate(n int, address, nodeId string) {
+	// 지갑 주소가 유효한지 검증
+	if !wallet.ValidateAddress(address) {
+		log.Panic("Address is not Valid")
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+
+	blocks, err := chain.Generate(n, address)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// 채굴된 블록 해시 출력
+	for _, block := range blocks {
+		fmt.Printf("%x\n", block.Hash)
+	}
+}
+
+// 블록체인을 부트스트랩 파일로 내보내기
+func (cli *CommandLine) exportChain(out string, from, to int, nodeId string) {
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+
+	// 내보낼 파일 생성
+	file, err := os.Create(out)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer file.Close()
+
+	count, err := chain.ExportChain(file, from, to)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	fmt.Printf("Exported %d blocks to %s\n", count, out)
+}
+
+// 부트스트랩 파일에서 블록체인 가져오기
+func (cli *CommandLine) importChain(in, nodeId string) {
+	// 가져올 파일 열기
+	file, err := os.Open(in)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer file.Close()
+
+	count, err := blockchain.ImportChain(file, nodeId)
+	if err != nil {
+		log.Panicf("Import stopped after %d blocks: %s", count, err)
+	}
+
+	fmt.Printf("Imported %d blocks from %s\n", count, in)
 }
 
 func (cli *CommandLine) listAddresses(nodeId string) {
@@ -77,12 +219,150 @@ func (cli *CommandLine) listAddresses(nodeId string) {
 	for _, address := range addresses {
 		fmt.Println(address)
 	}
+	// 감시 전용 주소는 표시와 함께 출력
+	for _, address := range wallets.GetWatchOnlyAddresses() {
+		fmt.Printf("%s (watch-only)\n", address)
+	}
+	// 다중 서명 주소는 필요한 서명 수와 함께 출력
+	for _, address := range wallets.GetMultiSigAddresses() {
+		m, pubKeys := blockchain.ExtractMultiSig(wallets.GetRedeemScript(address))
+		fmt.Printf("%s (multisig %d of %d)\n", address, m, len(pubKeys))
+	}
+}
+
+// 주소의 공개 키 출력(다중 서명 주소를 만들 때 공유)
+func (cli *CommandLine) getPubKey(address, nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
+
+	w, ok := wallets.Wallets[address]
+	if !ok {
+		log.Panic("Address is not in the wallet")
+	}
+
+	fmt.Printf("%x\n", w.PublicKey)
+}
+
+// 공개 키 N개로 M-of-N 다중 서명 주소를 만들어 지갑에 추가
+// 공개 키 대신 지갑에 있는 주소를 사용할 수 있음
+func (cli *CommandLine) createMultiSig(m int, keys, nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
+
+	var pubKeys [][]byte
+	for _, key := range strings.Split(keys, ",") {
+		if w, ok := wallets.Wallets[key]; ok {
+			pubKeys = append(pubKeys, w.PublicKey)
+			continue
+		}
+
+		pubKey, err := hex.DecodeString(key)
+		if err != nil || len(pubKey) != 64 {
+			log.Panicf("Error: %s is not a public key or an address in the wallet", key)
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+
+	redeemScript, err := blockchain.NewMultiSigScript(m, pubKeys)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	address, err := wallets.AddMultiSig(redeemScript)
+	if err != nil {
+		log.Panic(err)
+	}
+	wallets.SaveFile(nodeId)
+
+	fmt.Printf("Multisig address: %s\n", address)
+	fmt.Printf("Redeem script: %x\n", redeemScript)
+}
+
+// 감시 전용 주소 추가
+func (cli *CommandLine) importAddress(address, pubKeyHash, nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
+
+	// 공개 키 해시가 주어지면 주소로 변환
+	if pubKeyHash != "" {
+		hash, err := hex.DecodeString(pubKeyHash)
+		if err != nil || len(hash) != 20 {
+			log.Panic("Public key hash is not Valid")
+		}
+		address = string(wallet.PubKeyHashToAddress(hash))
+	}
+
+	if err := wallets.AddWatchOnly(address); err != nil {
+		log.Panic(err)
+	}
+	wallets.SaveFile(nodeId)
+
+	fmt.Printf("Watching address: %s\n", address)
+}
+
+// 지갑의 모든 주소(감시 전용 포함)에 대한 트랜잭션 내역과 잔액 출력
+func (cli *CommandLine) history(nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
+
+	// 16진수 공개 키 해시로 조회할 수 있도록 변환
+	pubKeyHashes := make(map[string]bool)
+	watchOnly := make(map[string]bool)
+	for address, pubKeyHash := range wallets.GetAllPubKeyHashes() {
+		key := hex.EncodeToString(pubKeyHash)
+		pubKeyHashes[key] = true
+		watchOnly[key] = wallets.IsWatchOnly(address)
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+
+	balance, watchOnlyBalance := 0, 0
+	for _, entry := range chain.FindHistory(pubKeyHashes) {
+		amount, watchOnlyAmount := 0, 0
+		for pubKeyHash, delta := range entry.Deltas {
+			if watchOnly[pubKeyHash] {
+				watchOnlyAmount += delta
+			} else {
+				amount += delta
+			}
+		}
+		balance += amount
+		watchOnlyBalance += watchOnlyAmount
+
+		fmt.Printf("%d %x %+d", entry.Height, entry.TxID, amount)
+		if watchOnlyAmount != 0 {
+			fmt.Printf(" (watch-only: %+d)", watchOnlyAmount)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("Balance: %d (watch-only: %d, total: %d)\n", balance, watchOnlyBalance, balance+watchOnlyBalance)
 }
 
 func (cli *CommandLine) createWallet(nodeId string) {
 	// 파일에서 지갑 정보 불러와 변수 생성
 	wallets, _ := wallet.CreateWallets(nodeId)
 
+	// 잠긴 지갑에는 새 개인 키를 암호화하여 추가할 수 없음
+	if wallets.IsLocked() {
+		log.Panic(wallet.ErrWalletLocked)
+	}
+
+	// HD 시드가 없으면 새 니모닉으로 시드를 생성
+	if !wallets.HasHDSeed() {
+		mnemonic, err := wallet.NewMnemonic()
+		if err != nil {
+			log.Panic(err)
+		}
+		if err := wallets.InitHD(mnemonic); err != nil {
+			log.Panic(err)
+		}
+
+		fmt.Println("Write down this mnemonic to restore your wallet:")
+		fmt.Println(mnemonic)
+	}
+
 	// 새로운 지갑 생성하고 지갑 주소 생성하여 주소 불러와 변수 생성
 	address := wallets.AddWallet()
 
@@ -92,153 +372,945 @@ func (cli *CommandLine) createWallet(nodeId string) {
 	fmt.Printf("New address is: %s\n", address)
 }
 
-// 체인 내의 블록들을 출력
-func (cli *CommandLine) printChain(nodeId string) {
-	// 반복자를 사용하여 체인을 탐색
-	chain := blockchain.ContinueBlockChain(nodeId)
-	defer chain.Database.Close()
-	iter := chain.Iterator()
+// 니모닉으로 HD 지갑 복원
+func (cli *CommandLine) restoreWallet(mnemonic string, gapLimit int, nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
 
-	for {
-		// 다음(이전) 블록을 가져옴
-		block := iter.Next()
+	if err := wallets.InitHD(mnemonic); err != nil {
+		log.Panic(err)
+	}
 
-		// 블록의 정보를 출력
-		fmt.Printf("Prev. hash: %x\n", block.PrevHash)
-		fmt.Printf("Hash: %x\n", block.Hash)
+	// 체인에서 사용된 공개 키 해시 수집
+	used := make(map[string]bool)
+	if blockchain.DBexists(blockchain.DBPath(nodeId)) {
+		chain := blockchain.ContinueBlockChain(nodeId)
+		used = chain.FindUsedPubKeyHashes()
+		chain.Database.Close()
+	}
 
-		// 작업 증명 결과를 출력
-		pow := blockchain.NewProof(block)
-		fmt.Printf("PoW: %s\n", strconv.FormatBool(pow.Validate()))
-		// 트랜잭션 정보 출력
-		for _, tx := range block.Transactions {
-			fmt.Println(tx)
-		}
-		fmt.Println()
+	// 연속으로 gapLimit개의 미사용 주소가 나올 때까지 파생하며 사용된 주소 복원
+	addresses := wallets.ScanHD(func(pubKeyHash []byte) bool {
+		return used[hex.EncodeToString(pubKeyHash)]
+	}, gapLimit)
 
-		// 이전 해시값이 없다면 반복문 종료
-		if len(block.PrevHash) == 0 {
-			break
-		}
+	wallets.SaveFile(nodeId)
+
+	for _, address := range addresses {
+		fmt.Println(address)
 	}
+	fmt.Printf("Restored %d used addresses\n", len(addresses))
 }
 
-// 새로운 블록체인 생성
-func (cli *CommandLine) createBlockChain(address, nodeId string) {
-	// 지갑 주소가 유효한지 검증
-	if !wallet.ValidateAddress(address) {
-		log.Panic("Address is not Valid")
+// 주소의 개인 키 내보내기
+func (cli *CommandLine) dumpPrivKey(address, nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
+
+	w, ok := wallets.Wallets[address]
+	if !ok {
+		log.Panic("Address is not in the wallet")
 	}
 
-	// 블록체인을 초기화하고 주소를 첫 블록의 수신자로 지정
-	chain := blockchain.InitBlockChain(address, nodeId)
-	defer chain.Database.Close()
+	key, err := w.ExportPrivateKey()
+	if err != nil {
+		log.Panic(err)
+	}
 
-	// UTXOSet 객체 생성하고 블록체인 할당
-	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
-	// UTXO 집합 재색인
-	UTXOSet.Reindex()
+	fmt.Println(key)
+}
 
-	fmt.Println("Finished!")
+// 주소의 개인 키로 메시지에 서명
+func (cli *CommandLine) signMessage(address, message, nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
+
+	w, ok := wallets.Wallets[address]
+	if !ok {
+		log.Panic("Address is not in the wallet")
+	}
+
+	signature, err := w.SignMessage(message)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	fmt.Println(signature)
 }
 
-// 지갑 주소의 잔액을 조회
-func (cli *CommandLine) getBalance(address, nodeId string) {
-	// 지갑 주소 유효한지 검증
-	if !wallet.ValidateAddress(address) {
-		log.Panic("Address is not Valid")
+// 메시지 서명이 주소의 소유자가 만든 것인지 검증
+func (cli *CommandLine) verifyMessage(address, signature, message string) {
+	valid, err := wallet.VerifyMessage(address, signature, message)
+	if err != nil {
+		log.Panic(err)
 	}
 
-	// 기존 블록체인을 이어서 사용
+	fmt.Println(valid)
+}
+
+// 개인 키 가져오기
+func (cli *CommandLine) importPrivKey(key string, rescan bool, nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
+
+	w, err := wallet.ImportPrivateKey(key)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	address, err := wallets.ImportWallet(w)
+	if err != nil {
+		log.Panic(err)
+	}
+	wallets.SaveFile(nodeId)
+
+	fmt.Printf("Imported address: %s\n", address)
+
+	if !rescan {
+		return
+	}
+
+	// 체인에서 가져온 키의 사용되지 않은 출력 탐색
 	chain := blockchain.ContinueBlockChain(nodeId)
-	// UTXOSet 객체를 생성하고 블록체인 할당
-	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
 	defer chain.Database.Close()
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
 
-	// 잔액 초기화
 	balance := 0
-	// 주소를 Base58로 디코딩하고 공개 키 해시 추출
-	pubKeyHash := wallet.Base58Decode([]byte(address))
-	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-4]
-	// 사용되지 않은 트랜잭션 출력(UTXO)를 찾음
-	UTXOs := UTXOSet.FindUnspentTransactions(pubKeyHash)
-
-	// 모든 UTXO의 값을 합산하여 잔액 계산
+	UTXOs := UTXOSet.FindUnspentTransactions(wallet.PublicKeyHash(w.PublicKey))
 	for _, out := range UTXOs {
 		balance += out.Value
 	}
 
-	fmt.Printf("Balance of %s: %d\n", address, balance)
+	fmt.Printf("Rescan found %d unspent outputs, balance: %d\n", len(UTXOs), balance)
 }
 
-// 트랜잭션을 전송
-func (cli *CommandLine) send(from, to string, amount int, nodeId string, mineNow bool) {
-	// 수신 지갑 주소 유효한지 검증
-	if !wallet.ValidateAddress(to) {
-		log.Panic("Address is not Valid")
+// 표준 입력에서 비밀번호 한 줄을 읽는 함수
+// 명령행 인수로 받으면 셸 기록과 프로세스 목록에 남으므로 표준 입력으로 받음
+func readPassphrase(r *bufio.Reader, prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		log.Panic("Passphrase is not given")
 	}
-	// 발신 지갑 주소 유효한지 검증
-	if !wallet.ValidateAddress(from) {
-		log.Panic("Address is not Valid")
+	passphrase := strings.TrimRight(line, "\r\n")
+	if passphrase == "" {
+		log.Panic("Passphrase is empty")
 	}
 
-	// 기존 블록체인을 이어서 사용
-	chain := blockchain.ContinueBlockChain(nodeId)
-	// UTXOSet 객체를 생성하고 블록체인을 할당
-	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
-	defer chain.Database.Close()
+	return passphrase
+}
 
+// 지갑 암호화
+func (cli *CommandLine) encryptWallet(nodeId string) {
 	wallets, err := wallet.CreateWallets(nodeId)
-
 	if err != nil {
 		log.Panic(err)
 	}
-	wallet := wallets.GetWallet(from)
 
-	// 새로운 트랜잭션을 생성
-
-	tx := blockchain.NewTransaction(&wallet, to, amount, &UTXOSet)
+	// 잘못 입력하지 않도록 비밀번호를 두 번 입력받음
+	r := bufio.NewReader(os.Stdin)
+	passphrase := readPassphrase(r, "Enter passphrase: ")
+	if readPassphrase(r, "Repeat passphrase: ") != passphrase {
+		log.Panic("Passphrases do not match")
+	}
 
-	if mineNow {
-		cbTx := blockchain.CoinbaseTx(from, "")
-		txs := []*blockchain.Transaction{cbTx, tx}
-		block := chain.MineBlock(txs)
-		UTXOSet.Update(block)
-	} else {
-		network.SendTx(network.KnownNodes[0], tx)
-		fmt.Println("send tx")
+	// 모든 개인 키를 암호화하고 파일에 저장
+	if err := wallets.Encrypt(passphrase); err != nil {
+		log.Panic(err)
 	}
-	fmt.Println("Success!")
+	wallets.SaveFile(nodeId)
+
+	fmt.Println("Wallet encrypted. Use walletpassphrase while the node is running to unlock it before sending.")
 }
 
-func (cli *CommandLine) Run() {
-	// 명령행 인수를 유효성 검사
-	cli.validateArgs()
+// 실행 중인 노드에서 지갑을 잠금 해제
+func (cli *CommandLine) walletPassphrase(timeout int, nodeId string) {
+	wallets, err := wallet.CreateWallets(nodeId)
+	if err != nil {
+		log.Panic(err)
+	}
 
-	nodeId := os.Getenv("NODE_ID")
-	if nodeId == "" {
-		fmt.Printf("NODE_ID env is not set!")
-		runtime.Goexit()
+	passphrase := readPassphrase(bufio.NewReader(os.Stdin), "Enter passphrase: ")
+	if err := wallets.Unlock(passphrase, time.Duration(timeout)*time.Second, nodeId); err != nil {
+		log.Panic(err)
 	}
 
-	// 명령어를 파싱하기 위한 FlagSet을 생성
-	getBalanceCmd := flag.NewFlagSet("getbalance", flag.ExitOnError)
-	createBlockchainCmd := flag.NewFlagSet("createblockchain", flag.ExitOnError)
-	sendCmd := flag.NewFlagSet("send", flag.ExitOnError)
-	printChainCmd := flag.NewFlagSet("printchain", flag.ExitOnError)
-	createWalletCmd := flag.NewFlagSet("createwallet", flag.ExitOnError)
+	fmt.Printf("Wallet unlocked for %d seconds\n", timeout)
+}
+
+// 실행 중인 노드에서 지갑을 잠금
+func (cli *CommandLine) walletLock(nodeId string) {
+	wallets, err := wallet.CreateWallets(nodeId)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	if err := wallets.Lock(nodeId); err != nil {
+		log.Panic(err)
+	}
+
+	fmt.Println("Wallet locked")
+}
+
+// 지갑의 모든 주소(감시 전용 포함)의 잔액 합계 출력
+func (cli *CommandLine) getWalletBalance(nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+
+	// 성숙하지 않은 코인베이스 출력은 다음 블록에서 사용할 수 없으므로 따로 합산
+	height := chain.GetBestHeight() + 1
+	balance, immature, watchOnlyBalance := 0, 0, 0
+	for address, pubKeyHash := range wallets.GetAllPubKeyHashes() {
+		for _, utxo := range UTXOSet.FindUnspentOutputs([][]byte{pubKeyHash}) {
+			switch {
+			case wallets.IsWatchOnly(address):
+				watchOnlyBalance += utxo.Output.Value
+			case !utxo.IsMature(height):
+				immature += utxo.Output.Value
+			default:
+				balance += utxo.Output.Value
+			}
+		}
+	}
+
+	fmt.Printf("Balance: %d (immature: %d, watch-only: %d, total: %d)\n", balance, immature, watchOnlyBalance, balance+immature+watchOnlyBalance)
+}
+
+// 지갑의 모든 UTXO 출력
+func (cli *CommandLine) listUnspent(nodeId string) {
+	// 파일에서 지갑 정보 불러와 변수 생성
+	wallets, _ := wallet.CreateWallets(nodeId)
+
+	// 공개 키 해시(또는 스크립트 해시)로 주소를 찾을 수 있도록 맵 생성
+	addresses := make(map[string]string)
+	var pubKeyHashes [][]byte
+	for address, pubKeyHash := range wallets.GetAllPubKeyHashes() {
+		addresses[hex.EncodeToString(pubKeyHash)] = address
+		pubKeyHashes = append(pubKeyHashes, pubKeyHash)
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+
+	UTXOs := UTXOSet.FindUnspentOutputs(pubKeyHashes)
+
+	// 확인 수는 UTXO 항목에 기록된 블록 높이로 계산
+	bestHeight := chain.GetBestHeight()
+
+	for _, utxo := range UTXOs {
+		address := addresses[hex.EncodeToString(utxo.Output.AddressHash())]
+		confirmations := bestHeight - utxo.Height + 1
+
+		fmt.Printf("txid: %x vout: %d amount: %d address: %s confirmations: %d", utxo.TxID, utxo.Vout, utxo.Output.Value, address, confirmations)
+		if wallets.IsWatchOnly(address) {
+			fmt.Print(" (watch-only)")
+		}
+		if !utxo.IsMature(bestHeight + 1) {
+			fmt.Printf(" (immature, spendable at block %d)", utxo.Height+blockchain.ActiveParams.CoinbaseMaturity)
+		}
+		if wallets.GetRedeemScript(address) != nil {
+			fmt.Print(" (multisig)")
+		}
+		fmt.Println()
+	}
+}
+
+// 체인 내의 블록들을 출력
+func (cli *CommandLine) printChain(nodeId string) {
+	// 반복자를 사용하여 체인을 탐색
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+	iter := chain.Iterator()
+
+	for {
+		// 다음(이전) 블록을 가져옴
+		block := iter.Next()
+
+		// 블록의 정보를 출력
+		fmt.Printf("Prev. hash: %x\n", block.PrevHash)
+		fmt.Printf("Hash: %x\n", block.Hash)
+
+		// 작업 증명 결과를 출력
+		pow := blockchain.NewProof(block)
+		fmt.Printf("PoW: %s\n", strconv.FormatBool(pow.Validate()))
+		// 트랜잭션 정보 출력
+		for _, tx := range block.Transactions {
+			fmt.Println(tx)
+		}
+		fmt.Println()
+
+		// 이전 해시값이 없다면 반복문 종료
+		if len(block.PrevHash) == 0 {
+			break
+		}
+	}
+}
+
+// 새로운 블록체인 생성
+func (cli *CommandLine) createBlockChain(address, nodeId string) {
+	// 지갑 주소가 주어진 경우 유효한지 검증
+	if address != "" && !wallet.ValidateAddress(address) {
+		log.Panic("Address is not Valid")
+	}
+
+	// 네트워크의 고정된 Genesis 블록으로 블록체인을 초기화
+	chain := blockchain.InitBlockChain(nodeId)
+	defer chain.Database.Close()
+
+	// UTXOSet 객체 생성하고 블록체인 할당
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	// UTXO 집합 재색인
+	UTXOSet.Reindex()
+
+	// 주소가 주어지면 첫 블록을 채굴하여 보상을 지급
+	if address != "" {
+		cbTx := blockchain.CoinbaseTx(address, "")
+		block := chain.MineBlock([]*blockchain.Transaction{cbTx})
+		UTXOSet.Update(block)
+	}
+
+	fmt.Println("Finished!")
+}
+
+// 지갑 주소의 잔액을 조회
+func (cli *CommandLine) getBalance(address, nodeId string) {
+	// 지갑 주소 유효한지 검증
+	if !wallet.ValidateAddress(address) {
+		log.Panic("Address is not Valid")
+	}
+
+	// 기존 블록체인을 이어서 사용
+	chain := blockchain.ContinueBlockChain(nodeId)
+	// UTXOSet 객체를 생성하고 블록체인 할당
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Database.Close()
+
+	// 잔액 초기화(성숙하지 않은 코인베이스 출력은 따로 합산)
+	balance, immature := 0, 0
+	// 주소를 Base58로 디코딩하고 공개 키 해시 추출
+	pubKeyHash := wallet.Base58Decode([]byte(address))
+	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-4]
+	// 사용되지 않은 트랜잭션 출력(UTXO)를 찾음
+	UTXOs := UTXOSet.FindUnspentOutputs([][]byte{pubKeyHash})
+
+	// 다음 블록에서 사용할 수 있는 UTXO의 값을 합산하여 잔액 계산
+	height := chain.GetBestHeight() + 1
+	for _, utxo := range UTXOs {
+		if utxo.IsMature(height) {
+			balance += utxo.Output.Value
+		} else {
+			immature += utxo.Output.Value
+		}
+	}
+
+	fmt.Printf("Balance of %s: %d (immature: %d)\n", address, balance, immature)
+}
+
+func (cli *CommandLine) send(from, utxos, to string, amount int, strategy string, feeRate int, lockTime uint32, rbf bool, data []byte, nodeId string, mineNow bool) {
+	tx := cli.sendPayments(from, utxos, []blockchain.Payment{{Address: to, Amount: amount}}, strategy, feeRate, lockTime, rbf, data, nodeId, mineNow)
+
+	fmt.Printf("Transaction: %x\n", tx.ID)
+}
+
+// 여러 수신자에게 보내는 하나의 트랜잭션 생성
+func (cli *CommandLine) sendMany(from, to, file, utxos, strategy string, feeRate int, rbf bool, nodeId string, mineNow bool) {
+	var payments []blockchain.Payment
+	var err error
+	if file != "" {
+		payments, err = readPaymentsFile(file)
+	} else {
+		payments, err = parsePayments(to)
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+
+	tx := cli.sendPayments(from, utxos, payments, strategy, feeRate, 0, rbf, nil, nodeId, mineNow)
+
+	fmt.Printf("Transaction: %x\n", tx.ID)
+}
+
+// 지불 목록으로 트랜잭션을 생성하여 채굴하거나 전송
+// lockTime이 0이 아니면 해당 블록 높이 또는 유닉스 시간 이후에만 블록에 포함될 수 있음
+// rbf이면 bumpfee로 수수료를 높여 교체할 수 있음
+// data가 있으면 사용할 수 없는 데이터 출력에 기록
+func (cli *CommandLine) sendPayments(from, utxos string, payments []blockchain.Payment, strategy string, feeRate int, lockTime uint32, rbf bool, data []byte, nodeId string, mineNow bool) *blockchain.Transaction {
+	// 기존 블록체인을 이어서 사용
+	chain := blockchain.ContinueBlockChain(nodeId)
+	// UTXOSet 객체를 생성하고 블록체인을 할당
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Database.Close()
+
+	wallets, err := wallet.CreateWallets(nodeId)
+
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// 잠긴 지갑으로는 서명하지 않음
+	if wallets.IsLocked() {
+		log.Panic(wallet.ErrWalletLocked)
+	}
+
+	senders, selected, selector := prepareSpend(wallets, from, utxos, payments, strategy)
+
+	// 새로운 트랜잭션을 생성
+
+	tx := blockchain.NewTransaction(senders, selected, payments, selector, blockchain.FeeRate(feeRate), lockTime, rbf, data, &UTXOSet)
+
+	submitTransaction(chain, &UTXOSet, tx, mineNow)
+
+	// 노드에 전송한 트랜잭션은 블록에 포함될 때까지 수수료를 높여 교체할 수 있도록 지갑에 저장
+	if !mineNow {
+		wallets.AddUnconfirmed(hex.EncodeToString(tx.ID), tx.Serialize(), blockchain.ChangeOutputIndex(tx, len(payments)))
+		wallets.SaveFile(nodeId)
+	}
+
+	return tx
+}
+
+// 발신 지갑, 직접 선택한 UTXO와 코인 선택 전략을 준비하는 함수
+func prepareSpend(wallets *wallet.Wallets, from, utxos string, payments []blockchain.Payment, strategy string) ([]*wallet.Wallet, []blockchain.UnspentOutput, blockchain.CoinSelector) {
+	// 수신 지갑 주소와 금액이 유효한지 검증
+	for _, payment := range payments {
+		if !wallet.ValidateAddress(payment.Address) {
+			log.Panic("Address is not Valid")
+		}
+		if payment.Amount <= 0 {
+			log.Panic("Amount must be positive")
+		}
+	}
+
+	// 발신 주소 목록(any이거나 비어 있으면 지갑의 모든 주소)
+	var addresses []string
+	if from == "" || from == "any" {
+		addresses = wallets.GetAllAddresses()
+	} else {
+		addresses = strings.Split(from, ",")
+	}
+
+	var senders []*wallet.Wallet
+	for _, address := range addresses {
+		// 발신 지갑 주소 유효한지 검증
+		if !wallet.ValidateAddress(address) {
+			log.Panic("Address is not Valid")
+		}
+		// 감시 전용 주소나 지갑에 없는 주소로는 서명할 수 없음
+		if wallets.IsWatchOnly(address) {
+			log.Panic("Cannot send from a watch-only address")
+		}
+		if _, ok := wallets.Wallets[address]; !ok {
+			log.Panic("Address is not in the wallet")
+		}
+
+		wallet := wallets.GetWallet(address)
+		senders = append(senders, &wallet)
+	}
+
+	// 직접 선택한 UTXO 파싱
+	var selected []blockchain.UnspentOutput
+	if utxos != "" {
+		for _, outpoint := range strings.Split(utxos, ",") {
+			utxo, err := blockchain.ParseOutpoint(outpoint)
+			if err != nil {
+				log.Panic(err)
+			}
+			selected = append(selected, utxo)
+		}
+	}
+
+	// 코인 선택 전략
+	selector, err := blockchain.NewCoinSelector(strategy)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	return senders, selected, selector
+}
+
+// 잠금 스크립트의 주소를 반환하는 함수(주소로 나타낼 수 없는 스크립트는 종류)
+func scriptAddress(script []byte) string {
+	if pubKeyHash := blockchain.ExtractPubKeyHash(script); pubKeyHash != nil {
+		return string(wallet.PubKeyHashToAddress(pubKeyHash))
+	}
+	if scriptHash := blockchain.ExtractScriptHash(script); scriptHash != nil {
+		return string(wallet.ScriptHashToAddress(scriptHash))
+	}
+
+	return blockchain.ClassifyScript(script).String()
+}
+
+// 서명된 트랜잭션을 바로 채굴하거나 노드에 전송하는 함수
+// 채굴 보상은 첫 번째 입력이 소비하는 출력의 소유자에게 지급
+func submitTransaction(chain *blockchain.BlockChain, UTXOSet *blockchain.UTXOSet, tx *blockchain.Transaction, mineNow bool) {
+	rewardAddress := ""
+	if mineNow {
+		prevOutput := chain.FindPrevOutputs(&blockchain.Transaction{Inputs: tx.Inputs[:1]})[0]
+		rewardAddress = scriptAddress(prevOutput.ScriptPubKey)
+	}
+
+	submitTransactionWithReward(chain, UTXOSet, tx, rewardAddress, mineNow)
+}
+
+// 서명된 트랜잭션을 채굴 보상을 rewardAddress로 보내는 블록으로 바로 채굴하거나 노드에 전송하는 함수
+func submitTransactionWithReward(chain *blockchain.BlockChain, UTXOSet *blockchain.UTXOSet, tx *blockchain.Transaction, rewardAddress string, mineNow bool) {
+	if mineNow {
+		// 트랜잭션의 수수료가 사라지지 않도록 NewBlockTemplate처럼 코인베이스가 보조금과 수수료를 함께 받음
+		fee, err := blockchain.NewUTXOView(*UTXOSet).Connect(tx)
+		if err != nil {
+			log.Panic("Error: ", err)
+		}
+		cbTx := blockchain.CoinbaseTx(rewardAddress, "")
+		cbTx.Outputs[0].Value += fee
+		cbTx.ID = cbTx.Hash()
+
+		txs := []*blockchain.Transaction{cbTx, tx}
+		block := chain.MineBlock(txs)
+		UTXOSet.Update(block)
+	} else {
+		network.SendTx(network.KnownNodes[0], tx)
+		fmt.Println("send tx")
+	}
+	fmt.Println("Success!")
+}
+
+// 서명되지 않은 트랜잭션을 이전 출력과 함께 파일로 저장
+// 개인 키가 필요 없으므로 잠긴 지갑으로도 생성 가능
+func (cli *CommandLine) createRawTx(from, to, utxos, strategy string, feeRate int, out, nodeId string) {
+	payments, err := parsePayments(to)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Database.Close()
+
+	wallets, err := wallet.CreateWallets(nodeId)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	senders, selected, selector := prepareSpend(wallets, from, utxos, payments, strategy)
+	tx, prevOutputs := blockchain.NewUnsignedTransaction(senders, selected, payments, selector, blockchain.FeeRate(feeRate), 0, false, nil, &UTXOSet)
+
+	writeRawTx(out, &blockchain.RawTransaction{Tx: tx, PrevOutputs: prevOutputs})
+
+	fmt.Printf("Unsigned transaction %x written to %s\n", tx.ID, out)
+}
+
+// 파일의 트랜잭션을 지갑의 개인 키로 서명
+// 서명은 이전 출력의 금액을 포함하지 않으므로 로컬 UTXO 집합으로 이전 출력을 확인하고 수수료를 보여준 뒤 서명
+func (cli *CommandLine) signRawTx(in, out, nodeId string) {
+	raw := readRawTx(in)
+
+	wallets, err := wallet.CreateWallets(nodeId)
+	if err != nil {
+		log.Panic(err)
+	}
+	if wallets.IsLocked() {
+		log.Panic(wallet.ErrWalletLocked)
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	fee, err := raw.CheckFee(blockchain.UTXOSet{Blockchain: chain})
+	chain.Database.Close()
+	if err != nil {
+		log.Panic(err)
+	}
+	for outId, output := range raw.Tx.Outputs {
+		fmt.Printf("  Output %d: amount: %d address: %s\n", outId, output.Value, scriptAddress(output.ScriptPubKey))
+	}
+	fmt.Printf("Fee: %d\n", fee)
+
+	// 지갑의 모든 개인 키를 공개 키 해시로 찾을 수 있도록 맵 생성
+	keys := make(map[string]*ecdsa.PrivateKey)
+	for _, w := range wallets.Wallets {
+		keys[hex.EncodeToString(wallet.PublicKeyHash(w.PublicKey))] = w.DeserializePrivateKey(w.PrivateKey)
+	}
+
+	if err := raw.Tx.SignWithKeys(keys, raw.PrevOutputs); err != nil {
+		log.Panic(err)
+	}
+	if !raw.IsSigned() {
+		log.Panic("ERROR: Signed transaction does not verify")
+	}
+
+	writeRawTx(out, raw)
+
+	fmt.Printf("Signed transaction %x written to %s\n", raw.Tx.ID, out)
+}
+
+// 서명된 트랜잭션 파일을 채굴하거나 노드에 전송
+func (cli *CommandLine) sendRawTx(in, nodeId string, mineNow bool) {
+	raw := readRawTx(in)
+	if !raw.IsSigned() {
+		log.Panic("ERROR: Transaction is not signed")
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Database.Close()
+
+	submitTransaction(chain, &UTXOSet, raw.Tx, mineNow)
+}
+
+// 여러 사람의 입력을 사용하는 부분 서명 트랜잭션을 파일로 생성
+func (cli *CommandLine) createPSBT(inputs, to, out, nodeId string) {
+	payments, err := parsePayments(to)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Database.Close()
+
+	// 입력으로 사용할 UTXO가 존재하는지 확인
+	var txInputs []blockchain.TxInput
+	for _, outpoint := range strings.Split(inputs, ",") {
+		utxo, err := blockchain.ParseOutpoint(outpoint)
+		if err != nil {
+			log.Panic(err)
+		}
+		if _, ok := UTXOSet.FindOutput(utxo.TxID, utxo.Vout); !ok {
+			log.Panicf("Error: %s is not an unspent output", outpoint)
+		}
+		txInputs = append(txInputs, blockchain.TxInput{ID: utxo.TxID, Out: utxo.Vout})
+	}
+
+	var outputs []blockchain.TxOutput
+	for _, payment := range payments {
+		if !wallet.ValidateAddress(payment.Address) {
+			log.Panic("Address is not Valid")
+		}
+		outputs = append(outputs, *blockchain.NewTXOutput(payment.Amount, payment.Address))
+	}
+
+	// 다중 서명 주소의 출력을 사용할 수 있도록 지갑의 redeem script를 스크립트 해시로 찾을 수 있게 함
+	wallets, _ := wallet.CreateWallets(nodeId)
+	redeemScripts := make(map[string][]byte)
+	for _, address := range wallets.GetMultiSigAddresses() {
+		redeemScript := wallets.GetRedeemScript(address)
+		redeemScripts[hex.EncodeToString(wallet.PublicKeyHash(redeemScript))] = redeemScript
+	}
+
+	prevOutputs := chain.FindPrevOutputs(&blockchain.Transaction{Inputs: txInputs})
+	psbt, err := blockchain.NewPSBT(txInputs, outputs, prevOutputs, redeemScripts)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	writePSBT(out, psbt)
+
+	fmt.Printf("Partially signed transaction %x written to %s\n", psbt.Tx.ID, out)
+}
+
+// 지갑의 개인 키로 서명할 수 있는 입력에 서명
+// 서명은 이전 출력의 금액을 포함하지 않으므로 로컬 UTXO 집합으로 이전 출력을 확인하고 수수료를 보여준 뒤 서명
+func (cli *CommandLine) signPSBT(in, out, nodeId string) {
+	psbt := readPSBT(in)
+
+	wallets, err := wallet.CreateWallets(nodeId)
+	if err != nil {
+		log.Panic(err)
+	}
+	if wallets.IsLocked() {
+		log.Panic(wallet.ErrWalletLocked)
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	fee, err := psbt.CheckFee(blockchain.UTXOSet{Blockchain: chain})
+	chain.Database.Close()
+	if err != nil {
+		log.Panic(err)
+	}
+	for outId, output := range psbt.Tx.Outputs {
+		fmt.Printf("  Output %d: amount: %d address: %s\n", outId, output.Value, scriptAddress(output.ScriptPubKey))
+	}
+	fmt.Printf("Fee: %d\n", fee)
+
+	keys := make(map[string]*ecdsa.PrivateKey)
+	for _, w := range wallets.Wallets {
+		keys[hex.EncodeToString(wallet.PublicKeyHash(w.PublicKey))] = w.DeserializePrivateKey(w.PrivateKey)
+	}
+
+	signed := psbt.Sign(keys)
+	writePSBT(out, psbt)
+
+	fmt.Printf("Added %d signatures, %d of %d inputs are signed\n", signed, psbt.SignedCount(), len(psbt.Inputs))
+}
+
+// 여러 사람이 서명한 부분 서명 트랜잭션 파일을 합침
+func (cli *CommandLine) combinePSBT(in, out string) {
+	files := strings.Split(in, ",")
+
+	psbt := readPSBT(files[0])
+	for _, file := range files[1:] {
+		if err := psbt.Combine(readPSBT(file)); err != nil {
+			log.Panic(err)
+		}
+	}
+
+	writePSBT(out, psbt)
+
+	fmt.Printf("%d of %d inputs are signed\n", psbt.SignedCount(), len(psbt.Inputs))
+}
+
+// 부분 서명 트랜잭션의 입력별 서명 상태 출력
+func (cli *CommandLine) decodePSBT(in string) {
+	psbt := readPSBT(in)
+
+	fmt.Printf("Transaction %x\n", psbt.Tx.ID)
+	for inId, input := range psbt.Inputs {
+		txIn := psbt.Tx.Inputs[inId]
+		fmt.Printf("  Input %d: %x:%d amount: %d address: %s signatures: %d of %d\n", inId, txIn.ID, txIn.Out, input.PrevOutput.Value, scriptAddress(input.PrevOutput.ScriptPubKey), len(input.PartialSigs), input.RequiredSigs())
+	}
+	for outId, output := range psbt.Tx.Outputs {
+		fmt.Printf("  Output %d: amount: %d address: %s\n", outId, output.Value, scriptAddress(output.ScriptPubKey))
+	}
+}
+
+// 모든 입력이 서명된 부분 서명 트랜잭션을 검증하여 완성하고 채굴하거나 전송
+func (cli *CommandLine) finalizePSBT(in, nodeId string, mineNow bool) {
+	tx, err := readPSBT(in).Finalize()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Database.Close()
+
+	submitTransaction(chain, &UTXOSet, tx, mineNow)
+}
+
+// 부분 서명 트랜잭션을 16진수 텍스트 파일로 저장하는 함수
+func writePSBT(path string, psbt *blockchain.PartiallySignedTransaction) {
+	content := hex.EncodeToString(psbt.Serialize()) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		log.Panic(err)
+	}
+}
+
+// 16진수 텍스트 파일에서 부분 서명 트랜잭션을 읽는 함수
+func readPSBT(path string) *blockchain.PartiallySignedTransaction {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	data, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		log.Panic(err)
+	}
+
+	psbt, err := blockchain.DeserializePSBT(data)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	return psbt
+}
+
+// 원시 트랜잭션을 16진수 텍스트 파일로 저장하는 함수
+func writeRawTx(path string, raw *blockchain.RawTransaction) {
+	content := hex.EncodeToString(raw.Serialize()) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		log.Panic(err)
+	}
+}
+
+// 16진수 텍스트 파일에서 원시 트랜잭션을 읽는 함수
+func readRawTx(path string) *blockchain.RawTransaction {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	data, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		log.Panic(err)
+	}
+
+	raw, err := blockchain.DeserializeRawTransaction(data)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	return raw
+}
+
+func (cli *CommandLine) Run() {
+	// 명령행 인수를 유효성 검사
+	cli.validateArgs()
+
+	nodeId := os.Getenv("NODE_ID")
+	if nodeId == "" {
+		fmt.Printf("NODE_ID env is not set!")
+		runtime.Goexit()
+	}
+
+	// NETWORK 환경 변수로 체인 파라미터 선택
+	if err := blockchain.SelectParams(os.Getenv("NETWORK")); err != nil {
+		log.Panic(err)
+	}
+	network.SetSeedNode()
+
+	// 명령어를 파싱하기 위한 FlagSet을 생성
+	getBalanceCmd := flag.NewFlagSet("getbalance", flag.ExitOnError)
+	createBlockchainCmd := flag.NewFlagSet("createblockchain", flag.ExitOnError)
+	sendCmd := flag.NewFlagSet("send", flag.ExitOnError)
+	sendManyCmd := flag.NewFlagSet("sendmany", flag.ExitOnError)
+	createRawTxCmd := flag.NewFlagSet("createrawtx", flag.ExitOnError)
+	signRawTxCmd := flag.NewFlagSet("signrawtx", flag.ExitOnError)
+	sendRawTxCmd := flag.NewFlagSet("sendrawtx", flag.ExitOnError)
+	createPSBTCmd := flag.NewFlagSet("createpsbt", flag.ExitOnError)
+	signPSBTCmd := flag.NewFlagSet("signpsbt", flag.ExitOnError)
+	combinePSBTCmd := flag.NewFlagSet("combinepsbt", flag.ExitOnError)
+	decodePSBTCmd := flag.NewFlagSet("decodepsbt", flag.ExitOnError)
+	finalizePSBTCmd := flag.NewFlagSet("finalizepsbt", flag.ExitOnError)
+	initiateSwapCmd := flag.NewFlagSet("initiateswap", flag.ExitOnError)
+	participateSwapCmd := flag.NewFlagSet("participateswap", flag.ExitOnError)
+	auditSwapCmd := flag.NewFlagSet("auditswap", flag.ExitOnError)
+	redeemSwapCmd := flag.NewFlagSet("redeemswap", flag.ExitOnError)
+	refundSwapCmd := flag.NewFlagSet("refundswap", flag.ExitOnError)
+	extractSecretCmd := flag.NewFlagSet("extractsecret", flag.ExitOnError)
+	notarizeCmd := flag.NewFlagSet("notarize", flag.ExitOnError)
+	proveFileCmd := flag.NewFlagSet("provefile", flag.ExitOnError)
+	verifyFileProofCmd := flag.NewFlagSet("verifyfileproof", flag.ExitOnError)
+	bumpFeeCmd := flag.NewFlagSet("bumpfee", flag.ExitOnError)
+	getWalletBalanceCmd := flag.NewFlagSet("getwalletbalance", flag.ExitOnError)
+	listUnspentCmd := flag.NewFlagSet("listunspent", flag.ExitOnError)
+	printChainCmd := flag.NewFlagSet("printchain", flag.ExitOnError)
+	createWalletCmd := flag.NewFlagSet("createwallet", flag.ExitOnError)
 	listAddressesCmd := flag.NewFlagSet("listaddresses", flag.ExitOnError)
+	importAddressCmd := flag.NewFlagSet("importaddress", flag.ExitOnError)
+	historyCmd := flag.NewFlagSet("history", flag.ExitOnError)
 	reindexUTXOCmd := flag.NewFlagSet("reindexutxo", flag.ExitOnError)
+	getBlockTemplateCmd := flag.NewFlagSet("getblocktemplate", flag.ExitOnError)
 	startNodeCmd := flag.NewFlagSet("startnode", flag.ExitOnError)
+	dbInfoCmd := flag.NewFlagSet("dbinfo", flag.ExitOnError)
+	generateCmd := flag.NewFlagSet("generate", flag.ExitOnError)
+	encryptWalletCmd := flag.NewFlagSet("encryptwallet", flag.ExitOnError)
+	walletPassphraseCmd := flag.NewFlagSet("walletpassphrase", flag.ExitOnError)
+	walletLockCmd := flag.NewFlagSet("walletlock", flag.ExitOnError)
+	restoreWalletCmd := flag.NewFlagSet("restorewallet", flag.ExitOnError)
+	dumpPrivKeyCmd := flag.NewFlagSet("dumpprivkey", flag.ExitOnError)
+	importPrivKeyCmd := flag.NewFlagSet("importprivkey", flag.ExitOnError)
+	getPubKeyCmd := flag.NewFlagSet("getpubkey", flag.ExitOnError)
+	createMultiSigCmd := flag.NewFlagSet("createmultisig", flag.ExitOnError)
+	signMessageCmd := flag.NewFlagSet("signmessage", flag.ExitOnError)
+	verifyMessageCmd := flag.NewFlagSet("verifymessage", flag.ExitOnError)
+	exportChainCmd := flag.NewFlagSet("exportchain", flag.ExitOnError)
+	importChainCmd := flag.NewFlagSet("importchain", flag.ExitOnError)
 
 	// 명령어에 대한 옵션을 정의
 	getBalanceAddress := getBalanceCmd.String("address", "", "The address to get balance for")
-	createBlockchainAddress := createBlockchainCmd.String("address", "", "The address to send genesis block reward to")
-	sendFrom := sendCmd.String("from", "", "Source wallet address")
+	createBlockchainAddress := createBlockchainCmd.String("address", "", "The address to send the first block reward to (optional)")
+	sendFrom := sendCmd.String("from", "", "Comma separated source wallet addresses, or any")
+	sendUTXOs := sendCmd.String("utxos", "", "Comma separated TXID:VOUT outputs to spend")
 	sendTo := sendCmd.String("to", "", "Destination wallet address")
 	sendAmount := sendCmd.Int("amount", 0, "Amount to send")
+	sendStrategy := sendCmd.String("strategy", "bnb", "Coin selection strategy: bnb, largest, smallest or random")
+	sendFeeRate := sendCmd.Int("feerate", 0, "Fee per 1000 bytes of transaction")
+	sendLockTime := sendCmd.Uint("locktime", 0, "Earliest block height (or unix time if at least 500000000) the transaction can be mined at")
+	sendRBF := sendCmd.Bool("rbf", false, "Signal that the transaction can be replaced with a higher fee by bumpfee")
+	sendData := sendCmd.String("data", "", "Hex data to record in an unspendable output")
 	sendMine := sendCmd.Bool("mine", false, "Mine immediately on the same node")
+	sendManyFrom := sendManyCmd.String("from", "", "Comma separated source wallet addresses, or any")
+	sendManyTo := sendManyCmd.String("to", "", "Comma separated ADDRESS=AMOUNT payments")
+	sendManyFile := sendManyCmd.String("file", "", "JSON or CSV file with the payments")
+	sendManyUTXOs := sendManyCmd.String("utxos", "", "Comma separated TXID:VOUT outputs to spend")
+	sendManyStrategy := sendManyCmd.String("strategy", "bnb", "Coin selection strategy: bnb, largest, smallest or random")
+	sendManyFeeRate := sendManyCmd.Int("feerate", 0, "Fee per 1000 bytes of transaction")
+	sendManyRBF := sendManyCmd.Bool("rbf", false, "Signal that the transaction can be replaced with a higher fee by bumpfee")
+	sendManyMine := sendManyCmd.Bool("mine", false, "Mine immediately on the same node")
+	createRawTxFrom := createRawTxCmd.String("from", "", "Comma separated source wallet addresses, or any")
+	createRawTxTo := createRawTxCmd.String("to", "", "Comma separated ADDRESS=AMOUNT payments")
+	createRawTxUTXOs := createRawTxCmd.String("utxos", "", "Comma separated TXID:VOUT outputs to spend")
+	createRawTxStrategy := createRawTxCmd.String("strategy", "bnb", "Coin selection strategy: bnb, largest, smallest or random")
+	createRawTxFeeRate := createRawTxCmd.Int("feerate", 0, "Fee per 1000 bytes of transaction")
+	createRawTxOut := createRawTxCmd.String("out", "", "The file to write the unsigned transaction to")
+	signRawTxIn := signRawTxCmd.String("in", "", "The transaction file to sign")
+	signRawTxOut := signRawTxCmd.String("out", "", "The file to write the signed transaction to (default: overwrite -in)")
+	sendRawTxIn := sendRawTxCmd.String("in", "", "The signed transaction file to broadcast")
+	sendRawTxMine := sendRawTxCmd.Bool("mine", false, "Mine immediately on the same node")
+	createPSBTInputs := createPSBTCmd.String("inputs", "", "Comma separated TXID:VOUT outputs to spend")
+	createPSBTTo := createPSBTCmd.String("to", "", "Comma separated ADDRESS=AMOUNT outputs, including change")
+	createPSBTOut := createPSBTCmd.String("out", "", "The file to write the partially signed transaction to")
+	signPSBTIn := signPSBTCmd.String("in", "", "The partially signed transaction file")
+	signPSBTOut := signPSBTCmd.String("out", "", "The file to write the result to (default: overwrite -in)")
+	combinePSBTIn := combinePSBTCmd.String("in", "", "Comma separated partially signed transaction files")
+	combinePSBTOut := combinePSBTCmd.String("out", "", "The file to write the combined transaction to")
+	decodePSBTIn := decodePSBTCmd.String("in", "", "The partially signed transaction file")
+	finalizePSBTIn := finalizePSBTCmd.String("in", "", "The fully signed transaction file")
+	finalizePSBTMine := finalizePSBTCmd.Bool("mine", false, "Mine immediately on the same node")
+	initiateSwapFrom := initiateSwapCmd.String("from", "", "The address to fund the contract from and refund to")
+	initiateSwapTo := initiateSwapCmd.String("to", "", "The participant's address that can redeem the contract")
+	initiateSwapAmount := initiateSwapCmd.Int("amount", 0, "Amount to lock in the contract")
+	initiateSwapLockTime := initiateSwapCmd.Uint("locktime", 0, "Block height (or unix time if at least 500000000) after which the contract can be refunded")
+	initiateSwapFeeRate := initiateSwapCmd.Int("feerate", 0, "Fee per 1000 bytes of transaction")
+	initiateSwapMine := initiateSwapCmd.Bool("mine", false, "Mine immediately on the same node")
+	participateSwapFrom := participateSwapCmd.String("from", "", "The address to fund the contract from and refund to")
+	participateSwapTo := participateSwapCmd.String("to", "", "The initiator's address that can redeem the contract")
+	participateSwapAmount := participateSwapCmd.Int("amount", 0, "Amount to lock in the contract")
+	participateSwapSecretHash := participateSwapCmd.String("secrethash", "", "The secret hash from the initiator's contract")
+	participateSwapLockTime := participateSwapCmd.Uint("locktime", 0, "Block height (or unix time if at least 500000000) after which the contract can be refunded")
+	participateSwapFeeRate := participateSwapCmd.Int("feerate", 0, "Fee per 1000 bytes of transaction")
+	participateSwapMine := participateSwapCmd.Bool("mine", false, "Mine immediately on the same node")
+	auditSwapContract := auditSwapCmd.String("contract", "", "The hex swap contract")
+	redeemSwapContract := redeemSwapCmd.String("contract", "", "The hex swap contract")
+	redeemSwapSecret := redeemSwapCmd.String("secret", "", "The hex secret")
+	redeemSwapTo := redeemSwapCmd.String("to", "", "The address to send the coins to (default: the contract's recipient)")
+	redeemSwapFeeRate := redeemSwapCmd.Int("feerate", 0, "Fee per 1000 bytes of transaction")
+	redeemSwapMine := redeemSwapCmd.Bool("mine", false, "Mine immediately on the same node")
+	refundSwapContract := refundSwapCmd.String("contract", "", "The hex swap contract")
+	refundSwapTo := refundSwapCmd.String("to", "", "The address to send the coins to (default: the contract's refund address)")
+	refundSwapFeeRate := refundSwapCmd.Int("feerate", 0, "Fee per 1000 bytes of transaction")
+	refundSwapMine := refundSwapCmd.Bool("mine", false, "Mine immediately on the same node")
+	extractSecretContract := extractSecretCmd.String("contract", "", "The hex swap contract")
+	notarizeFile := notarizeCmd.String("file", "", "The file to anchor")
+	notarizeFrom := notarizeCmd.String("from", "", "Comma separated wallet addresses paying the fee, or any")
+	notarizeFeeRate := notarizeCmd.Int("feerate", 0, "Fee per 1000 bytes of transaction")
+	notarizeMine := notarizeCmd.Bool("mine", false, "Mine immediately on the same node")
+	proveFileFile := proveFileCmd.String("file", "", "The notarized file")
+	proveFileOut := proveFileCmd.String("out", "", "The file to write the proof to")
+	verifyFileProofFile := verifyFileProofCmd.String("file", "", "The notarized file")
+	verifyFileProofProof := verifyFileProofCmd.String("proof", "", "The proof file")
+	bumpFeeTxID := bumpFeeCmd.String("txid", "", "The unconfirmed wallet transaction to replace")
+	bumpFeeFeeRate := bumpFeeCmd.Int("feerate", 0, "Fee per 1000 bytes of the replacement transaction")
+	bumpFeeMine := bumpFeeCmd.Bool("mine", false, "Mine immediately on the same node")
+	getBlockTemplateNode := getBlockTemplateCmd.String("node", "", "Address of the running node")
 	startNodeMiner := startNodeCmd.String("miner", "", "Enable mining mode and send reward to ADDRESS")
+	walletPassphraseTimeout := walletPassphraseCmd.Int("timeout", 60, "Seconds to keep the wallet unlocked")
+	restoreWalletMnemonic := restoreWalletCmd.String("mnemonic", "", "The mnemonic to restore the wallet from")
+	restoreWalletGapLimit := restoreWalletCmd.Int("gaplimit", wallet.DefaultGapLimit, "Stop scanning after this many consecutive unused addresses")
+	importAddressAddress := importAddressCmd.String("address", "", "The address to watch")
+	importAddressPubKeyHash := importAddressCmd.String("pubkeyhash", "", "The hex public key hash to watch")
+	dumpPrivKeyAddress := dumpPrivKeyCmd.String("address", "", "The address to print the private key for")
+	getPubKeyAddress := getPubKeyCmd.String("address", "", "The address to print the public key for")
+	createMultiSigM := createMultiSigCmd.Int("m", 0, "Number of signatures required to spend")
+	createMultiSigPubKeys := createMultiSigCmd.String("pubkeys", "", "Comma separated hex public keys or wallet addresses")
+	signMessageAddress := signMessageCmd.String("address", "", "The address to sign the message with")
+	signMessageMessage := signMessageCmd.String("message", "", "The message to sign")
+	verifyMessageAddress := verifyMessageCmd.String("address", "", "The address that signed the message")
+	verifyMessageSignature := verifyMessageCmd.String("signature", "", "The signature printed by signmessage")
+	verifyMessageMessage := verifyMessageCmd.String("message", "", "The signed message")
+	importPrivKeyKey := importPrivKeyCmd.String("key", "", "The private key to import")
+	importPrivKeyRescan := importPrivKeyCmd.Bool("rescan", false, "Scan the chain for outputs of the imported key")
+	generateCount := generateCmd.Int("n", 1, "Number of blocks to mine")
+	generateAddress := generateCmd.String("address", "", "The address to send block rewards to")
+	exportChainOut := exportChainCmd.String("out", "", "The bootstrap file to write")
+	exportChainFrom := exportChainCmd.Int("from", 0, "The first block height to export")
+	exportChainTo := exportChainCmd.Int("to", -1, "The last block height to export (default: chain tip)")
+	importChainIn := importChainCmd.String("in", "", "The bootstrap file to read")
 
 	// 첫 번째 명령어에 따라 분기
 	switch os.Args[1] {
@@ -247,17 +1319,197 @@ func (cli *CommandLine) Run() {
 		if err != nil {
 			log.Panic(err)
 		}
+	case "dbinfo":
+		err := dbInfoCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "encryptwallet":
+		err := encryptWalletCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "walletpassphrase":
+		err := walletPassphraseCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "walletlock":
+		err := walletLockCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "restorewallet":
+		err := restoreWalletCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "dumpprivkey":
+		err := dumpPrivKeyCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "getpubkey":
+		err := getPubKeyCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "createmultisig":
+		err := createMultiSigCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "signmessage":
+		err := signMessageCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "verifymessage":
+		err := verifyMessageCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "importprivkey":
+		err := importPrivKeyCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "generate":
+		err := generateCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "exportchain":
+		err := exportChainCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "importchain":
+		err := importChainCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
 	case "getbalance":
 		// getbalance 명령을 파싱하고 에러 처리(옵션으로 들어온 값을 옵션변수(getBalanceAddress)에 알맞게 할당)
 		err := getBalanceCmd.Parse(os.Args[2:])
 		if err != nil {
 			log.Panic(err)
 		}
+	case "sendmany":
+		err := sendManyCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "createrawtx":
+		err := createRawTxCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "signrawtx":
+		err := signRawTxCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "sendrawtx":
+		err := sendRawTxCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "createpsbt":
+		err := createPSBTCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "signpsbt":
+		err := signPSBTCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "combinepsbt":
+		err := combinePSBTCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "decodepsbt":
+		err := decodePSBTCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "finalizepsbt":
+		err := finalizePSBTCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "initiateswap":
+		err := initiateSwapCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "participateswap":
+		err := participateSwapCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "auditswap":
+		err := auditSwapCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "redeemswap":
+		err := redeemSwapCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "refundswap":
+		err := refundSwapCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "extractsecret":
+		err := extractSecretCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "notarize":
+		err := notarizeCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "provefile":
+		err := proveFileCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "verifyfileproof":
+		err := verifyFileProofCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "bumpfee":
+		err := bumpFeeCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "getwalletbalance":
+		err := getWalletBalanceCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "listunspent":
+		err := listUnspentCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
 	case "createblockchain":
 		err := createBlockchainCmd.Parse(os.Args[2:])
 		if err != nil {
 			log.Panic(err)
 		}
+	case "getblocktemplate":
+		err := getBlockTemplateCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
 	case "startnode":
 		err := startNodeCmd.Parse(os.Args[2:])
 		if err != nil {
@@ -268,6 +1520,16 @@ func (cli *CommandLine) Run() {
 		if err != nil {
 			log.Panic(err)
 		}
+	case "importaddress":
+		err := importAddressCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
+	case "history":
+		err := historyCmd.Parse(os.Args[2:])
+		if err != nil {
+			log.Panic(err)
+		}
 	case "createwallet":
 		err := createWalletCmd.Parse(os.Args[2:])
 		if err != nil {
@@ -297,11 +1559,15 @@ func (cli *CommandLine) Run() {
 		cli.getBalance(*getBalanceAddress, nodeId)
 	}
 
+	if getWalletBalanceCmd.Parsed() {
+		cli.getWalletBalance(nodeId)
+	}
+
+	if listUnspentCmd.Parsed() {
+		cli.listUnspent(nodeId)
+	}
+
 	if createBlockchainCmd.Parsed() {
-		if *createBlockchainAddress == "" {
-			createBlockchainCmd.Usage()
-			runtime.Goexit()
-		}
 		cli.createBlockChain(*createBlockchainAddress, nodeId)
 	}
 
@@ -316,17 +1582,320 @@ func (cli *CommandLine) Run() {
 	if listAddressesCmd.Parsed() {
 		cli.listAddresses(nodeId)
 	}
+	if importAddressCmd.Parsed() {
+		if (*importAddressAddress == "") == (*importAddressPubKeyHash == "") {
+			importAddressCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.importAddress(*importAddressAddress, *importAddressPubKeyHash, nodeId)
+	}
+	if historyCmd.Parsed() {
+		cli.history(nodeId)
+	}
 	if reindexUTXOCmd.Parsed() {
 		cli.reindexUTXO(nodeId)
 	}
+	if dbInfoCmd.Parsed() {
+		cli.dbInfo(nodeId)
+	}
+
+	if encryptWalletCmd.Parsed() {
+		cli.encryptWallet(nodeId)
+	}
+
+	if walletPassphraseCmd.Parsed() {
+		if *walletPassphraseTimeout <= 0 {
+			walletPassphraseCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.walletPassphrase(*walletPassphraseTimeout, nodeId)
+	}
+
+	if walletLockCmd.Parsed() {
+		cli.walletLock(nodeId)
+	}
+
+	if restoreWalletCmd.Parsed() {
+		if *restoreWalletMnemonic == "" || *restoreWalletGapLimit <= 0 {
+			restoreWalletCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.restoreWallet(*restoreWalletMnemonic, *restoreWalletGapLimit, nodeId)
+	}
+
+	if dumpPrivKeyCmd.Parsed() {
+		if *dumpPrivKeyAddress == "" {
+			dumpPrivKeyCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.dumpPrivKey(*dumpPrivKeyAddress, nodeId)
+	}
+
+	if getPubKeyCmd.Parsed() {
+		if *getPubKeyAddress == "" {
+			getPubKeyCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.getPubKey(*getPubKeyAddress, nodeId)
+	}
+
+	if createMultiSigCmd.Parsed() {
+		if *createMultiSigM <= 0 || *createMultiSigPubKeys == "" {
+			createMultiSigCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.createMultiSig(*createMultiSigM, *createMultiSigPubKeys, nodeId)
+	}
+
+	if signMessageCmd.Parsed() {
+		if *signMessageAddress == "" {
+			signMessageCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.signMessage(*signMessageAddress, *signMessageMessage, nodeId)
+	}
+
+	if verifyMessageCmd.Parsed() {
+		if *verifyMessageAddress == "" || *verifyMessageSignature == "" {
+			verifyMessageCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.verifyMessage(*verifyMessageAddress, *verifyMessageSignature, *verifyMessageMessage)
+	}
+
+	if importPrivKeyCmd.Parsed() {
+		if *importPrivKeyKey == "" {
+			importPrivKeyCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.importPrivKey(*importPrivKeyKey, *importPrivKeyRescan, nodeId)
+	}
+
+	if generateCmd.Parsed() {
+		if *generateAddress == "" || *generateCount <= 0 {
+			generateCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.generate(*generateCount, *generateAddress, nodeId)
+	}
+
+	if exportChainCmd.Parsed() {
+		if *exportChainOut == "" {
+			exportChainCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.exportChain(*exportChainOut, *exportChainFrom, *exportChainTo, nodeId)
+	}
+
+	if importChainCmd.Parsed() {
+		if *importChainIn == "" {
+			importChainCmd.Usage()
+			runtime.Goexit()
+		}
+		cli.importChain(*importChainIn, nodeId)
+	}
 
 	if sendCmd.Parsed() {
-		if *sendFrom == "" || *sendTo == "" || *sendAmount <= 0 {
+		if (*sendFrom == "" && *sendUTXOs == "") || *sendTo == "" || *sendAmount <= 0 || *sendFeeRate < 0 || *sendLockTime > math.MaxUint32 {
 			sendCmd.Usage()
 			runtime.Goexit()
 		}
 
-		cli.send(*sendFrom, *sendTo, *sendAmount, nodeId, *sendMine)
+		var data []byte
+		if *sendData != "" {
+			var err error
+			if data, err = hex.DecodeString(*sendData); err != nil || len(data) > blockchain.MaxDataCarrierSize {
+				log.Panicf("Data must be hex of at most %d bytes", blockchain.MaxDataCarrierSize)
+			}
+		}
+		cli.send(*sendFrom, *sendUTXOs, *sendTo, *sendAmount, *sendStrategy, *sendFeeRate, uint32(*sendLockTime), *sendRBF, data, nodeId, *sendMine)
+	}
+
+	if sendManyCmd.Parsed() {
+		if (*sendManyFrom == "" && *sendManyUTXOs == "") || (*sendManyTo == "") == (*sendManyFile == "") || *sendManyFeeRate < 0 {
+			sendManyCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.sendMany(*sendManyFrom, *sendManyTo, *sendManyFile, *sendManyUTXOs, *sendManyStrategy, *sendManyFeeRate, *sendManyRBF, nodeId, *sendManyMine)
+	}
+
+	if createRawTxCmd.Parsed() {
+		if (*createRawTxFrom == "" && *createRawTxUTXOs == "") || *createRawTxTo == "" || *createRawTxOut == "" || *createRawTxFeeRate < 0 {
+			createRawTxCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.createRawTx(*createRawTxFrom, *createRawTxTo, *createRawTxUTXOs, *createRawTxStrategy, *createRawTxFeeRate, *createRawTxOut, nodeId)
+	}
+
+	if signRawTxCmd.Parsed() {
+		if *signRawTxIn == "" {
+			signRawTxCmd.Usage()
+			runtime.Goexit()
+		}
+		if *signRawTxOut == "" {
+			*signRawTxOut = *signRawTxIn
+		}
+
+		cli.signRawTx(*signRawTxIn, *signRawTxOut, nodeId)
+	}
+
+	if sendRawTxCmd.Parsed() {
+		if *sendRawTxIn == "" {
+			sendRawTxCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.sendRawTx(*sendRawTxIn, nodeId, *sendRawTxMine)
+	}
+
+	if createPSBTCmd.Parsed() {
+		if *createPSBTInputs == "" || *createPSBTTo == "" || *createPSBTOut == "" {
+			createPSBTCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.createPSBT(*createPSBTInputs, *createPSBTTo, *createPSBTOut, nodeId)
+	}
+
+	if signPSBTCmd.Parsed() {
+		if *signPSBTIn == "" {
+			signPSBTCmd.Usage()
+			runtime.Goexit()
+		}
+		if *signPSBTOut == "" {
+			*signPSBTOut = *signPSBTIn
+		}
+
+		cli.signPSBT(*signPSBTIn, *signPSBTOut, nodeId)
+	}
+
+	if combinePSBTCmd.Parsed() {
+		if *combinePSBTIn == "" || *combinePSBTOut == "" {
+			combinePSBTCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.combinePSBT(*combinePSBTIn, *combinePSBTOut)
+	}
+
+	if decodePSBTCmd.Parsed() {
+		if *decodePSBTIn == "" {
+			decodePSBTCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.decodePSBT(*decodePSBTIn)
+	}
+
+	if finalizePSBTCmd.Parsed() {
+		if *finalizePSBTIn == "" {
+			finalizePSBTCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.finalizePSBT(*finalizePSBTIn, nodeId, *finalizePSBTMine)
+	}
+
+	if initiateSwapCmd.Parsed() {
+		if *initiateSwapFrom == "" || *initiateSwapTo == "" || *initiateSwapAmount <= 0 || *initiateSwapFeeRate < 0 ||
+			*initiateSwapLockTime == 0 || *initiateSwapLockTime > math.MaxUint32 {
+			initiateSwapCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.initiateSwap(*initiateSwapFrom, *initiateSwapTo, *initiateSwapAmount, uint32(*initiateSwapLockTime), *initiateSwapFeeRate, nodeId, *initiateSwapMine)
+	}
+
+	if participateSwapCmd.Parsed() {
+		if *participateSwapFrom == "" || *participateSwapTo == "" || *participateSwapAmount <= 0 || *participateSwapSecretHash == "" ||
+			*participateSwapFeeRate < 0 || *participateSwapLockTime == 0 || *participateSwapLockTime > math.MaxUint32 {
+			participateSwapCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.participateSwap(*participateSwapFrom, *participateSwapTo, *participateSwapAmount, *participateSwapSecretHash, uint32(*participateSwapLockTime), *participateSwapFeeRate, nodeId, *participateSwapMine)
+	}
+
+	if auditSwapCmd.Parsed() {
+		if *auditSwapContract == "" {
+			auditSwapCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.auditSwap(*auditSwapContract, nodeId)
+	}
+
+	if redeemSwapCmd.Parsed() {
+		if *redeemSwapContract == "" || *redeemSwapSecret == "" || *redeemSwapFeeRate < 0 {
+			redeemSwapCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.redeemSwap(*redeemSwapContract, *redeemSwapSecret, *redeemSwapTo, *redeemSwapFeeRate, nodeId, *redeemSwapMine)
+	}
+
+	if refundSwapCmd.Parsed() {
+		if *refundSwapContract == "" || *refundSwapFeeRate < 0 {
+			refundSwapCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.refundSwap(*refundSwapContract, *refundSwapTo, *refundSwapFeeRate, nodeId, *refundSwapMine)
+	}
+
+	if extractSecretCmd.Parsed() {
+		if *extractSecretContract == "" {
+			extractSecretCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.extractSecret(*extractSecretContract, nodeId)
+	}
+
+	if notarizeCmd.Parsed() {
+		if *notarizeFile == "" || *notarizeFeeRate < 0 {
+			notarizeCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.notarize(*notarizeFile, *notarizeFrom, *notarizeFeeRate, nodeId, *notarizeMine)
+	}
+
+	if proveFileCmd.Parsed() {
+		if *proveFileFile == "" || *proveFileOut == "" {
+			proveFileCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.proveFile(*proveFileFile, *proveFileOut, nodeId)
+	}
+
+	if verifyFileProofCmd.Parsed() {
+		if *verifyFileProofFile == "" || *verifyFileProofProof == "" {
+			verifyFileProofCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.verifyFileProof(*verifyFileProofFile, *verifyFileProofProof, nodeId)
+	}
+
+	if bumpFeeCmd.Parsed() {
+		if *bumpFeeTxID == "" || *bumpFeeFeeRate < 0 {
+			bumpFeeCmd.Usage()
+			runtime.Goexit()
+		}
+
+		cli.bumpFee(*bumpFeeTxID, *bumpFeeFeeRate, nodeId, *bumpFeeMine)
+	}
+
+	if getBlockTemplateCmd.Parsed() {
+		if *getBlockTemplateNode == "" {
+			*getBlockTemplateNode = fmt.Sprintf("localhost:%s", nodeId)
+		}
+
+		cli.getBlockTemplate(*getBlockTemplateNode)
 	}
 
 	if startNodeCmd.Parsed() {
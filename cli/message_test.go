@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSignAndVerifyMessage(t *testing.T) {
+	cli := &CommandLine{}
+	node := newTestNode(t, "message")
+	other := newTestNode(t, "message2")
+
+	alice, bob := node.newAddress(t), node.newAddress(t)
+	stranger := other.newAddress(t)
+
+	const message = "I own this address"
+	node.use(t)
+	signature := strings.TrimSpace(captureOutput(t, func() { cli.signMessage(alice, message, node.id) }))
+
+	// 서명 검증은 지갑 없이 주소와 메시지만으로 가능
+	verify := func(address, signature, message string) string {
+		return strings.TrimSpace(captureOutput(t, func() { cli.verifyMessage(address, signature, message) }))
+	}
+	tests := []struct {
+		name      string
+		address   string
+		signature string
+		message   string
+		want      string
+	}{
+		{"signer and message", alice, signature, message, "true"},
+		{"other message", alice, signature, message + ".", "false"},
+		{"other address", bob, signature, message, "false"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := verify(test.address, test.signature, test.message); got != test.want {
+				t.Fatalf("verifymessage printed %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	expectPanic(t, "verifymessage with a malformed signature", func() { cli.verifyMessage(alice, "not a signature", message) })
+
+	// 개인 키가 없는 주소로는 서명할 수 없음
+	node.use(t)
+	expectPanic(t, "signmessage with an address of another wallet", func() { cli.signMessage(stranger, message, node.id) })
+	node.use(t)
+	cli.importAddress(stranger, "", node.id)
+	node.use(t)
+	expectPanic(t, "signmessage with a watch-only address", func() { cli.signMessage(stranger, message, node.id) })
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+)
+
+// 파일의 SHA-256 해시를 데이터 출력에 기록하는 트랜잭션을 생성하여 체인에 남김
+func (cli *CommandLine) notarize(path, from string, feeRate int, nodeId string, mineNow bool) {
+	hash := hashFile(path)
+
+	// 지불 없이 수수료와 잔돈만 있는 트랜잭션에 파일 해시를 기록
+	tx := cli.sendPayments(from, "", nil, "bnb", feeRate, 0, false, hash, nodeId, mineNow)
+
+	fmt.Printf("File hash: %x\n", hash)
+	fmt.Printf("Anchored in transaction %x\n", tx.ID)
+}
+
+// 파일 해시를 기록한 트랜잭션이 블록에 포함되었다는 Merkle 증명을 파일로 저장
+func (cli *CommandLine) proveFile(path, out, nodeId string) {
+	hash := hashFile(path)
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+
+	proof, err := chain.NewMerkleProof(hash)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	content := hex.EncodeToString(proof.Serialize()) + "\n"
+	if err := os.WriteFile(out, []byte(content), 0644); err != nil {
+		log.Panic(err)
+	}
+
+	fmt.Printf("Proof that %x is in block %d (%x) written to %s\n", hash, proof.Height, proof.BlockHash, out)
+}
+
+// 파일과 Merkle 증명을 확인하고 증명의 블록이 이 체인에 있는지 확인
+func (cli *CommandLine) verifyFileProof(path, proofPath, nodeId string) {
+	hash := hashFile(path)
+
+	content, err := os.ReadFile(proofPath)
+	if err != nil {
+		log.Panic(err)
+	}
+	data, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		log.Panic(err)
+	}
+	proof, err := blockchain.DeserializeMerkleProof(data)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+
+	confirmations, err := chain.CheckMerkleProof(proof, hash)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	fmt.Printf("File hash %x is anchored in transaction %x\n", hash, proof.Tx.ID)
+	fmt.Printf("Block %d (%x), %d confirmations\n", proof.Height, proof.BlockHash, confirmations)
+}
+
+// 파일의 SHA-256 해시를 계산하는 함수
+func hashFile(path string) []byte {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	hash := sha256.Sum256(content)
+
+	return hash[:]
+}
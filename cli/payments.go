@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+)
+
+// "주소=금액,주소=금액" 형식의 지불 목록을 파싱하는 함수
+func parsePayments(list string) ([]blockchain.Payment, error) {
+	var payments []blockchain.Payment
+	for _, item := range strings.Split(list, ",") {
+		parts := strings.Split(item, "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid payment %q, expected ADDRESS=AMOUNT", item)
+		}
+
+		amount, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount in payment %q", item)
+		}
+		payments = append(payments, blockchain.Payment{Address: parts[0], Amount: amount})
+	}
+
+	return payments, nil
+}
+
+// 파일에서 지불 목록을 읽는 함수
+// .json 파일은 [{"address": "...", "amount": 1}] 형식, 그 외에는 "주소,금액" 형식의 CSV
+func readPaymentsFile(path string) ([]blockchain.Payment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var payments []blockchain.Payment
+		if err := json.NewDecoder(file).Decode(&payments); err != nil {
+			return nil, err
+		}
+		if len(payments) == 0 {
+			return nil, fmt.Errorf("%s has no payments", path)
+		}
+		return payments, nil
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 2
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	var payments []blockchain.Payment
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		amount, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			// 첫 줄의 머리글은 건너뜀
+			if len(payments) == 0 && strings.EqualFold(record[0], "address") {
+				continue
+			}
+			return nil, fmt.Errorf("invalid amount %q in %s", record[1], path)
+		}
+		payments = append(payments, blockchain.Payment{Address: strings.TrimSpace(record[0]), Amount: amount})
+	}
+
+	if len(payments) == 0 {
+		return nil, fmt.Errorf("%s has no payments", path)
+	}
+
+	return payments, nil
+}
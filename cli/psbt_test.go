@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 주소로 잠긴 UTXO를 블록 높이 순서로 반환하는 함수
+func (n *testNode) unspent(t *testing.T, address string) []blockchain.UnspentOutput {
+	t.Helper()
+	n.use(t)
+
+	chain := blockchain.ContinueBlockChain(n.id)
+	defer chain.Database.Close()
+
+	utxos := (blockchain.UTXOSet{Blockchain: chain}).FindUnspentOutputs([][]byte{wallet.AddressToPubKeyHash(address)})
+	sort.Slice(utxos, func(i, j int) bool { return utxos[i].Height < utxos[j].Height })
+	return utxos
+}
+
+func TestPSBTSignCombineAndFinalize(t *testing.T) {
+	cli := &CommandLine{}
+	node := newTestNode(t, "psbt")
+
+	alice, bob, carol := node.newAddress(t), node.newAddress(t), node.newAddress(t)
+	node.use(t)
+	cli.createBlockChain(alice, node.id)
+	cli.generate(blockchain.ActiveParams.CoinbaseMaturity, alice, node.id)
+	node.use(t)
+	cli.send(alice, "", bob, 8, "largest", 0, 0, false, nil, node.id, true)
+
+	// Alice의 가장 오래된 출력과 Bob의 출력을 함께 사용하여 Carol에게 지불
+	aliceUTXO, bobUTXO := node.unspent(t, alice)[0], node.unspent(t, bob)[0]
+	inputs := fmt.Sprintf("%x:%d,%x:%d", aliceUTXO.TxID, aliceUTXO.Vout, bobUTXO.TxID, bobUTXO.Vout)
+	amount := aliceUTXO.Output.Value + bobUTXO.Output.Value - 2
+
+	dir := t.TempDir()
+	unsigned := filepath.Join(dir, "unsigned.psbt")
+	signed := filepath.Join(dir, "signed.psbt")
+	node.use(t)
+	cli.createPSBT(inputs, carol+"="+strconv.Itoa(amount), unsigned, node.id)
+
+	out := captureOutput(t, func() { cli.decodePSBT(unsigned) })
+	if strings.Count(out, "signatures: 0 of 1") != 2 {
+		t.Fatalf("decodepsbt does not show two unsigned inputs:\n%s", out)
+	}
+
+	// 파일의 이전 출력 금액을 바꾸면 UTXO 집합과 비교하여 서명하지 않음
+	tampered := readPSBT(unsigned)
+	tampered.Inputs[1].PrevOutput.Value += 100
+	writePSBT(filepath.Join(dir, "tampered.psbt"), tampered)
+	node.use(t)
+	expectPanic(t, "signpsbt with a forged input value", func() {
+		cli.signPSBT(filepath.Join(dir, "tampered.psbt"), filepath.Join(dir, "forged.psbt"), node.id)
+	})
+
+	// 서명 전에 UTXO 집합의 금액으로 계산한 수수료를 보여줌
+	node.use(t)
+	out = captureOutput(t, func() { cli.signPSBT(unsigned, signed, node.id) })
+	if got := outputField(t, out, "Fee"); got != "2" {
+		t.Fatalf("signpsbt shows fee %s, want 2", got)
+	}
+	good := readPSBT(signed)
+	if good.SignedCount() != 2 {
+		t.Fatalf("%d of 2 inputs are signed", good.SignedCount())
+	}
+
+	// 잘못된 서명이 저장된 파일
+	bad := readPSBT(unsigned)
+	bad.Inputs[0].PartialSigs = []blockchain.PartialSig{{PubKey: good.Inputs[0].PartialSigs[0].PubKey, Signature: make([]byte, 64)}}
+	writePSBT(filepath.Join(dir, "bad.psbt"), bad)
+
+	// 잘못된 서명은 합치지 않음
+	if err := readPSBT(signed).Combine(bad); !errors.Is(err, blockchain.ErrInvalidPartialSig) {
+		t.Fatalf("combining a bad signature: got %v, want %v", err, blockchain.ErrInvalidPartialSig)
+	}
+	expectPanic(t, "combinepsbt with a bad signature", func() {
+		cli.combinePSBT(signed+","+filepath.Join(dir, "bad.psbt"), filepath.Join(dir, "rejected.psbt"))
+	})
+
+	// 먼저 저장된 잘못된 서명이 같은 공개 키의 올바른 서명을 막지 않음
+	combined := filepath.Join(dir, "combined.psbt")
+	cli.combinePSBT(filepath.Join(dir, "bad.psbt")+","+signed, combined)
+	if got := readPSBT(combined).SignedCount(); got != 2 {
+		t.Fatalf("%d of 2 inputs are signed after combining", got)
+	}
+
+	node.use(t)
+	cli.finalizePSBT(combined, node.id, true)
+	if got := node.addressBalance(t, carol); got != amount {
+		t.Fatalf("carol received %d, want %d", got, amount)
+	}
+}
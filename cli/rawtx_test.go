@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+)
+
+func TestRawTransactionSignAndSend(t *testing.T) {
+	cli := &CommandLine{}
+	node := newTestNode(t, "rawtx")
+
+	alice, bob := node.newAddress(t), node.newAddress(t)
+	node.use(t)
+	cli.createBlockChain(alice, node.id)
+	cli.generate(blockchain.ActiveParams.CoinbaseMaturity, alice, node.id)
+
+	dir := t.TempDir()
+	unsigned := filepath.Join(dir, "unsigned.tx")
+	signed := filepath.Join(dir, "signed.tx")
+
+	node.use(t)
+	cli.createRawTx(alice, bob+"=5", "", "largest", 10, unsigned, node.id)
+
+	// 서명하지 않은 트랜잭션은 전송할 수 없음
+	node.use(t)
+	expectPanic(t, "sendrawtx with an unsigned transaction", func() { cli.sendRawTx(unsigned, node.id, true) })
+
+	// 파일의 이전 출력 금액을 바꾸면 서명 해시로는 알 수 없으므로 UTXO 집합과 비교하여 거부
+	raw := readRawTx(unsigned)
+	raw.PrevOutputs[0].Value += 100
+	tampered := filepath.Join(dir, "tampered.tx")
+	writeRawTx(tampered, raw)
+	node.use(t)
+	expectPanic(t, "signrawtx with a forged input value", func() { cli.signRawTx(tampered, filepath.Join(dir, "forged.tx"), node.id) })
+
+	// 서명 전에 UTXO 집합의 금액으로 계산한 수수료를 보여줌
+	raw = readRawTx(unsigned)
+	want := 0
+	for _, out := range raw.PrevOutputs {
+		want += out.Value
+	}
+	for _, out := range raw.Tx.Outputs {
+		want -= out.Value
+	}
+	node.use(t)
+	out := captureOutput(t, func() { cli.signRawTx(unsigned, signed, node.id) })
+	if got := outputField(t, out, "Fee"); got != strconv.Itoa(want) {
+		t.Fatalf("signrawtx shows fee %s, want %d", got, want)
+	}
+	if !readRawTx(signed).IsSigned() {
+		t.Fatal("signed transaction does not verify")
+	}
+
+	node.use(t)
+	cli.sendRawTx(signed, node.id, true)
+	if got := node.addressBalance(t, bob); got != 5 {
+		t.Fatalf("bob received %d, want 5", got)
+	}
+
+	// 이미 사용한 출력을 사용하는 트랜잭션 파일은 서명하지 않음
+	node.use(t)
+	expectPanic(t, "signrawtx after the inputs were spent", func() { cli.signRawTx(unsigned, filepath.Join(dir, "again.tx"), node.id) })
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 노드의 마지막 블록
+func (n *testNode) lastBlock(t *testing.T) *blockchain.Block {
+	t.Helper()
+	n.use(t)
+
+	chain := blockchain.ContinueBlockChain(n.id)
+	defer chain.Database.Close()
+
+	return chain.Iterator().Next()
+}
+
+// 주소로 잠긴 출력의 합계
+func (n *testNode) addressBalance(t *testing.T, address string) int {
+	t.Helper()
+
+	return n.balance(t, wallet.AddressToPubKeyHash(address))
+}
+
+func TestSendMany(t *testing.T) {
+	cli := &CommandLine{}
+	node := newTestNode(t, "sendmany")
+
+	alice, bob, carol := node.newAddress(t), node.newAddress(t), node.newAddress(t)
+	node.use(t)
+	cli.createBlockChain(alice, node.id)
+	cli.generate(blockchain.ActiveParams.CoinbaseMaturity, alice, node.id)
+
+	// 여러 수신자에게 하나의 트랜잭션으로 보내고 바로 채굴
+	aliceBalance := node.addressBalance(t, alice)
+	node.use(t)
+	cli.sendMany(alice, bob+"=3,"+carol+"=4", "", "", "largest", 10, false, node.id, true)
+
+	if got := node.addressBalance(t, bob); got != 3 {
+		t.Fatalf("bob received %d, want 3", got)
+	}
+	if got := node.addressBalance(t, carol); got != 4 {
+		t.Fatalf("carol received %d, want 4", got)
+	}
+
+	// 채굴 보상을 받는 발신자는 보조금과 자신이 낸 수수료를 모두 돌려받음
+	block := node.lastBlock(t)
+	if len(block.Transactions) != 2 || len(block.Transactions[1].Outputs) != 3 {
+		t.Fatalf("block has %d transactions, want a coinbase and one transaction with three outputs", len(block.Transactions))
+	}
+	fee := block.Transactions[0].Outputs[0].Value - blockchain.ActiveParams.Subsidy
+	if fee <= 0 {
+		t.Fatalf("coinbase pays %d, want the subsidy plus the transaction fee", block.Transactions[0].Outputs[0].Value)
+	}
+	if got, want := node.addressBalance(t, alice), aliceBalance-7+blockchain.ActiveParams.Subsidy; got != want {
+		t.Fatalf("alice's balance is %d, want %d (fee %d burned)", got, want, fee)
+	}
+
+	// 파일의 지불 목록으로도 보낼 수 있음
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "payments.csv")
+	jsonFile := filepath.Join(dir, "payments.json")
+	if err := os.WriteFile(csvFile, []byte("# address,amount\n"+bob+",1\n"+carol+",2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonFile, []byte(`[{"address": "`+bob+`", "amount": 5}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	node.use(t)
+	cli.sendMany(alice, "", csvFile, "", "largest", 10, false, node.id, true)
+	node.use(t)
+	cli.sendMany(alice, "", jsonFile, "", "largest", 10, false, node.id, true)
+
+	if got := node.addressBalance(t, bob); got != 3+1+5 {
+		t.Fatalf("bob has %d after file payments, want 9", got)
+	}
+	if got := node.addressBalance(t, carol); got != 4+2 {
+		t.Fatalf("carol has %d after file payments, want 6", got)
+	}
+
+	// 잘못된 지불 목록은 트랜잭션을 만들지 않음
+	height := node.height(t)
+	node.use(t)
+	expectPanic(t, "payment without an amount", func() { cli.sendMany(alice, bob, "", "", "largest", 10, false, node.id, true) })
+	node.use(t)
+	expectPanic(t, "payment to an invalid address", func() { cli.sendMany(alice, "nowhere=1", "", "", "largest", 10, false, node.id, true) })
+	node.use(t)
+	expectPanic(t, "payment of zero", func() { cli.sendMany(alice, bob+"=0", "", "", "largest", 10, false, node.id, true) })
+	if got := node.height(t); got != height {
+		t.Fatalf("height is %d after rejected payments, want %d", got, height)
+	}
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 비밀 값을 만들어 to 주소가 받을 수 있는 HTLC에 자금을 보내는 스왑 시작
+// 상대방은 비밀 값 해시로 다른 체인에 더 짧은 LockTime의 계약을 만들어 참여함
+func (cli *CommandLine) initiateSwap(from, to string, amount int, lockTime uint32, feeRate int, nodeId string, mineNow bool) {
+	secret := make([]byte, blockchain.HTLCSecretSize)
+	_, err := rand.Read(secret)
+	blockchain.Handle(err)
+	secretHash := sha256.Sum256(secret)
+
+	contract := cli.fundSwap(from, to, amount, secretHash[:], lockTime, feeRate, nodeId, mineNow)
+
+	fmt.Printf("Secret:      %x\n", secret)
+	fmt.Printf("Secret hash: %x\n", secretHash)
+	printContract(contract)
+}
+
+// 상대방이 시작한 스왑의 비밀 값 해시로 to 주소가 받을 수 있는 HTLC에 자금을 보내 스왑에 참여
+func (cli *CommandLine) participateSwap(from, to string, amount int, secretHash string, lockTime uint32, feeRate int, nodeId string, mineNow bool) {
+	hash, err := hex.DecodeString(secretHash)
+	if err != nil || len(hash) != sha256.Size {
+		log.Panic("Secret hash is not Valid")
+	}
+
+	contract := cli.fundSwap(from, to, amount, hash, lockTime, feeRate, nodeId, mineNow)
+
+	printContract(contract)
+}
+
+// HTLC를 만들고 from 주소의 자금을 계약의 스크립트 해시 주소로 보내는 함수
+// 비밀 값을 알면 to 주소가, lockTime 이후에는 from 주소가 받을 수 있음
+func (cli *CommandLine) fundSwap(from, to string, amount int, secretHash []byte, lockTime uint32, feeRate int, nodeId string, mineNow bool) []byte {
+	// 계약의 두 주소는 모두 개인 키로 서명하는 주소여야 함
+	for _, address := range []string{from, to} {
+		if !wallet.ValidateAddress(address) || wallet.IsScriptHashAddress(address) {
+			log.Panicf("Error: %s is not a key address", address)
+		}
+	}
+
+	c := &blockchain.HTLC{
+		SecretHash:          secretHash,
+		RecipientPubKeyHash: wallet.AddressToPubKeyHash(to),
+		RefundPubKeyHash:    wallet.AddressToPubKeyHash(from),
+		LockTime:            lockTime,
+	}
+	contract := c.Script()
+	contractAddress := string(wallet.ScriptHashToAddress(wallet.PublicKeyHash(contract)))
+
+	payments := []blockchain.Payment{{Address: contractAddress, Amount: amount}}
+	tx := cli.sendPayments(from, "", payments, "bnb", feeRate, 0, false, nil, nodeId, mineNow)
+
+	fmt.Printf("Contract transaction: %x\n", tx.ID)
+
+	return contract
+}
+
+// 계약 내용 출력
+func printContract(contract []byte) {
+	fmt.Printf("Contract:    %x\n", contract)
+	fmt.Printf("Contract address: %s\n", wallet.ScriptHashToAddress(wallet.PublicKeyHash(contract)))
+}
+
+// 상대방이 만든 계약의 내용과 이 체인에 있는 계약의 자금을 확인
+func (cli *CommandLine) auditSwap(contractHex, nodeId string) {
+	contract, c := parseContract(contractHex)
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Database.Close()
+
+	fmt.Printf("Contract address:  %s\n", wallet.ScriptHashToAddress(wallet.PublicKeyHash(contract)))
+	fmt.Printf("Recipient address: %s\n", wallet.PubKeyHashToAddress(c.RecipientPubKeyHash))
+	fmt.Printf("Refund address:    %s\n", wallet.PubKeyHashToAddress(c.RefundPubKeyHash))
+	fmt.Printf("Secret hash:       %x\n", c.SecretHash)
+	if c.LockTime >= blockchain.LockTimeThreshold {
+		fmt.Printf("Lock time:         %s\n", time.Unix(int64(c.LockTime), 0).Format(time.RFC3339))
+	} else {
+		fmt.Printf("Lock time:         block %d (current height %d)\n", c.LockTime, chain.GetBestHeight())
+	}
+
+	total := 0
+	for _, utxo := range UTXOSet.FindUnspentOutputs([][]byte{wallet.PublicKeyHash(contract)}) {
+		fmt.Printf("Output %s: %d\n", utxo.Outpoint(), utxo.Output.Value)
+		total += utxo.Output.Value
+	}
+	fmt.Printf("Contract value:    %d\n", total)
+}
+
+// 비밀 값으로 계약의 자금을 받음(to가 비어 있으면 계약의 수신자 주소로 받음)
+func (cli *CommandLine) redeemSwap(contractHex, secretHex, to string, feeRate int, nodeId string, mineNow bool) {
+	contract, c := parseContract(contractHex)
+
+	secret, err := hex.DecodeString(secretHex)
+	if err != nil || len(secret) != blockchain.HTLCSecretSize {
+		log.Panic("Secret is not Valid")
+	}
+
+	cli.spendSwap(contract, c.RecipientPubKeyHash, secret, to, feeRate, nodeId, mineNow)
+}
+
+// LockTime이 지난 계약의 자금을 돌려받음(to가 비어 있으면 계약의 환불 주소로 받음)
+func (cli *CommandLine) refundSwap(contractHex, to string, feeRate int, nodeId string, mineNow bool) {
+	contract, c := parseContract(contractHex)
+
+	cli.spendSwap(contract, c.RefundPubKeyHash, nil, to, feeRate, nodeId, mineNow)
+}
+
+// 계약의 모든 출력을 지갑에 있는 pubKeyHash의 개인 키로 서명하여 to 주소로 보내는 함수
+func (cli *CommandLine) spendSwap(contract, pubKeyHash, secret []byte, to string, feeRate int, nodeId string, mineNow bool) {
+	owner := string(wallet.PubKeyHashToAddress(pubKeyHash))
+	if to == "" {
+		to = owner
+	}
+	if !wallet.ValidateAddress(to) {
+		log.Panic("Address is not Valid")
+	}
+
+	wallets, err := wallet.CreateWallets(nodeId)
+	if err != nil {
+		log.Panic(err)
+	}
+	if wallets.IsLocked() {
+		log.Panic(wallet.ErrWalletLocked)
+	}
+	w, ok := wallets.Wallets[owner]
+	if !ok || wallets.IsWatchOnly(owner) {
+		log.Panicf("Error: %s is not in the wallet", owner)
+	}
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
+	defer chain.Database.Close()
+
+	utxos := UTXOSet.FindUnspentOutputs([][]byte{wallet.PublicKeyHash(contract)})
+	tx, err := blockchain.NewHTLCSpend(contract, utxos, to, w.DeserializePrivateKey(w.PrivateKey), secret, blockchain.FeeRate(feeRate))
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// 계약 주소가 아닌 받는 주소로 채굴 보상 지급
+	submitTransactionWithReward(chain, &UTXOSet, tx, to, mineNow)
+
+	fmt.Printf("Spent contract in transaction %x\n", tx.ID)
+}
+
+// 계약이 비밀 값으로 사용된 트랜잭션에서 비밀 값을 찾아 출력
+func (cli *CommandLine) extractSecret(contractHex, nodeId string) {
+	contract, _ := parseContract(contractHex)
+
+	chain := blockchain.ContinueBlockChain(nodeId)
+	defer chain.Database.Close()
+
+	secret, err := chain.FindHTLCSecret(contract)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	fmt.Printf("Secret: %x\n", secret)
+}
+
+// 16진수 계약을 파싱하는 함수
+func parseContract(contractHex string) ([]byte, *blockchain.HTLC) {
+	contract, err := hex.DecodeString(contractHex)
+	if err != nil {
+		log.Panic("Contract is not Valid")
+	}
+
+	c, err := blockchain.ExtractHTLC(contract)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	return contract, c
+}
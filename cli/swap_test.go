@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 스왑 테스트에서 사용하는 로컬 노드(NODE_ID와 데이터 디렉터리가 노드마다 다름)
+type testNode struct {
+	id      string
+	dataDir string
+}
+
+// 임시 데이터 디렉터리를 가진 regtest 노드를 만드는 함수
+func newTestNode(t *testing.T, id string) *testNode {
+	t.Helper()
+
+	if err := blockchain.SelectParams(blockchain.RegTestParams.Name); err != nil {
+		t.Fatal(err)
+	}
+	dataDir := blockchain.ActiveParams.DataDir
+	t.Cleanup(func() {
+		blockchain.ActiveParams.DataDir = dataDir
+		blockchain.SelectParams(blockchain.RegTestParams.Name)
+	})
+
+	return &testNode{id: id, dataDir: t.TempDir()}
+}
+
+// 블록체인과 지갑 파일이 노드의 데이터 디렉터리를 사용하도록 전환하는 함수
+func (n *testNode) use(t *testing.T) {
+	t.Helper()
+
+	blockchain.ActiveParams.DataDir = n.dataDir
+	if err := blockchain.SelectParams(blockchain.RegTestParams.Name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 노드의 지갑에 새 주소를 추가하는 함수
+func (n *testNode) newAddress(t *testing.T) string {
+	t.Helper()
+	n.use(t)
+
+	wallets, _ := wallet.CreateWallets(n.id)
+	address := wallets.AddWallet()
+	wallets.SaveFile(n.id)
+
+	return address
+}
+
+// 노드의 UTXO 집합에서 공개 키 해시 또는 스크립트 해시로 잠긴 출력의 합계를 구하는 함수
+func (n *testNode) balance(t *testing.T, pubKeyHash []byte) int {
+	t.Helper()
+	n.use(t)
+
+	chain := blockchain.ContinueBlockChain(n.id)
+	defer chain.Database.Close()
+
+	total := 0
+	for _, utxo := range (blockchain.UTXOSet{Blockchain: chain}).FindUnspentOutputs([][]byte{pubKeyHash}) {
+		total += utxo.Output.Value
+	}
+	return total
+}
+
+// 노드의 현재 블록 높이
+func (n *testNode) height(t *testing.T) int {
+	t.Helper()
+	n.use(t)
+
+	chain := blockchain.ContinueBlockChain(n.id)
+	defer chain.Database.Close()
+
+	return chain.GetBestHeight()
+}
+
+// 표준 출력으로 출력된 내용을 반환하는 함수
+func captureOutput(t *testing.T, f func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+
+	out := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		out <- buf.String()
+	}()
+
+	defer func() {
+		os.Stdout = stdout
+		w.Close()
+	}()
+	f()
+	w.Close()
+
+	return <-out
+}
+
+// 출력에서 "name: 16진수" 줄의 값을 찾는 함수
+func outputField(t *testing.T, output, name string) string {
+	t.Helper()
+
+	m := regexp.MustCompile(`(?m)^` + name + `:\s+([0-9a-f]+)$`).FindStringSubmatch(output)
+	if m == nil {
+		t.Fatalf("%s not found in output:\n%s", name, output)
+	}
+	return m[1]
+}
+
+// f가 패닉을 일으키는지 확인하는 함수
+func expectPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("%s succeeded, want failure", name)
+		}
+	}()
+	f()
+}
+
+// 16진수 문자열을 바이트로 변환하는 함수
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// 16진수 계약의 스크립트 해시
+func contractHash(t *testing.T, contractHex string) []byte {
+	t.Helper()
+
+	return wallet.PublicKeyHash(mustDecodeHex(t, contractHex))
+}
+
+func TestAtomicSwapAcrossTwoChains(t *testing.T) {
+	cli := &CommandLine{}
+	x := newTestNode(t, "swapx")
+	y := newTestNode(t, "swapy")
+
+	// 각 체인의 지갑에 두 참여자의 주소를 만들고 코인베이스 출력이 성숙할 때까지 채굴
+	aliceX, bobX := x.newAddress(t), x.newAddress(t)
+	aliceY, bobY := y.newAddress(t), y.newAddress(t)
+
+	maturity := blockchain.ActiveParams.CoinbaseMaturity
+	x.use(t)
+	cli.createBlockChain(aliceX, x.id)
+	cli.generate(maturity, aliceX, x.id)
+	y.use(t)
+	cli.createBlockChain(bobY, y.id)
+	cli.generate(maturity, bobY, y.id)
+
+	// Alice가 체인 X에서 스왑을 시작하고 Bob이 체인 Y에서 더 짧은 LockTime으로 참여
+	lockX := uint32(x.height(t) + 20)
+	x.use(t)
+	out := captureOutput(t, func() { cli.initiateSwap(aliceX, bobX, 10, lockX, 0, x.id, true) })
+	secret := outputField(t, out, "Secret")
+	secretHash := outputField(t, out, "Secret hash")
+	contractX := outputField(t, out, "Contract")
+
+	if hash := sha256.Sum256(mustDecodeHex(t, secret)); hex.EncodeToString(hash[:]) != secretHash {
+		t.Fatal("secret hash does not match the secret")
+	}
+	if got := x.balance(t, contractHash(t, contractX)); got != 10 {
+		t.Fatalf("contract on chain X holds %d, want 10", got)
+	}
+
+	lockY := uint32(y.height(t) + 10)
+	y.use(t)
+	out = captureOutput(t, func() { cli.participateSwap(bobY, aliceY, 8, secretHash, lockY, 0, y.id, true) })
+	contractY := outputField(t, out, "Contract")
+
+	if got := y.balance(t, contractHash(t, contractY)); got != 8 {
+		t.Fatalf("contract on chain Y holds %d, want 8", got)
+	}
+
+	// 잘못된 비밀 값으로는 받을 수 없음
+	wrongSecret := hex.EncodeToString(make([]byte, blockchain.HTLCSecretSize))
+	expectPanic(t, "redeem with a wrong secret", func() { cli.redeemSwap(contractY, wrongSecret, "", 0, y.id, true) })
+
+	// Alice가 비밀 값으로 체인 Y의 계약을 받음
+	aliceYBalance := y.balance(t, wallet.AddressToPubKeyHash(aliceY))
+	y.use(t)
+	cli.redeemSwap(contractY, secret, "", 0, y.id, true)
+
+	if got := y.balance(t, contractHash(t, contractY)); got != 0 {
+		t.Fatalf("contract on chain Y still holds %d after redeem", got)
+	}
+	if got := y.balance(t, wallet.AddressToPubKeyHash(aliceY)); got <= aliceYBalance {
+		t.Fatalf("alice's balance on chain Y is %d after redeem, want more than %d", got, aliceYBalance)
+	}
+
+	// Bob은 체인 Y의 사용 트랜잭션에서 비밀 값을 알아내어 체인 X의 계약을 받음
+	y.use(t)
+	out = captureOutput(t, func() { cli.extractSecret(contractY, y.id) })
+	if got := outputField(t, out, "Secret"); got != secret {
+		t.Fatalf("extracted secret %s, want %s", got, secret)
+	}
+
+	bobXBalance := x.balance(t, wallet.AddressToPubKeyHash(bobX))
+	x.use(t)
+	cli.redeemSwap(contractX, secret, "", 0, x.id, true)
+
+	if got := x.balance(t, contractHash(t, contractX)); got != 0 {
+		t.Fatalf("contract on chain X still holds %d after redeem", got)
+	}
+	if got := x.balance(t, wallet.AddressToPubKeyHash(bobX)); got <= bobXBalance {
+		t.Fatalf("bob's balance on chain X is %d after redeem, want more than %d", got, bobXBalance)
+	}
+}
+
+func TestAtomicSwapRefundAfterLockTime(t *testing.T) {
+	cli := &CommandLine{}
+	x := newTestNode(t, "refundx")
+
+	alice, bob := x.newAddress(t), x.newAddress(t)
+	x.use(t)
+	cli.createBlockChain(alice, x.id)
+	cli.generate(blockchain.ActiveParams.CoinbaseMaturity, alice, x.id)
+
+	lockTime := uint32(x.height(t) + 5)
+	x.use(t)
+	out := captureOutput(t, func() { cli.initiateSwap(alice, bob, 10, lockTime, 0, x.id, true) })
+	contract := outputField(t, out, "Contract")
+
+	// LockTime 전에는 환불할 수 없음
+	expectPanic(t, "refund before the lock time", func() { cli.refundSwap(contract, "", 0, x.id, true) })
+	if got := x.balance(t, contractHash(t, contract)); got != 10 {
+		t.Fatalf("contract holds %d after early refund attempt, want 10", got)
+	}
+
+	// LockTime이 지나도록 Bob에게 채굴하여 Alice의 잔액 변화가 환불만 반영되도록 함
+	x.use(t)
+	cli.generate(int(lockTime)-x.height(t)+1, bob, x.id)
+
+	aliceBalance := x.balance(t, wallet.AddressToPubKeyHash(alice))
+	x.use(t)
+	cli.refundSwap(contract, "", 0, x.id, true)
+
+	if got := x.balance(t, contractHash(t, contract)); got != 0 {
+		t.Fatalf("contract still holds %d after refund", got)
+	}
+	if got := x.balance(t, wallet.AddressToPubKeyHash(alice)); got <= aliceBalance {
+		t.Fatalf("alice's balance is %d after refund, want more than %d", got, aliceBalance)
+	}
+}
@@ -0,0 +1,139 @@
+package network
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+)
+
+// 블록에 포함되지 못한 트랜잭션이 메모리 풀에서 제거되기까지의 시간(입력이 계속 묶여 있지 않도록 함)
+const mempoolExpiry = 14 * 24 * time.Hour
+
+// 메모리 풀에 저장된 트랜잭션
+type mempoolEntry struct {
+	tx blockchain.Transaction
+	// 메모리 풀에 추가될 때 계산한 수수료
+	fee int
+	// 메모리 풀에 추가된 시간
+	added time.Time
+}
+
+// 메모리 풀에서 valid를 만족하는 트랜잭션을 뷰에 적용할 수 있는 순서로 적용하고 적용한 트랜잭션을 반환하는 함수
+// 메모리 풀의 다른 트랜잭션 출력을 사용하는 트랜잭션은 부모 트랜잭션이 적용된 뒤에 적용됨
+func connectPool(view *blockchain.UTXOView, valid func(*blockchain.Transaction) bool) []*blockchain.Transaction {
+	var connected []*blockchain.Transaction
+
+	pending := make(map[string]*blockchain.Transaction)
+	for id, entry := range memoryPool {
+		tx := entry.tx
+		if valid(&tx) {
+			pending[id] = &tx
+		}
+	}
+
+	// 더 이상 적용할 수 있는 트랜잭션이 없을 때까지 반복
+	for progress := true; progress; {
+		progress = false
+		for id, tx := range pending {
+			if _, err := view.Connect(tx); err == nil {
+				connected = append(connected, tx)
+				delete(pending, id)
+				progress = true
+			}
+		}
+	}
+
+	return connected
+}
+
+// 메모리 풀에서 tx의 입력과 같은 출력을 사용하는 트랜잭션을 찾는 함수
+func findConflicts(tx *blockchain.Transaction) map[string]bool {
+	spends := make(map[string]bool)
+	for _, in := range tx.Inputs {
+		spends[blockchain.UnspentOutput{TxID: in.ID, Vout: in.Out}.Outpoint()] = true
+	}
+
+	conflicts := make(map[string]bool)
+	for id, entry := range memoryPool {
+		for _, in := range entry.tx.Inputs {
+			if spends[blockchain.UnspentOutput{TxID: in.ID, Vout: in.Out}.Outpoint()] {
+				conflicts[id] = true
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// ids의 트랜잭션에 그 출력을 사용하는 메모리 풀의 모든 자손 트랜잭션을 더한 집합을 반환하는 함수
+func withDescendants(ids map[string]bool) map[string]bool {
+	all := make(map[string]bool)
+	for id := range ids {
+		all[id] = true
+	}
+
+	for progress := true; progress; {
+		progress = false
+		for id, entry := range memoryPool {
+			if all[id] {
+				continue
+			}
+			for _, in := range entry.tx.Inputs {
+				if all[hex.EncodeToString(in.ID)] {
+					all[id] = true
+					progress = true
+					break
+				}
+			}
+		}
+	}
+
+	return all
+}
+
+// 같은 출력을 사용하는 메모리 풀의 트랜잭션을 수수료가 fee인 tx로 교체할 수 있는지 확인하고 제거할 트랜잭션을 반환하는 함수
+func checkReplacement(tx *blockchain.Transaction, fee int, conflicts map[string]bool) (map[string]bool, error) {
+	var conflictTxs []*blockchain.Transaction
+	for id := range conflicts {
+		conflictTx := memoryPool[id].tx
+		conflictTxs = append(conflictTxs, &conflictTx)
+	}
+
+	// 충돌하는 트랜잭션의 자손도 사용하던 출력이 사라지므로 함께 제거
+	evicted := withDescendants(conflicts)
+	evictedFees := 0
+	for id := range evicted {
+		evictedFees += memoryPool[id].fee
+	}
+
+	return evicted, blockchain.CheckReplacement(tx, fee, conflictTxs, evictedFees)
+}
+
+// 만료된 트랜잭션과 블록에 포함되었거나 블록의 트랜잭션과 충돌하여 더 이상 유효하지 않은 트랜잭션을 메모리 풀에서 제거하는 함수
+func trimPool(chain *blockchain.BlockChain) {
+	expired := make(map[string]bool)
+	for id, entry := range memoryPool {
+		if time.Since(entry.added) > mempoolExpiry {
+			expired[id] = true
+		}
+	}
+	for id := range withDescendants(expired) {
+		fmt.Printf("Expiring transaction %s\n", id)
+		delete(memoryPool, id)
+	}
+
+	// UTXO 집합 위에 적용할 수 없는 트랜잭션 제거
+	view := blockchain.NewUTXOView(blockchain.UTXOSet{Blockchain: chain})
+	valid := make(map[string]bool)
+	for _, tx := range connectPool(view, func(*blockchain.Transaction) bool { return true }) {
+		valid[hex.EncodeToString(tx.ID)] = true
+	}
+	for id := range memoryPool {
+		if !valid[id] {
+			fmt.Printf("Removing transaction %s: it was mined or its inputs were spent\n", id)
+			delete(memoryPool, id)
+		}
+	}
+}
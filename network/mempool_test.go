@@ -0,0 +1,194 @@
+package network
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/Kim-DaeHan/go-blockchain/blockchain"
+	"github.com/Kim-DaeHan/go-blockchain/wallet"
+)
+
+// 임시 데이터 디렉터리에 regtest 체인을 만들고 빈 메모리 풀에서 시작하는 함수
+// 첫 번째 채굴 보상을 사용할 수 있을 때까지 블록을 생성하고 높이 1의 코인베이스 트랜잭션을 반환
+func newTestPool(t *testing.T, miner *wallet.Wallet) (*blockchain.BlockChain, *blockchain.Transaction) {
+	t.Helper()
+
+	if err := blockchain.SelectParams(blockchain.RegTestParams.Name); err != nil {
+		t.Fatal(err)
+	}
+	dataDir := blockchain.ActiveParams.DataDir
+	blockchain.ActiveParams.DataDir = t.TempDir()
+	t.Cleanup(func() { blockchain.ActiveParams.DataDir = dataDir })
+
+	chain := blockchain.InitBlockChain("test")
+	t.Cleanup(func() { chain.Database.Close() })
+	blockchain.UTXOSet{Blockchain: chain}.Reindex()
+
+	blocks, err := chain.Generate(blockchain.ActiveParams.CoinbaseMaturity+1, string(miner.Address()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memoryPool = make(map[string]*mempoolEntry)
+	t.Cleanup(func() { memoryPool = make(map[string]*mempoolEntry) })
+
+	return chain, blocks[0].Transactions[0]
+}
+
+// prev의 vout 출력을 주어진 시퀀스 번호로 사용하고 fee를 제외한 금액을 지갑으로 보내는 트랜잭션
+func poolSpendTx(t *testing.T, w *wallet.Wallet, prev *blockchain.Transaction, vout int, sequence uint32, fee int) *blockchain.Transaction {
+	t.Helper()
+
+	tx := &blockchain.Transaction{
+		Version: blockchain.TxVersion,
+		Inputs:  []blockchain.TxInput{{ID: prev.ID, Out: vout, Sequence: sequence}},
+		Outputs: []blockchain.TxOutput{*blockchain.NewTXOutput(prev.Outputs[vout].Value-fee, string(w.Address()))},
+	}
+	tx.ID = tx.Hash()
+	tx.Sign(w.DeserializePrivateKey(w.PrivateKey), map[string]blockchain.Transaction{hex.EncodeToString(prev.ID): *prev})
+
+	return tx
+}
+
+// 다른 노드가 중계한 것처럼 트랜잭션을 처리하는 함수
+func relayTx(chain *blockchain.BlockChain, tx *blockchain.Transaction) {
+	payload := GobEncode(Tx{"localhost:3001", tx.Serialize()})
+	HandleTx(append(CmdToBytes("tx"), payload...), chain)
+}
+
+// 메모리 풀에 트랜잭션이 있는지 확인하는 함수
+func inPool(tx *blockchain.Transaction) bool {
+	_, ok := memoryPool[hex.EncodeToString(tx.ID)]
+	return ok
+}
+
+func TestReplacementRequiresSignal(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain, funding := newTestPool(t, miner)
+
+	original := poolSpendTx(t, miner, funding, 0, blockchain.MaxTxInSequenceNum, 1)
+	relayTx(chain, original)
+	if !inPool(original) {
+		t.Fatal("original transaction was not accepted")
+	}
+
+	// 교체 가능함을 표시하지 않은 트랜잭션은 수수료를 더 내도 교체할 수 없음
+	replacement := poolSpendTx(t, miner, funding, 0, blockchain.MaxTxInSequenceNum, 5)
+	relayTx(chain, replacement)
+	if inPool(replacement) || !inPool(original) {
+		t.Fatal("transaction that does not signal replaceability was replaced")
+	}
+}
+
+func TestReplacementFee(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain, funding := newTestPool(t, miner)
+
+	// 수수료가 같은 교체 트랜잭션과 ID가 다르도록 다른 시퀀스 번호를 사용
+	const fee = 2
+	original := poolSpendTx(t, miner, funding, 0, blockchain.MaxRBFSequence-1, fee)
+	relayTx(chain, original)
+	if !inPool(original) {
+		t.Fatal("original transaction was not accepted")
+	}
+
+	// 교체 트랜잭션은 교체되는 수수료에 자신의 크기만큼의 추가 수수료를 더 내야 함
+	increment := blockchain.IncrementalRelayFeeRate.Fee(1, 1)
+	tests := []struct {
+		name string
+		fee  int
+	}{
+		{"lower absolute fee", fee - 1},
+		{"same absolute fee", fee},
+		{"missing incremental relay fee", fee + increment - 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replacement := poolSpendTx(t, miner, funding, 0, blockchain.MaxRBFSequence, test.fee)
+			relayTx(chain, replacement)
+			if inPool(replacement) || !inPool(original) {
+				t.Fatalf("replacement paying %d replaced a transaction paying %d", test.fee, fee)
+			}
+		})
+	}
+
+	replacement := poolSpendTx(t, miner, funding, 0, blockchain.MaxRBFSequence, fee+increment)
+	relayTx(chain, replacement)
+	if !inPool(replacement) || inPool(original) {
+		t.Fatal("replacement paying the incremental relay fee was rejected")
+	}
+}
+
+func TestReplacementEvictsDescendants(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain, funding := newTestPool(t, miner)
+
+	const parentFee, childFee = 2, 3
+	parent := poolSpendTx(t, miner, funding, 0, blockchain.MaxRBFSequence, parentFee)
+	child := poolSpendTx(t, miner, parent, 0, blockchain.MaxTxInSequenceNum, childFee)
+	relayTx(chain, parent)
+	relayTx(chain, child)
+	if !inPool(parent) || !inPool(child) {
+		t.Fatal("parent and child were not accepted")
+	}
+
+	// 자손도 함께 제거되므로 자손의 수수료까지 더 내야 함
+	increment := blockchain.IncrementalRelayFeeRate.Fee(1, 1)
+	cheap := poolSpendTx(t, miner, funding, 0, blockchain.MaxRBFSequence, parentFee+increment)
+	relayTx(chain, cheap)
+	if inPool(cheap) || !inPool(parent) || !inPool(child) {
+		t.Fatal("replacement that does not pay for the evicted child was accepted")
+	}
+
+	replacement := poolSpendTx(t, miner, funding, 0, blockchain.MaxRBFSequence, parentFee+childFee+increment)
+	relayTx(chain, replacement)
+	if !inPool(replacement) {
+		t.Fatal("replacement paying for the parent and child was rejected")
+	}
+	if inPool(parent) || inPool(child) {
+		t.Fatal("replaced transaction or its descendant is still in the pool")
+	}
+}
+
+func TestMempoolExpiry(t *testing.T) {
+	miner := wallet.MakeWallet()
+	chain, funding := newTestPool(t, miner)
+
+	// 서로 다른 출력을 사용하는 트랜잭션을 위해 높이 1의 출력을 두 개로 나눔
+	split := &blockchain.Transaction{
+		Version: blockchain.TxVersion,
+		Inputs:  []blockchain.TxInput{{ID: funding.ID, Out: 0, Sequence: blockchain.MaxTxInSequenceNum}},
+		Outputs: []blockchain.TxOutput{
+			*blockchain.NewTXOutput(funding.Outputs[0].Value/2, string(miner.Address())),
+			*blockchain.NewTXOutput(funding.Outputs[0].Value/2, string(miner.Address())),
+		},
+	}
+	split.ID = split.Hash()
+	split.Sign(miner.DeserializePrivateKey(miner.PrivateKey), map[string]blockchain.Transaction{hex.EncodeToString(funding.ID): *funding})
+
+	parent := poolSpendTx(t, miner, split, 0, blockchain.MaxTxInSequenceNum, 1)
+	child := poolSpendTx(t, miner, parent, 0, blockchain.MaxTxInSequenceNum, 1)
+	fresh := poolSpendTx(t, miner, split, 1, blockchain.MaxTxInSequenceNum, 1)
+	for _, tx := range []*blockchain.Transaction{split, parent, child, fresh} {
+		relayTx(chain, tx)
+	}
+
+	// 만료 시간 직전의 트랜잭션은 남아 있음
+	memoryPool[hex.EncodeToString(parent.ID)].added = time.Now().Add(-mempoolExpiry + time.Minute)
+	trimPool(chain)
+	if !inPool(parent) || !inPool(child) || !inPool(fresh) {
+		t.Fatal("transaction was removed before it expired")
+	}
+
+	// 만료된 트랜잭션은 그 출력을 사용하는 자손과 함께 제거되고 다른 트랜잭션은 남아 있음
+	memoryPool[hex.EncodeToString(parent.ID)].added = time.Now().Add(-mempoolExpiry - time.Minute)
+	trimPool(chain)
+	if inPool(parent) || inPool(child) {
+		t.Fatal("expired transaction or its descendant is still in the pool")
+	}
+	if !inPool(split) || !inPool(fresh) {
+		t.Fatal("unexpired transaction was removed")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"runtime"
 	"syscall"
+	"time"
 
 	"github.com/vrecan/death/v3"
 
@@ -25,11 +26,11 @@ const (
 )
 
 var (
-	nodeAddress     string                                    // 현재 노드의 주소
-	mineAddress     string                                    // 채굴 주소
-	KnownNodes      = []string{"localhost:3000"}              // 알려진 노드 리스트 초기화
-	blocksInTransit = [][]byte{}                              // 전송 중인 블록의 해시 리스트
-	memoryPool      = make(map[string]blockchain.Transaction) // 메모리 풀에 저장된 트랜잭션
+	nodeAddress     string                           // 현재 노드의 주소
+	mineAddress     string                           // 채굴 주소
+	KnownNodes      = []string{"localhost:3000"}     // 알려진 노드 리스트 초기화(SetSeedNode로 네트워크 기본 포트 사용)
+	blocksInTransit = [][]byte{}                     // 전송 중인 블록의 해시 리스트
+	memoryPool      = make(map[string]*mempoolEntry) // 메모리 풀에 저장된 트랜잭션
 )
 
 // 노드 주소 리스트를 저장
@@ -68,11 +69,28 @@ type Tx struct {
 	Transaction []byte
 }
 
+// 블록 템플릿 응답(요청한 연결로 바로 응답)
+type BlockTemplate struct {
+	PrevHash      []byte
+	Height        int
+	Transactions  [][]byte
+	Fees          []int
+	CoinbaseValue int
+	Size          int
+	SigOps        int
+}
+
 // 버전 정보를 저장
 type Version struct {
-	Version    int
-	BestHeight int
-	AddrFrom   string
+	Version     int
+	BestHeight  int
+	AddrFrom    string
+	GenesisHash []byte
+}
+
+// 선택된 네트워크의 기본 포트를 사용하는 시드 노드로 알려진 노드 리스트를 초기화
+func SetSeedNode() {
+	KnownNodes = []string{fmt.Sprintf("localhost:%s", blockchain.ActiveParams.DefaultPort)}
 }
 
 // 명령어를 바이트 배열로 변환
@@ -171,8 +189,9 @@ func SendData(addr string, data []byte) {
 	// 함수 종료 시 연결 닫기
 	defer conn.Close()
 
-	// 데이터 전송
-	_, err = io.Copy(conn, bytes.NewReader(data))
+	// 네트워크 매직 바이트를 붙여 데이터 전송
+	magic := blockchain.ActiveParams.Magic
+	_, err = io.Copy(conn, io.MultiReader(bytes.NewReader(magic[:]), bytes.NewReader(data)))
 	if err != nil {
 		// 데이터 전송 실패 시 패닉
 		log.Panic(err)
@@ -234,7 +253,7 @@ func SendVersion(addr string, chain *blockchain.BlockChain) {
 	fmt.Println("bestHeight: ", bestHeight)
 
 	// Version 구조체를 GOB 인코딩하여 바이트 배열로 변환
-	payload := GobEncode(Version{version, bestHeight, nodeAddress})
+	payload := GobEncode(Version{version, bestHeight, nodeAddress, chain.GetGenesisHash()})
 	// "version" 명령어와 인코딩된 데이터를 결합하여 요청 생성
 	request := append(CmdToBytes("version"), payload...)
 
@@ -292,8 +311,19 @@ func HandleBlock(request []byte, chain *blockchain.BlockChain) {
 
 	// 새로운 블록 수신 메시지 출력
 	fmt.Println("Recevied a new block!")
-	// 블록체인에 블록 추가
-	chain.AddBlock(block)
+
+	// 이미 가지고 있는 블록은 다시 처리하지 않음
+	if _, err := chain.GetBlock(block.Hash); err == nil {
+		return
+	}
+
+	// 작업 증명, 서명, 이중 지불, 금액, 잠금, 코인베이스 성숙 깊이를 검증한 뒤 블록을 연결하고 UTXO 집합 갱신
+	// 유효하지 않은 블록은 버리고 보낸 노드에서 받던 나머지 블록도 요청하지 않음
+	if err := chain.ConnectBlock(block); err != nil {
+		fmt.Printf("Rejecting block %x: %v\n", block.Hash, err)
+		blocksInTransit = [][]byte{}
+		return
+	}
 
 	// 추가된 블록의 해시 출력
 	fmt.Printf("Added block %x\n", block.Hash)
@@ -308,10 +338,18 @@ func HandleBlock(request []byte, chain *blockchain.BlockChain) {
 		// 전송 중인 블록 리스트에서 첫 번째 블록 제거
 		blocksInTransit = blocksInTransit[1:]
 	} else {
-		// UTXO 집합 생성
-		UTXOSet := blockchain.UTXOSet{Blockchain: chain}
-		// UTXO 집합 재색인
-		UTXOSet.Reindex()
+		// 블록에 포함되었거나 블록과 충돌하는 트랜잭션을 메모리 풀에서 제거
+		trimPool(chain)
+
+		// 현재 노드가 마스터 노드인 경우 검증한 블록을 다른 노드에 전달
+		if nodeAddress == KnownNodes[0] {
+			for _, node := range KnownNodes {
+				// 자신과 블록을 보낸 노드를 제외한 노드에 전송
+				if node != nodeAddress && node != payload.AddrFrom {
+					SendInv(node, "block", [][]byte{block.Hash})
+				}
+			}
+		}
 	}
 }
 
@@ -336,24 +374,24 @@ func HandleInv(request []byte, chain *blockchain.BlockChain) {
 
 	// 인벤토리 타입이 "block"인 경우
 	if payload.Type == "block" {
-		// 전송 중인 블록 목록을 업데이트
-		blocksInTransit = payload.Items
+		// 가지고 있지 않은 블록만 체인에 연결할 수 있도록 오래된 블록부터 요청(인벤토리는 최신 블록부터 나열됨)
+		newInTransit := [][]byte{}
+		for i := len(payload.Items) - 1; i >= 0; i-- {
+			if _, err := chain.GetBlock(payload.Items[i]); err != nil {
+				newInTransit = append(newInTransit, payload.Items[i])
+			}
+		}
+		if len(newInTransit) == 0 {
+			return
+		}
 
 		// 첫 번째 블록 해시를 가져옴
-		blockHash := payload.Items[0]
+		blockHash := newInTransit[0]
 		// 블록 요청 전송
 		SendGetData(payload.AddrFrom, "block", blockHash)
 
-		// 새로운 전송 중인 블록 목록 생성
-		newInTransit := [][]byte{}
-		for _, b := range blocksInTransit {
-			// 첫 번째 블록 해시와 다른 블록 해시만 추가
-			if bytes.Compare(b, blockHash) != 0 {
-				newInTransit = append(newInTransit, b)
-			}
-		}
 		// 전송 중인 블록 목록 업데이트
-		blocksInTransit = newInTransit
+		blocksInTransit = newInTransit[1:]
 	}
 
 	// 인벤토리 타입이 "tx"인 경우
@@ -363,7 +401,7 @@ func HandleInv(request []byte, chain *blockchain.BlockChain) {
 		fmt.Printf("handle inv: %x\n", txID)
 
 		// 메모리 풀에 트랜잭션이 없는 경우
-		if memoryPool[hex.EncodeToString(txID)].ID == nil {
+		if _, ok := memoryPool[hex.EncodeToString(txID)]; !ok {
 			// 트랜잭션 요청 전송
 			SendGetData(payload.AddrFrom, "tx", txID)
 		}
@@ -426,10 +464,14 @@ func HandleGetData(request []byte, chain *blockchain.BlockChain) {
 		// 트랜잭션 ID를 문자열로 변환
 		txID := hex.EncodeToString(payload.ID)
 		// 메모리 풀에서 트랜잭션 가져오기
-		tx := memoryPool[txID]
+		entry, ok := memoryPool[txID]
+		if !ok {
+			// 트랜잭션이 없으면 함수 종료
+			return
+		}
 
 		// 트랜잭션 데이터를 전송
-		SendTx(payload.AddrFrom, &tx)
+		SendTx(payload.AddrFrom, &entry.tx)
 	}
 }
 
@@ -453,8 +495,69 @@ func HandleTx(request []byte, chain *blockchain.BlockChain) {
 	txData := payload.Transaction
 	// 트랜잭션 데이터를 역직렬화하여 트랜잭션 객체 생성
 	tx := blockchain.DeserializeTransaction(txData)
+
+	// 출력 형식이 잘못된 트랜잭션은 메모리 풀에 추가하지 않음
+	if err := tx.CheckOutputs(); err != nil {
+		fmt.Printf("Rejecting transaction %x: %v\n", tx.ID, err)
+		return
+	}
+
+	// 코인베이스는 블록에만 포함될 수 있으므로 중계된 코인베이스는 거부
+	if tx.IsCoinbase() {
+		fmt.Printf("Rejecting transaction %x: coinbase transactions are only valid in blocks\n", tx.ID)
+		return
+	}
+
+	// ID가 내용과 다른 트랜잭션은 거부
+	if err := tx.CheckID(); err != nil {
+		fmt.Printf("Rejecting transaction %x: %v\n", tx.ID, err)
+		return
+	}
+
+	// 만료되었거나 더 이상 유효하지 않은 트랜잭션을 먼저 제거
+	trimPool(chain)
+
+	// 이미 메모리 풀에 있는 트랜잭션은 다시 처리하지 않음
+	if _, ok := memoryPool[hex.EncodeToString(tx.ID)]; ok {
+		return
+	}
+
+	// UTXO 집합 위에 메모리 풀의 트랜잭션을 적용한 뷰 생성(메모리 풀의 부모 출력도 사용할 수 있음)
+	// 같은 출력을 사용하는 메모리 풀의 트랜잭션과 그 자손은 교체 대상이므로 제외
+	conflicts := findConflicts(&tx)
+	replaced := withDescendants(conflicts)
+	view := blockchain.NewUTXOView(blockchain.UTXOSet{Blockchain: chain})
+	connectPool(view, func(poolTx *blockchain.Transaction) bool { return !replaced[hex.EncodeToString(poolTx.ID)] })
+
+	// 충돌하는 트랜잭션을 제거하기 전에 사용하는 출력의 잠금 스크립트와 서명을 검증하고
+	// 다음 블록에 포함될 수 없는 잠긴 트랜잭션이나 성숙하지 않은 코인베이스 출력을 사용하는 트랜잭션은 추가하지 않음
+	if err := view.CheckInputs(&tx); err != nil {
+		fmt.Printf("Rejecting transaction %x: %v\n", tx.ID, err)
+		return
+	}
+
+	// 사용할 수 없는 출력을 쓰거나 금액이 맞지 않는 트랜잭션은 추가하지 않음
+	fee, err := view.Connect(&tx)
+	if err != nil {
+		fmt.Printf("Rejecting transaction %x: %v\n", tx.ID, err)
+		return
+	}
+
+	// 충돌하는 트랜잭션이 교체 가능함을 표시하고 더 높은 수수료를 내는 경우에만 교체
+	if len(conflicts) > 0 {
+		evicted, err := checkReplacement(&tx, fee, conflicts)
+		if err != nil {
+			fmt.Printf("Rejecting transaction %x: %v\n", tx.ID, err)
+			return
+		}
+		for id := range evicted {
+			fmt.Printf("Replacing transaction %s with %x\n", id, tx.ID)
+			delete(memoryPool, id)
+		}
+	}
+
 	// 메모리 풀에 트랜잭션 추가
-	memoryPool[hex.EncodeToString(tx.ID)] = tx
+	memoryPool[hex.EncodeToString(tx.ID)] = &mempoolEntry{tx, fee, time.Now()}
 
 	// 노드 주소와 메모리 풀 크기 출력
 	fmt.Println("KnownNodes in HandleTx: ", KnownNodes)
@@ -478,42 +581,42 @@ func HandleTx(request []byte, chain *blockchain.BlockChain) {
 	}
 }
 
-// 트랜잭션을 채굴하는 함수
-func MineTx(chain *blockchain.BlockChain) {
-	var txs []*blockchain.Transaction
-
-	// 메모리 풀에 있는 모든 트랜잭션에 대해
+// 메모리 풀의 트랜잭션으로 다음 블록의 템플릿을 만드는 함수
+// 서명이 잘못되었거나 잠금이 풀리지 않은 트랜잭션, 성숙하지 않은 코인베이스를 사용하는 트랜잭션은 제외(다음 블록까지 남겨 둠)
+func newBlockTemplate(chain *blockchain.BlockChain) *blockchain.BlockTemplate {
+	var candidates []*blockchain.Transaction
 	for id := range memoryPool {
+		tx := memoryPool[id].tx
 		// 트랜잭션 ID 출력
-		fmt.Printf("txID: %x\n", memoryPool[id].ID)
-		// 트랜잭션 가져오기
-		tx := memoryPool[id]
-		// 트랜잭션 검증
-		if chain.VerifyTransaction(&tx) {
-			// 유효한 트랜잭션만 추가
-			txs = append(txs, &tx)
-		}
+		fmt.Printf("txID: %x\n", tx.ID)
+		candidates = append(candidates, &tx)
 	}
 
+	return chain.NewBlockTemplate(candidates)
+}
+
+// 트랜잭션을 채굴하는 함수
+func MineTx(chain *blockchain.BlockChain) {
+	// 메모리 풀의 트랜잭션으로 블록 템플릿 생성
+	template := newBlockTemplate(chain)
+
 	// 유효한 트랜잭션이 없는 경우
-	if len(txs) == 0 {
+	if len(template.Transactions) == 0 {
 		// 모든 트랜잭션이 유효하지 않음을 출력
 		fmt.Println("All Transactions are invalid")
 		// 함수 종료
 		return
 	}
 
-	// 코인베이스 트랜잭션 생성
-	cbTx := blockchain.CoinbaseTx(mineAddress, "")
-	// 트랜잭션 목록에 코인베이스 트랜잭션 추가
-	txs = append(txs, cbTx)
+	// 수수료를 모두 받는 코인베이스 트랜잭션을 앞에 추가한 트랜잭션 목록
+	txs := template.BlockTransactions(mineAddress)
 
 	// 트랜잭션 목록을 포함한  새로운 블록 채굴
 	newBlock := chain.MineBlock(txs)
 	// UTXO 집합 생성
 	UTXOSet := blockchain.UTXOSet{Blockchain: chain}
-	// UTXO 집합 재색인
-	UTXOSet.Reindex()
+	// 새 블록의 트랜잭션으로 UTXO 집합 갱신
+	UTXOSet.Update(newBlock)
 
 	// 새로운 블록이 채굴되었음을 출력
 	fmt.Println("New Block mined")
@@ -525,6 +628,8 @@ func MineTx(chain *blockchain.BlockChain) {
 		// 메모리 풀에서 해당 트랜잭션 제거
 		delete(memoryPool, txID)
 	}
+	// 만료되었거나 더 이상 유효하지 않은 트랜잭션도 제거
+	trimPool(chain)
 
 	// 알려진 모든 노드에 대해
 	for _, node := range KnownNodes {
@@ -542,6 +647,78 @@ func MineTx(chain *blockchain.BlockChain) {
 	}
 }
 
+// 블록 템플릿 요청을 처리하는 함수(요청을 보낸 연결로 메모리 풀의 블록 템플릿을 응답)
+func HandleGetTemplate(conn net.Conn, chain *blockchain.BlockChain) {
+	template := newBlockTemplate(chain)
+
+	payload := BlockTemplate{
+		PrevHash:      template.PrevHash,
+		Height:        template.Height,
+		Fees:          template.Fees,
+		CoinbaseValue: template.CoinbaseValue,
+		Size:          template.Size,
+		SigOps:        template.SigOps,
+	}
+	for _, tx := range template.Transactions {
+		payload.Transactions = append(payload.Transactions, tx.Serialize())
+	}
+
+	// 네트워크 매직 바이트를 붙여 응답 전송
+	magic := blockchain.ActiveParams.Magic
+	_, err := io.Copy(conn, io.MultiReader(bytes.NewReader(magic[:]), bytes.NewReader(GobEncode(payload))))
+	if err != nil {
+		fmt.Printf("Failed to send block template: %v\n", err)
+	}
+}
+
+// 노드에 블록 템플릿을 요청하고 같은 연결로 응답을 받는 함수
+func RequestBlockTemplate(addr string) (*blockchain.BlockTemplate, error) {
+	conn, err := net.Dial(protocol, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// 네트워크 매직 바이트와 "gettemplate" 명령어 전송
+	magic := blockchain.ActiveParams.Magic
+	request := append(magic[:], CmdToBytes("gettemplate")...)
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+	// 요청을 모두 보냈음을 알려 노드가 요청을 읽을 수 있도록 함
+	if err := conn.(*net.TCPConn).CloseWrite(); err != nil {
+		return nil, err
+	}
+
+	response, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < len(magic) || !bytes.Equal(response[:len(magic)], magic[:]) {
+		return nil, fmt.Errorf("%s sent no block template", addr)
+	}
+
+	var payload BlockTemplate
+	if err := gob.NewDecoder(bytes.NewReader(response[len(magic):])).Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	template := &blockchain.BlockTemplate{
+		PrevHash:      payload.PrevHash,
+		Height:        payload.Height,
+		Fees:          payload.Fees,
+		CoinbaseValue: payload.CoinbaseValue,
+		Size:          payload.Size,
+		SigOps:        payload.SigOps,
+	}
+	for _, data := range payload.Transactions {
+		tx := blockchain.DeserializeTransaction(data)
+		template.Transactions = append(template.Transactions, &tx)
+	}
+
+	return template, nil
+}
+
 // 버전 정보를 처리하는 함수
 func HandleVersion(request []byte, chain *blockchain.BlockChain) {
 	var buff bytes.Buffer
@@ -558,6 +735,12 @@ func HandleVersion(request []byte, chain *blockchain.BlockChain) {
 		log.Panic(err)
 	}
 
+	// Genesis 블록이 다른 노드는 다른 체인이므로 거부
+	if !bytes.Equal(payload.GenesisHash, chain.GetGenesisHash()) {
+		fmt.Printf("Rejecting peer %s: genesis %x does not match\n", payload.AddrFrom, payload.GenesisHash)
+		return
+	}
+
 	// 현재 노드의 블록체인 높이 가져오기
 	bestHeight := chain.GetBestHeight()
 	// 요청을 보낸 노드의 블록체인 높이 가져오기
@@ -592,6 +775,14 @@ func HandleConnection(conn net.Conn, chain *blockchain.BlockChain) {
 		log.Panic(err)
 	}
 
+	// 다른 네트워크의 메시지는 무시
+	magic := blockchain.ActiveParams.Magic
+	if len(req) < len(magic)+commandLength || !bytes.Equal(req[:len(magic)], magic[:]) {
+		fmt.Println("Ignoring message with unknown network magic")
+		return
+	}
+	req = req[len(magic):]
+
 	// 요청 데이터에서 명령어 추출
 	command := BytesToCmd(req[:commandLength])
 	// 수신한 명령어 출력
@@ -611,6 +802,8 @@ func HandleConnection(conn net.Conn, chain *blockchain.BlockChain) {
 		HandleGetData(req, chain)
 	case "tx":
 		HandleTx(req, chain)
+	case "gettemplate":
+		HandleGetTemplate(conn, chain)
 	case "version":
 		HandleVersion(req, chain)
 	default:
@@ -0,0 +1,216 @@
+package wallet
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// 실행 중인 노드의 지갑 에이전트 소켓 경로(데이터 디렉터리 기준)
+const agentSocket = "%s/wallets_%s.sock"
+
+// 에이전트에 연결할 때의 제한 시간
+const agentDialTimeout = time.Second
+
+// 에이전트가 실행 중이지 않을 때의 에러
+var ErrNoAgent = errors.New("node is not running, walletpassphrase keeps the wallet unlocked in the running node of NODE_ID")
+
+// 에이전트 요청
+type agentRequest struct {
+	// "unlock", "lock" 또는 "key"
+	Command string
+	// 잠금 해제할 때 보관할 암호화 키
+	Key []byte
+	// 잠금 해제를 유지할 시간
+	Timeout time.Duration
+}
+
+// 에이전트 응답
+type agentResponse struct {
+	// 잠금 해제되어 있으면 보관 중인 암호화 키
+	Key []byte
+	Err string
+}
+
+// 실행 중인 노드에서 잠금 해제된 지갑의 암호화 키를 제한 시간 동안 메모리에만 보관하는 에이전트
+// 소유자만 접근할 수 있는 유닉스 소켓으로 같은 NODE_ID의 명령에 키를 전달하며 파일에는 저장하지 않음
+type Agent struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	key   []byte
+	timer *time.Timer
+}
+
+// 노드의 지갑 에이전트를 시작하는 함수
+func StartAgent(nodeId string) (*Agent, error) {
+	path := fmt.Sprintf(agentSocket, dataDir, nodeId)
+
+	// 이전에 종료된 노드가 남긴 소켓 삭제
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// 소유자만 접근할 수 있도록 권한 제한
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	agent := &Agent{ln: ln}
+	go agent.serve()
+
+	return agent, nil
+}
+
+// 에이전트를 종료하고 보관 중인 키를 지우는 함수
+func (a *Agent) Close() error {
+	a.lock()
+
+	return a.ln.Close()
+}
+
+// 연결을 받아 요청을 처리하는 함수
+func (a *Agent) serve() {
+	for {
+		conn, err := a.ln.Accept()
+		if err != nil {
+			return
+		}
+		go a.handle(conn)
+	}
+}
+
+// 요청 하나를 처리하는 함수
+func (a *Agent) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var req agentRequest
+	if err := gob.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	var resp agentResponse
+	switch req.Command {
+	case "unlock":
+		a.unlock(req.Key, req.Timeout)
+	case "lock":
+		a.lock()
+	case "key":
+		resp.Key = a.currentKey()
+	default:
+		resp.Err = fmt.Sprintf("unknown agent command %q", req.Command)
+	}
+
+	gob.NewEncoder(conn).Encode(resp)
+}
+
+// 키를 보관하고 제한 시간이 지나면 지우도록 타이머 설정
+func (a *Agent) unlock(key []byte, timeout time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.clear()
+	a.key = append([]byte{}, key...)
+	a.timer = time.AfterFunc(timeout, a.lock)
+}
+
+// 보관 중인 키를 지우는 함수
+func (a *Agent) lock() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.clear()
+}
+
+// 키를 덮어써서 지우고 타이머를 멈추는 함수(mu를 잡은 상태에서 호출)
+func (a *Agent) clear() {
+	for i := range a.key {
+		a.key[i] = 0
+	}
+	a.key = nil
+
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+}
+
+// 보관 중인 키의 복사본(잠겨 있으면 nil)
+func (a *Agent) currentKey() []byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.key == nil {
+		return nil
+	}
+	return append([]byte{}, a.key...)
+}
+
+// 노드의 에이전트에 요청을 보내고 응답을 받는 함수
+func callAgent(nodeId string, req agentRequest) (agentResponse, error) {
+	var resp agentResponse
+
+	conn, err := net.DialTimeout("unix", fmt.Sprintf(agentSocket, dataDir, nodeId), agentDialTimeout)
+	if err != nil {
+		return resp, ErrNoAgent
+	}
+	defer conn.Close()
+
+	if err := gob.NewEncoder(conn).Encode(req); err != nil {
+		return resp, err
+	}
+	if err := gob.NewDecoder(conn).Decode(&resp); err != nil {
+		return resp, err
+	}
+	if resp.Err != "" {
+		return resp, errors.New(resp.Err)
+	}
+
+	return resp, nil
+}
+
+// 비밀번호를 확인하고 실행 중인 노드가 timeout 동안 지갑을 잠금 해제된 상태로 유지하도록 하는 함수
+func (ws *Wallets) Unlock(passphrase string, timeout time.Duration, nodeId string) error {
+	if err := ws.unlock(passphrase); err != nil {
+		return err
+	}
+
+	_, err := callAgent(nodeId, agentRequest{Command: "unlock", Key: ws.key, Timeout: timeout})
+	return err
+}
+
+// 실행 중인 노드가 보관 중인 키를 지워 지갑을 잠그는 함수
+func (ws *Wallets) Lock(nodeId string) error {
+	if !ws.IsEncrypted() {
+		return ErrWalletNotEncrypted
+	}
+
+	ws.key = nil
+	_, err := callAgent(nodeId, agentRequest{Command: "lock"})
+	return err
+}
+
+// 실행 중인 노드가 키를 보관하고 있으면 지갑을 잠금 해제하는 함수
+// 노드가 실행 중이지 않거나 잠겨 있으면 지갑은 잠긴 상태로 남음
+func (ws *Wallets) loadAgentKey(nodeId string) error {
+	resp, err := callAgent(nodeId, agentRequest{Command: "key"})
+	if err == ErrNoAgent {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if resp.Key == nil {
+		return nil
+	}
+
+	return ws.unlockWithKey(resp.Key)
+}
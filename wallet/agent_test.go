@@ -0,0 +1,110 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// 임시 데이터 디렉터리에 암호화된 지갑 파일을 만들고 주소를 반환하는 함수
+func newEncryptedWallet(t *testing.T, nodeId, passphrase string) string {
+	t.Helper()
+
+	dir := dataDir
+	dataDir = t.TempDir()
+	t.Cleanup(func() { dataDir = dir })
+
+	ws, _ := CreateWallets(nodeId)
+	address := ws.AddWallet()
+	if err := ws.Encrypt(passphrase); err != nil {
+		t.Fatal(err)
+	}
+	ws.SaveFile(nodeId)
+
+	return address
+}
+
+// 지갑 파일을 다시 불러오는 함수
+func loadWallets(t *testing.T, nodeId string) *Wallets {
+	t.Helper()
+
+	ws, err := CreateWallets(nodeId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ws
+}
+
+func TestAgentUnlockAndLock(t *testing.T) {
+	address := newEncryptedWallet(t, "agent", "correct horse")
+
+	// 노드가 실행 중이지 않으면 잠금 해제할 수 없고 지갑은 잠긴 상태로 불러와짐
+	if err := loadWallets(t, "agent").Unlock("correct horse", time.Minute, "agent"); !errors.Is(err, ErrNoAgent) {
+		t.Fatalf("unlock without a running node: got %v, want %v", err, ErrNoAgent)
+	}
+	if !loadWallets(t, "agent").IsLocked() {
+		t.Fatal("wallet is unlocked without a running node")
+	}
+
+	agent, err := StartAgent("agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer agent.Close()
+
+	// 잘못된 비밀번호는 거부되고 노드는 키를 보관하지 않음
+	if err := loadWallets(t, "agent").Unlock("wrong", time.Minute, "agent"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("unlock with a wrong passphrase: got %v, want %v", err, ErrWrongPassphrase)
+	}
+	if !loadWallets(t, "agent").IsLocked() {
+		t.Fatal("wallet is unlocked after a wrong passphrase")
+	}
+
+	// 잠금 해제하면 다른 명령이 불러온 지갑도 개인 키를 사용할 수 있음
+	if err := loadWallets(t, "agent").Unlock("correct horse", time.Minute, "agent"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	ws := loadWallets(t, "agent")
+	if ws.IsLocked() || ws.GetWallet(address).PrivateKey == nil {
+		t.Fatal("wallet is locked after walletpassphrase")
+	}
+
+	// 잠그면 다시 불러온 지갑은 잠겨 있음
+	if err := ws.Lock("agent"); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if !loadWallets(t, "agent").IsLocked() {
+		t.Fatal("wallet is unlocked after walletlock")
+	}
+}
+
+func TestAgentUnlockExpires(t *testing.T) {
+	newEncryptedWallet(t, "expiry", "correct horse")
+
+	agent, err := StartAgent("expiry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer agent.Close()
+
+	if err := loadWallets(t, "expiry").Unlock("correct horse", 100*time.Millisecond, "expiry"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if loadWallets(t, "expiry").IsLocked() {
+		t.Fatal("wallet is locked before the timeout")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if !loadWallets(t, "expiry").IsLocked() {
+		t.Fatal("wallet is still unlocked after the timeout")
+	}
+
+	// 노드를 종료하면 보관 중인 키도 사라짐
+	if err := loadWallets(t, "expiry").Unlock("correct horse", time.Minute, "expiry"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	agent.Close()
+	if !loadWallets(t, "expiry").IsLocked() {
+		t.Fatal("wallet is unlocked after the node stopped")
+	}
+}
@@ -0,0 +1,224 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// scrypt 키 유도 파라미터
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	// 유도되는 암호화 키 길이(AES-256)
+	keyLength = 32
+	// 솔트 길이
+	saltLength = 16
+)
+
+var (
+	// 암호화된 지갑이 잠겨 있을 때의 에러
+	ErrWalletLocked = errors.New("wallet is locked, unlock it with walletpassphrase while the node is running")
+	// 비밀번호가 틀렸을 때의 에러
+	ErrWrongPassphrase = errors.New("the wallet passphrase entered was incorrect")
+	// 지갑이 암호화되어 있지 않을 때의 에러
+	ErrWalletNotEncrypted = errors.New("wallet is not encrypted")
+
+	// 비밀번호 확인용으로 암호화해 두는 값
+	passphraseCheck = []byte("go-blockchain wallet")
+)
+
+// 지갑 암호화 정보
+type Encryption struct {
+	// scrypt 솔트와 파라미터
+	Salt []byte
+	N    int
+	R    int
+	P    int
+	// 비밀번호 확인용 암호문
+	Check []byte
+}
+
+// 지갑이 암호화되어 있는지 확인
+func (ws *Wallets) IsEncrypted() bool {
+	return ws.Encryption != nil
+}
+
+// 지갑이 잠겨 있어 개인 키를 사용할 수 없는지 확인
+func (ws *Wallets) IsLocked() bool {
+	return ws.IsEncrypted() && ws.key == nil
+}
+
+// 비밀번호로 지갑의 모든 개인 키를 암호화하는 함수
+func (ws *Wallets) Encrypt(passphrase string) error {
+	if ws.IsEncrypted() {
+		return errors.New("wallet is already encrypted")
+	}
+
+	// 새로운 솔트 생성
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	enc := &Encryption{Salt: salt, N: scryptN, R: scryptR, P: scryptP}
+	key, err := enc.deriveKey(passphrase)
+	if err != nil {
+		return err
+	}
+
+	// 비밀번호 확인용 값 암호화
+	enc.Check, err = seal(key, passphraseCheck, nil)
+	if err != nil {
+		return err
+	}
+
+	ws.Encryption = enc
+	ws.key = key
+
+	// HD 시드 암호화
+	if ws.Seed != nil {
+		ws.EncryptedSeed, err = seal(key, ws.Seed, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 모든 지갑의 개인 키를 암호화
+	for _, w := range ws.Wallets {
+		if err := ws.encryptWallet(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 비밀번호를 확인하고 메모리에서 지갑의 잠금을 해제하는 함수
+func (ws *Wallets) unlock(passphrase string) error {
+	if !ws.IsEncrypted() {
+		return ErrWalletNotEncrypted
+	}
+
+	key, err := ws.Encryption.deriveKey(passphrase)
+	if err != nil {
+		return err
+	}
+
+	return ws.unlockWithKey(key)
+}
+
+// 키를 확인한 뒤 모든 지갑의 개인 키를 복호화하는 함수
+func (ws *Wallets) unlockWithKey(key []byte) error {
+	if _, err := open(key, ws.Encryption.Check, nil); err != nil {
+		return ErrWrongPassphrase
+	}
+
+	for _, w := range ws.Wallets {
+		privateKey, err := open(key, w.EncryptedKey, w.PublicKey)
+		if err != nil {
+			return err
+		}
+		w.PrivateKey = privateKey
+	}
+
+	// HD 시드 복호화
+	if ws.EncryptedSeed != nil {
+		seed, err := open(key, ws.EncryptedSeed, nil)
+		if err != nil {
+			return err
+		}
+		ws.Seed = seed
+	}
+
+	ws.key = key
+
+	return nil
+}
+
+// 지갑의 개인 키를 암호화하는 함수(공개 키를 추가 인증 데이터로 사용)
+func (ws *Wallets) encryptWallet(w *Wallet) error {
+	if ws.key == nil {
+		return ErrWalletLocked
+	}
+
+	encrypted, err := seal(ws.key, w.PrivateKey, w.PublicKey)
+	if err != nil {
+		return err
+	}
+	w.EncryptedKey = encrypted
+
+	return nil
+}
+
+// 파일에 저장할 때 사용하는 복사본(암호화된 지갑은 평문 개인 키를 제외)
+func (ws *Wallets) persistentCopy() *Wallets {
+	if !ws.IsEncrypted() {
+		return ws
+	}
+
+	wallets := Wallets{
+		Wallets:           make(map[string]*Wallet),
+		WatchOnly:         ws.WatchOnly,
+		Scripts:           ws.Scripts,
+		Encryption:        ws.Encryption,
+		EncryptedSeed:     ws.EncryptedSeed,
+		HDIndex:           ws.HDIndex,
+		Unconfirmed:       ws.Unconfirmed,
+		UnconfirmedChange: ws.UnconfirmedChange,
+	}
+	for address, w := range ws.Wallets {
+		wallets.Wallets[address] = &Wallet{PublicKey: w.PublicKey, EncryptedKey: w.EncryptedKey, Path: w.Path}
+	}
+
+	return &wallets
+}
+
+// 비밀번호로부터 암호화 키를 유도하는 함수
+func (enc *Encryption) deriveKey(passphrase string) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), enc.Salt, enc.N, enc.R, enc.P, keyLength)
+}
+
+// AES-GCM으로 데이터를 암호화하는 함수(논스를 암호문 앞에 붙여 반환)
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// AES-GCM으로 암호화된 데이터를 복호화하는 함수
+func open(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// 키로 AES-GCM 인스턴스를 생성하는 함수
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestEncryptWalletRoundTrip(t *testing.T) {
+	dir := dataDir
+	dataDir = t.TempDir()
+	t.Cleanup(func() { dataDir = dir })
+
+	ws, _ := CreateWallets("crypto")
+	if err := ws.InitHD("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"); err != nil {
+		t.Fatal(err)
+	}
+	first := ws.AddWallet()
+	if _, err := ws.ImportWallet(MakeWallet()); err != nil {
+		t.Fatal(err)
+	}
+	keys := make(map[string][]byte)
+	for address, w := range ws.Wallets {
+		keys[address] = w.PrivateKey
+	}
+	seed := ws.Seed
+
+	if err := ws.unlock("passphrase"); !errors.Is(err, ErrWalletNotEncrypted) {
+		t.Fatalf("unlock before encrypting: got %v, want %v", err, ErrWalletNotEncrypted)
+	}
+	if err := ws.Encrypt("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Encrypt("other"); err == nil {
+		t.Fatal("Encrypt encrypted the wallet twice")
+	}
+
+	// 잠금 해제된 상태에서 가져온 키도 암호화되어 저장됨
+	imported := MakeWallet()
+	if _, err := ws.ImportWallet(imported); err != nil {
+		t.Fatal(err)
+	}
+	keys[string(imported.Address())] = imported.PrivateKey
+	ws.SaveFile("crypto")
+
+	// 파일에는 평문 개인 키와 시드가 없음
+	file, err := os.ReadFile(fmt.Sprintf(walletFile, dataDir, "crypto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for address, key := range keys {
+		if bytes.Contains(file, key) {
+			t.Fatalf("wallet file contains the private key of %s", address)
+		}
+	}
+	if bytes.Contains(file, seed) {
+		t.Fatal("wallet file contains the HD seed")
+	}
+
+	// 불러온 지갑은 잠겨 있고 개인 키를 사용할 수 없음
+	loaded, err := CreateWallets("crypto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.IsLocked() || loaded.Seed != nil || loaded.GetWallet(first).PrivateKey != nil {
+		t.Fatal("loaded wallet is not locked")
+	}
+	if _, err := loaded.GetWallet(first).ExportPrivateKey(); !errors.Is(err, ErrWalletLocked) {
+		t.Fatalf("export from a locked wallet: got %v, want %v", err, ErrWalletLocked)
+	}
+
+	// 틀린 비밀번호로는 잠금 해제되지 않음
+	if err := loaded.unlock("wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("unlock with a wrong passphrase: got %v, want %v", err, ErrWrongPassphrase)
+	}
+	if !loaded.IsLocked() {
+		t.Fatal("wallet is unlocked after a wrong passphrase")
+	}
+
+	// 올바른 비밀번호로 모든 개인 키와 시드를 복호화
+	if err := loaded.unlock("passphrase"); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	for address, key := range keys {
+		if !bytes.Equal(loaded.GetWallet(address).PrivateKey, key) {
+			t.Fatalf("private key of %s does not round trip", address)
+		}
+	}
+	if !bytes.Equal(loaded.Seed, seed) {
+		t.Fatal("HD seed does not round trip")
+	}
+}
+
+func TestEncryptedKeyIsBoundToPublicKey(t *testing.T) {
+	ws := &Wallets{Wallets: make(map[string]*Wallet)}
+	a, b := ws.AddWallet(), ws.AddWallet()
+	if err := ws.Encrypt("passphrase"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 다른 지갑의 암호문으로 바꾼 개인 키는 복호화할 수 없음
+	ws.Wallets[a].EncryptedKey = ws.Wallets[b].EncryptedKey
+	locked := ws.persistentCopy()
+	if err := locked.unlock("passphrase"); err == nil {
+		t.Fatal("unlock accepted an encrypted key of another public key")
+	}
+}
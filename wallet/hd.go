@@ -0,0 +1,147 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 강화(hardened) 파생 인덱스의 시작값
+	HardenedKeyStart = uint32(0x80000000)
+	// 복원 시 연속으로 사용되지 않은 주소가 이만큼 나오면 탐색 중단
+	DefaultGapLimit = 20
+
+	// P-256 곡선용 마스터 키 HMAC 키(SLIP-0010)
+	masterKeySalt = "Nist256p1 seed"
+	// 외부(수신) 주소 파생 경로 형식: m/44'/코인 타입'/계정'/0/인덱스
+	hdPathFormat = "m/44'/%d'/0'/0/%d"
+)
+
+// 파생 경로에 사용하는 코인 타입(선택된 네트워크에 따라 SetNetwork로 변경됨)
+var hdCoinType = uint32(0)
+
+// 계층적 결정 지갑의 확장 키
+type ExtendedKey struct {
+	Key       []byte
+	ChainCode []byte
+}
+
+// 시드로부터 마스터 확장 키를 생성하는 함수
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errors.New("seed length must be between 128 and 512 bits")
+	}
+
+	data := seed
+	for {
+		mac := hmac.New(sha512.New, []byte(masterKeySalt))
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		// 키가 곡선의 범위를 벗어나면 결과를 다시 해시하여 재시도
+		if isValidPrivateKey(sum[:32]) {
+			return &ExtendedKey{sum[:32], sum[32:]}, nil
+		}
+		data = sum
+	}
+}
+
+// 자식 확장 키를 파생하는 함수
+// index가 HardenedKeyStart 이상이면 개인 키로, 아니면 공개 키로 파생
+func (k *ExtendedKey) Child(index uint32) *ExtendedKey {
+	curve := elliptic.P256()
+
+	var data []byte
+	if index >= HardenedKeyStart {
+		data = append([]byte{0x00}, k.Key...)
+	} else {
+		x, y := curve.ScalarBaseMult(k.Key)
+		data = elliptic.MarshalCompressed(curve, x, y)
+	}
+	data = appendUint32(data, index)
+
+	for {
+		mac := hmac.New(sha512.New, k.ChainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		// 자식 개인 키 = (IL + 부모 개인 키) mod N
+		il := new(big.Int).SetBytes(sum[:32])
+		if il.Cmp(curve.Params().N) < 0 {
+			child := new(big.Int).Add(il, new(big.Int).SetBytes(k.Key))
+			child.Mod(child, curve.Params().N)
+
+			if child.Sign() != 0 {
+				return &ExtendedKey{child.FillBytes(make([]byte, 32)), sum[32:]}
+			}
+		}
+
+		// 유효하지 않은 키는 다음 데이터로 재시도
+		data = append([]byte{0x01}, sum[32:]...)
+		data = appendUint32(data, index)
+	}
+}
+
+// 경로(예: m/44'/0'/0'/0/1)를 따라 확장 키를 파생하는 함수
+func (k *ExtendedKey) DerivePath(path string) (*ExtendedKey, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path %q", path)
+	}
+
+	key := k
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'")
+		index, err := strconv.ParseUint(strings.TrimSuffix(part, "'"), 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path %q: %w", path, err)
+		}
+
+		child := uint32(index)
+		if hardened {
+			child += HardenedKeyStart
+		}
+		key = key.Child(child)
+	}
+
+	return key, nil
+}
+
+// 확장 키로 지갑을 생성하는 함수
+func (k *ExtendedKey) Wallet() *Wallet {
+	curve := elliptic.P256()
+	x, y := curve.ScalarBaseMult(k.Key)
+
+	private := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+		D:         new(big.Int).SetBytes(k.Key),
+	}
+
+	return newWallet(private)
+}
+
+// 선택된 네트워크의 외부 주소 파생 경로를 반환하는 함수
+func HDPath(index int) string {
+	return fmt.Sprintf(hdPathFormat, hdCoinType, index)
+}
+
+// 바이트 슬라이스 뒤에 32비트 정수를 빅엔디언으로 붙이는 함수
+func appendUint32(data []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(data, buf[:]...)
+}
+
+// 개인 키가 곡선의 유효 범위(1 ~ N-1)에 있는지 확인
+func isValidPrivateKey(key []byte) bool {
+	d := new(big.Int).SetBytes(key)
+	return d.Sign() > 0 && d.Cmp(elliptic.P256().Params().N) < 0
+}
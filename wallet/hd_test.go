@@ -0,0 +1,210 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// 16진수 문자열을 바이트로 변환하는 함수
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// BIP39 테스트 벡터(비밀번호 "TREZOR")
+func TestMnemonicVectors(t *testing.T) {
+	tests := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+		{
+			"80808080808080808080808080808080",
+			"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+			"d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+		},
+		{
+			"ffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+			"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+		},
+		{
+			"9e885d952ad362caeb4efe34a8e91bd2",
+			"ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic",
+			"274ddc525802f7c828d8ef7ddbcdc5304e87ac3535913611fbbfa986d0c9e5476c91689f9c8a54fd55bd38606aa6a8595ad213d4c9c9f9aca3fb217069a41028",
+		},
+		{
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+			"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.entropy, func(t *testing.T) {
+			entropy := mustDecodeHex(t, test.entropy)
+			if got := entropyToMnemonic(entropy); got != test.mnemonic {
+				t.Fatalf("entropyToMnemonic = %q, want %q", got, test.mnemonic)
+			}
+
+			restored, err := mnemonicToEntropy(test.mnemonic)
+			if err != nil || hex.EncodeToString(restored) != test.entropy {
+				t.Fatalf("mnemonicToEntropy = %x, %v, want %s", restored, err, test.entropy)
+			}
+
+			seed, err := MnemonicToSeed(test.mnemonic, "TREZOR")
+			if err != nil || hex.EncodeToString(seed) != test.seed {
+				t.Fatalf("MnemonicToSeed = %x, %v, want %s", seed, err, test.seed)
+			}
+		})
+	}
+}
+
+func TestInvalidMnemonic(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+	}{
+		{"bad checksum", strings.Repeat("abandon ", 12)},
+		{"unknown word", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon bitcoins"},
+		{"too few words", strings.Repeat("abandon ", 10) + "about"},
+		{"word count not a multiple of three", strings.Repeat("abandon ", 13) + "about"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := MnemonicToSeed(test.mnemonic, ""); !errors.Is(err, ErrInvalidMnemonic) {
+				t.Fatalf("got %v, want %v", err, ErrInvalidMnemonic)
+			}
+		})
+	}
+
+	// 새로 만든 니모닉은 검증을 통과해야 함
+	mnemonic, err := NewMnemonic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MnemonicToSeed(mnemonic, ""); err != nil || len(strings.Fields(mnemonic)) != 12 {
+		t.Fatalf("new mnemonic %q: %v", mnemonic, err)
+	}
+}
+
+// SLIP-0010 nist256p1 테스트 벡터
+func TestHDDerivationVectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		seed      string
+		path      string
+		chainCode string
+		key       string
+	}{
+		{"vector 1 master", "000102030405060708090a0b0c0d0e0f", "m",
+			"beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea", "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2"},
+		{"vector 1 m/0'", "000102030405060708090a0b0c0d0e0f", "m/0'",
+			"3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11", "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c"},
+		{"vector 1 m/0'/1", "000102030405060708090a0b0c0d0e0f", "m/0'/1",
+			"4187afff1aafa8445010097fb99d23aee9f599450c7bd140b6826ac22ba21d0c", "284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129"},
+		{"vector 1 m/0'/1/2'", "000102030405060708090a0b0c0d0e0f", "m/0'/1/2'",
+			"98c7514f562e64e74170cc3cf304ee1ce54d6b6da4f880f313e8204c2a185318", "694596e8a54f252c960eb771a3c41e7e32496d03b954aeb90f61635b8e092aa7"},
+		{"vector 1 m/0'/1/2'/2", "000102030405060708090a0b0c0d0e0f", "m/0'/1/2'/2",
+			"ba96f776a5c3907d7fd48bde5620ee374d4acfd540378476019eab70790c63a0", "5996c37fd3dd2679039b23ed6f70b506c6b56b3cb5e424681fb0fa64caf82aaa"},
+		{"vector 1 m/0'/1/2'/2/1000000000", "000102030405060708090a0b0c0d0e0f", "m/0'/1/2'/2/1000000000",
+			"b9b7b82d326bb9cb5b5b121066feea4eb93d5241103c9e7a18aad40f1dde8059", "21c4f269ef0a5fd1badf47eeacebeeaa3de22eb8e5b0adcd0f27dd99d34d0119"},
+
+		// 파생한 키가 곡선 범위를 벗어나 다시 시도하는 경로
+		{"derivation retry m/28578'", "000102030405060708090a0b0c0d0e0f", "m/28578'",
+			"e94c8ebe30c2250a14713212f6449b20f3329105ea15b652ca5bdfc68f6c65c2", "06f0db126f023755d0b8d86d4591718a5210dd8d024e3e14b6159d63f53aa669"},
+		{"derivation retry m/28578'/33941", "000102030405060708090a0b0c0d0e0f", "m/28578'/33941",
+			"9e87fe95031f14736774cd82f25fd885065cb7c358c1edf813c72af535e83071", "092154eed4af83e078ff9b84322015aefe5769e31270f62c3f66c33888335f3a"},
+
+		// 마스터 키가 곡선 범위를 벗어나 다시 시도하는 시드
+		{"seed retry master", "a7305bc8df8d0951f0cb224c0e95d7707cbdf2c6ce7e8d481fec69c7ff5e9446", "m",
+			"7762f9729fed06121fd13f326884c82f59aa95c57ac492ce8c9654e60efd130c", "3b8c18469a4634517d6d0b65448f8e6c62091b45540a1743c5846be55d47d88f"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			master, err := NewMasterKey(mustDecodeHex(t, test.seed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, err := master.DerivePath(test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := hex.EncodeToString(key.ChainCode); got != test.chainCode {
+				t.Fatalf("chain code %s, want %s", got, test.chainCode)
+			}
+			if got := hex.EncodeToString(key.Key); got != test.key {
+				t.Fatalf("private key %s, want %s", got, test.key)
+			}
+		})
+	}
+}
+
+func TestInvalidDerivationPath(t *testing.T) {
+	master, err := NewMasterKey(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"", "0/1", "m/x", "m/1''", "m/2147483648"} {
+		if _, err := master.DerivePath(path); err == nil {
+			t.Fatalf("DerivePath(%q) succeeded", path)
+		}
+	}
+
+	if _, err := NewMasterKey(make([]byte, 15)); err == nil {
+		t.Fatal("NewMasterKey accepted a 120 bit seed")
+	}
+}
+
+func TestHDWalletRestore(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	// 같은 니모닉으로 만든 지갑은 같은 순서로 같은 주소를 파생
+	original := &Wallets{Wallets: make(map[string]*Wallet)}
+	if err := original.InitHD(mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	var addresses []string
+	for i := 0; i < 3; i++ {
+		addresses = append(addresses, original.AddWallet())
+	}
+	if err := original.InitHD(mnemonic); err == nil {
+		t.Fatal("InitHD replaced an existing seed")
+	}
+
+	// 두 번째 주소만 사용되었으면 복원할 때 첫 두 주소를 추가
+	restored := &Wallets{Wallets: make(map[string]*Wallet)}
+	if err := restored.InitHD(mnemonic); err != nil {
+		t.Fatal(err)
+	}
+	used := PublicKeyHash(original.GetWallet(addresses[1]).PublicKey)
+	found := restored.ScanHD(func(pubKeyHash []byte) bool { return string(pubKeyHash) == string(used) }, DefaultGapLimit)
+
+	if len(found) != 2 || found[0] != addresses[0] || found[1] != addresses[1] {
+		t.Fatalf("ScanHD found %v, want %v", found, addresses[:2])
+	}
+	if restored.HDIndex != 2 || restored.AddWallet() != addresses[2] {
+		t.Fatalf("restored wallet does not continue at index 2")
+	}
+}
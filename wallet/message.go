@@ -0,0 +1,91 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math/big"
+)
+
+const (
+	// 트랜잭션 서명과 구분하기 위해 메시지 앞에 붙이는 접두사
+	messagePrefix = "Go-Blockchain Signed Message:\n"
+
+	// 공개 키(X, Y)와 서명(r, s)의 길이
+	messagePubKeyLength    = 64
+	messageSignatureLength = 64
+)
+
+// 메시지 서명 형식이 올바르지 않을 때의 에러
+var ErrInvalidMessageSignature = errors.New("invalid message signature")
+
+// 접두사를 붙인 메시지의 이중 SHA-256 해시를 계산하는 함수
+func messageHash(message string) []byte {
+	firstHash := sha256.Sum256(append([]byte(messagePrefix), message...))
+	secondHash := sha256.Sum256(firstHash[:])
+
+	return secondHash[:]
+}
+
+// 지갑의 개인 키로 메시지에 서명하는 함수
+// P-256은 서명에서 공개 키를 복구할 수 없으므로 공개 키를 서명 앞에 포함하여 Base64로 인코딩
+func (w Wallet) SignMessage(message string) (string, error) {
+	// 잠긴 지갑은 개인 키를 알 수 없음
+	if w.PrivateKey == nil {
+		return "", ErrWalletLocked
+	}
+
+	privKey := w.DeserializePrivateKey(w.PrivateKey)
+	r, s, err := ecdsa.Sign(rand.Reader, privKey, messageHash(message))
+	if err != nil {
+		return "", err
+	}
+
+	// 공개 키(X, Y), r, s를 각각 32바이트 고정 길이로 결합
+	signature := privKey.X.FillBytes(make([]byte, 32))
+	signature = append(signature, privKey.Y.FillBytes(make([]byte, 32))...)
+	signature = append(signature, r.FillBytes(make([]byte, 32))...)
+	signature = append(signature, s.FillBytes(make([]byte, 32))...)
+
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// 메시지 서명이 주소의 소유자가 만든 것인지 검증하는 함수
+func VerifyMessage(address, signature, message string) (bool, error) {
+	if !ValidateAddress(address) {
+		return false, errors.New("address is not valid")
+	}
+	// 스크립트 해시 주소는 하나의 키로 서명할 수 없음
+	if IsScriptHashAddress(address) {
+		return false, errors.New("address does not refer to a key")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(data) != messagePubKeyLength+messageSignatureLength {
+		return false, ErrInvalidMessageSignature
+	}
+
+	pubKey, sig := data[:messagePubKeyLength], data[messagePubKeyLength:]
+
+	// 포함된 공개 키가 주소의 공개 키 해시와 일치해야 함
+	if !bytes.Equal(PublicKeyHash(pubKey), AddressToPubKeyHash(address)) {
+		return false, nil
+	}
+
+	curve := elliptic.P256()
+	x := new(big.Int).SetBytes(pubKey[:32])
+	y := new(big.Int).SetBytes(pubKey[32:])
+	if !curve.IsOnCurve(x, y) {
+		return false, ErrInvalidMessageSignature
+	}
+
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	rawPubKey := ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+
+	return ecdsa.Verify(&rawPubKey, messageHash(message), r, s), nil
+}
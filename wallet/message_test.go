@@ -0,0 +1,60 @@
+package wallet
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestSignAndVerifyMessage(t *testing.T) {
+	w, other := MakeWallet(), MakeWallet()
+	address := string(w.Address())
+
+	signature, err := w.SignMessage("hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSignature, _ := other.SignMessage("hello")
+
+	// 서명의 마지막 바이트를 바꾼 서명
+	data, _ := base64.StdEncoding.DecodeString(signature)
+	data[len(data)-1] ^= 1
+	tampered := base64.StdEncoding.EncodeToString(data)
+
+	tests := []struct {
+		name      string
+		address   string
+		signature string
+		message   string
+		valid     bool
+		err       error
+	}{
+		{"valid", address, signature, "hello", true, nil},
+		{"different message", address, signature, "hello!", false, nil},
+		{"signed by another key", address, otherSignature, "hello", false, nil},
+		{"tampered signature", address, tampered, "hello", false, nil},
+		{"not base64", address, "!!!", "hello", false, ErrInvalidMessageSignature},
+		{"wrong length", address, base64.StdEncoding.EncodeToString(make([]byte, 100)), "hello", false, ErrInvalidMessageSignature},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			valid, err := VerifyMessage(test.address, test.signature, test.message)
+			if valid != test.valid || !errors.Is(err, test.err) {
+				t.Fatalf("VerifyMessage = %v, %v, want %v, %v", valid, err, test.valid, test.err)
+			}
+		})
+	}
+
+	// 스크립트 해시 주소는 키 하나로 서명할 수 없음
+	scriptAddress := string(ScriptHashToAddress(PublicKeyHash([]byte("script"))))
+	if _, err := VerifyMessage(scriptAddress, signature, "hello"); err == nil {
+		t.Fatal("VerifyMessage accepted a script hash address")
+	}
+
+	// 잠긴 지갑은 서명할 수 없음
+	locked := Wallet{PublicKey: w.PublicKey}
+	if _, err := locked.SignMessage("hello"); !errors.Is(err, ErrWalletLocked) {
+		t.Fatalf("SignMessage with a locked key: got %v, want %v", err, ErrWalletLocked)
+	}
+}
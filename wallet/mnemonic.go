@@ -0,0 +1,126 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// 니모닉 생성에 사용하는 엔트로피 길이(128비트 = 12단어)
+	mnemonicEntropyBits = 128
+	// 시드 유도 시 PBKDF2 반복 횟수
+	mnemonicIterations = 2048
+	// 시드 길이
+	seedLength = 64
+)
+
+// BIP39 영어 단어 목록
+//
+//go:embed wordlist_english.txt
+var wordlistData string
+
+var (
+	// 단어 목록과 단어별 인덱스
+	wordlist  = strings.Split(strings.TrimSpace(wordlistData), "\n")
+	wordIndex = func() map[string]int {
+		index := make(map[string]int, len(wordlist))
+		for i, word := range wordlist {
+			index[word] = i
+		}
+		return index
+	}()
+
+	// 니모닉의 체크섬이 맞지 않을 때의 에러
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+)
+
+// 새로운 무작위 니모닉을 생성하는 함수
+func NewMnemonic() (string, error) {
+	entropy := make([]byte, mnemonicEntropyBits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+
+	return entropyToMnemonic(entropy), nil
+}
+
+// 엔트로피를 니모닉으로 변환하는 함수
+// 엔트로피 뒤에 SHA-256 해시의 앞 (엔트로피 비트 수 / 32)비트를 체크섬으로 붙인 뒤 11비트씩 단어로 변환
+func entropyToMnemonic(entropy []byte) string {
+	entropyBits := len(entropy) * 8
+	checksumBits := entropyBits / 32
+	hash := sha256.Sum256(entropy)
+
+	// 엔트로피와 체크섬을 하나의 정수로 결합
+	data := new(big.Int).SetBytes(entropy)
+	data.Lsh(data, uint(checksumBits))
+	data.Or(data, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	// 뒤에서부터 11비트씩 단어로 변환
+	count := (entropyBits + checksumBits) / 11
+	words := make([]string, count)
+	mask := big.NewInt(2047)
+	for i := count - 1; i >= 0; i-- {
+		index := new(big.Int).And(data, mask)
+		words[i] = wordlist[index.Int64()]
+		data.Rsh(data, 11)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// 니모닉을 검증하고 엔트로피를 복원하는 함수
+func mnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, ErrInvalidMnemonic
+	}
+
+	// 단어를 11비트 값으로 결합
+	data := new(big.Int)
+	for _, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word %q", ErrInvalidMnemonic, word)
+		}
+		data.Lsh(data, 11)
+		data.Or(data, big.NewInt(int64(index)))
+	}
+
+	// 엔트로피와 체크섬 분리
+	totalBits := len(words) * 11
+	checksumBits := totalBits / 33
+	entropyBits := totalBits - checksumBits
+
+	checksum := new(big.Int).And(data, big.NewInt(int64(1<<checksumBits)-1))
+	data.Rsh(data, uint(checksumBits))
+
+	entropy := make([]byte, entropyBits/8)
+	data.FillBytes(entropy)
+
+	// 체크섬 확인
+	hash := sha256.Sum256(entropy)
+	if checksum.Int64() != int64(hash[0]>>(8-checksumBits)) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidMnemonic)
+	}
+
+	return entropy, nil
+}
+
+// 니모닉과 선택적 비밀번호로부터 시드를 유도하는 함수
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if _, err := mnemonicToEntropy(mnemonic); err != nil {
+		return nil, err
+	}
+
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), mnemonicIterations, seedLength, sha512.New), nil
+}
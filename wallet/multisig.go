@@ -0,0 +1,31 @@
+package wallet
+
+import "errors"
+
+// 다중 서명 주소를 redeem script와 함께 추가하는 함수
+// 개인 키가 없으므로 잔액과 내역 조회, 부분 서명 트랜잭션 생성에 사용
+func (ws *Wallets) AddMultiSig(redeemScript []byte) (string, error) {
+	address := string(ScriptHashToAddress(PublicKeyHash(redeemScript)))
+	if _, ok := ws.Scripts[address]; ok {
+		return address, errors.New("multisig address is already in the wallet")
+	}
+
+	ws.Scripts[address] = redeemScript
+
+	return address, nil
+}
+
+// 주소의 redeem script를 반환(지갑에 없는 주소는 nil)
+func (ws *Wallets) GetRedeemScript(address string) []byte {
+	return ws.Scripts[address]
+}
+
+// 모든 다중 서명 주소를 반환
+func (ws *Wallets) GetMultiSigAddresses() []string {
+	var addresses []string
+	for address := range ws.Scripts {
+		addresses = append(addresses, address)
+	}
+
+	return addresses
+}
@@ -0,0 +1,69 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+const (
+	// 개인 키 길이(D 값)
+	privateKeyLength = 32
+)
+
+// 내보낸 개인 키의 버전 바이트(선택된 네트워크에 따라 SetNetwork로 변경됨)
+var privateKeyVersion = byte(0x80)
+
+// 내보낸 개인 키 형식이 올바르지 않을 때의 에러
+var ErrInvalidPrivateKey = errors.New("invalid private key")
+
+// 개인 키를 버전 바이트와 체크섬이 포함된 Base58 문자열로 내보내는 함수
+func (w Wallet) ExportPrivateKey() (string, error) {
+	// 잠긴 지갑은 개인 키를 알 수 없음
+	if w.PrivateKey == nil {
+		return "", ErrWalletLocked
+	}
+
+	// 버전 바이트와 D 값을 결합
+	payload := append([]byte{privateKeyVersion}, w.PrivateKey[len(w.PrivateKey)-privateKeyLength:]...)
+	// 체크섬을 붙여 Base58 인코딩
+	fullPayload := append(payload, Checksum(payload)...)
+
+	return string(Base58Encode(fullPayload)), nil
+}
+
+// 내보낸 개인 키 문자열로 지갑을 복원하는 함수
+func ImportPrivateKey(key string) (*Wallet, error) {
+	data := Base58Decode([]byte(key))
+	if len(data) != 1+privateKeyLength+checksumLength {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	// 체크섬 확인
+	payload, actualChecksum := data[:len(data)-checksumLength], data[len(data)-checksumLength:]
+	if !bytes.Equal(actualChecksum, Checksum(payload)) {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	// 다른 네트워크의 개인 키는 가져올 수 없음
+	if payload[0] != privateKeyVersion {
+		return nil, errors.New("private key is for a different network")
+	}
+
+	d := payload[1:]
+	if !isValidPrivateKey(d) {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	// D 값으로 공개 키를 계산하여 지갑 생성
+	curve := elliptic.P256()
+	x, y := curve.ScalarBaseMult(d)
+	private := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+		D:         new(big.Int).SetBytes(d),
+	}
+
+	return newWallet(private), nil
+}
@@ -0,0 +1,104 @@
+package wallet
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"testing"
+)
+
+// 버전 바이트와 D 값으로 내보낸 개인 키 문자열을 만드는 함수
+func encodePrivateKey(version byte, d []byte) string {
+	payload := append([]byte{version}, d...)
+	return string(Base58Encode(append(payload, Checksum(payload)...)))
+}
+
+func TestPrivateKeyRoundTrip(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		w := MakeWallet()
+
+		exported, err := w.ExportPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		imported, err := ImportPrivateKey(exported)
+		if err != nil {
+			t.Fatalf("ImportPrivateKey(%s): %v", exported, err)
+		}
+
+		if string(imported.Address()) != string(w.Address()) || string(imported.PrivateKey) != string(w.PrivateKey) {
+			t.Fatalf("imported key %s does not match the exported wallet", exported)
+		}
+		if again, _ := imported.ExportPrivateKey(); again != exported {
+			t.Fatalf("re-exported key %s, want %s", again, exported)
+		}
+	}
+}
+
+func TestImportInvalidPrivateKey(t *testing.T) {
+	exported, err := MakeWallet().ExportPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 마지막 문자를 바꾸면 체크섬이 맞지 않음
+	last := exported[len(exported)-1]
+	changed := byte('2')
+	if last == changed {
+		changed = '3'
+	}
+	corrupted := exported[:len(exported)-1] + string(changed)
+
+	order := elliptic.P256().Params().N.Bytes()
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"bad checksum", corrupted},
+		{"truncated", exported[:len(exported)-2]},
+		{"short key", encodePrivateKey(privateKeyVersion, make([]byte, privateKeyLength-1))},
+		{"zero key", encodePrivateKey(privateKeyVersion, make([]byte, privateKeyLength))},
+		{"key not below the curve order", encodePrivateKey(privateKeyVersion, order)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ImportPrivateKey(test.key); err == nil {
+				t.Fatalf("ImportPrivateKey(%s) succeeded", test.key)
+			}
+		})
+	}
+
+	// 다른 네트워크의 올바른 길이 키도 거부
+	other := encodePrivateKey(privateKeyVersion+1, append([]byte{1}, make([]byte, privateKeyLength-1)...))
+	if _, err := ImportPrivateKey(other); err == nil || errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("ImportPrivateKey with another network's version: %v", err)
+	}
+}
+
+func TestImportPrivateKeyIntoWallets(t *testing.T) {
+	ws := &Wallets{Wallets: make(map[string]*Wallet), WatchOnly: make(map[string][]byte)}
+	w := MakeWallet()
+	exported, _ := w.ExportPrivateKey()
+	address := string(w.Address())
+
+	// 감시 전용 주소였던 주소의 개인 키를 가져오면 개인 키를 가진 주소로 전환
+	if err := ws.AddWatchOnly(address); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ImportPrivateKey(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ws.ImportWallet(imported); err != nil || got != address {
+		t.Fatalf("ImportWallet = %s, %v, want %s", got, err, address)
+	}
+	if ws.IsWatchOnly(address) {
+		t.Fatal("imported address is still watch-only")
+	}
+
+	// 같은 키를 두 번 가져올 수 없음
+	if _, err := ws.ImportWallet(imported); err == nil {
+		t.Fatal("ImportWallet accepted a duplicate key")
+	}
+}
@@ -0,0 +1,20 @@
+package wallet
+
+// 노드에 전송한 트랜잭션을 블록에 포함될 때까지 수수료를 높여 교체할 수 있도록 저장
+// change는 트랜잭션을 만들 때 정한 잔돈 출력의 인덱스(잔돈이 없으면 -1)
+func (ws *Wallets) AddUnconfirmed(txID string, tx []byte, change int) {
+	ws.Unconfirmed[txID] = tx
+	ws.UnconfirmedChange[txID] = change
+}
+
+// 저장된 트랜잭션과 잔돈 출력 인덱스를 찾는 함수
+func (ws *Wallets) GetUnconfirmed(txID string) ([]byte, int, bool) {
+	tx, ok := ws.Unconfirmed[txID]
+	return tx, ws.UnconfirmedChange[txID], ok
+}
+
+// 블록에 포함되었거나 교체된 트랜잭션을 삭제
+func (ws *Wallets) RemoveUnconfirmed(txID string) {
+	delete(ws.Unconfirmed, txID)
+	delete(ws.UnconfirmedChange, txID)
+}
@@ -15,14 +15,22 @@ import (
 const (
 	// 체크섬의 길이 정의
 	checksumLength = 4
-	// 버전을 바이트 형태로 정의
-	version = byte(0x00)
 )
 
+// 주소의 버전 바이트(선택된 네트워크에 따라 SetNetwork로 변경됨)
+var version = byte(0x00)
+
+// 스크립트 해시 주소의 버전 바이트(선택된 네트워크에 따라 SetNetwork로 변경됨)
+var scriptHashVersion = byte(0x05)
+
 // 지갑 구조체
 type Wallet struct {
 	PrivateKey []byte
 	PublicKey  []byte
+	// 암호화된 지갑에서 암호화된 개인 키
+	EncryptedKey []byte
+	// HD 지갑에서 파생된 키의 파생 경로(무작위로 생성된 키는 빈 문자열)
+	Path string
 }
 
 // 개인 키를 복원하는 함수
@@ -50,11 +58,24 @@ func (w Wallet) DeserializePrivateKey(data []byte) *ecdsa.PrivateKey {
 
 // 지갑 주소를 생성하는 메서드
 func (w Wallet) Address() []byte {
-	// 공개 키의 해시 값을 얻어옴
-	pubHash := PublicKeyHash(w.PublicKey)
+	// 공개 키의 해시 값으로 주소 생성
+	return PubKeyHashToAddress(PublicKeyHash(w.PublicKey))
+}
+
+// 공개 키 해시로 주소를 생성하는 함수
+func PubKeyHashToAddress(pubHash []byte) []byte {
+	return encodeAddress(version, pubHash)
+}
 
+// 스크립트 해시로 주소를 생성하는 함수
+func ScriptHashToAddress(scriptHash []byte) []byte {
+	return encodeAddress(scriptHashVersion, scriptHash)
+}
+
+// 버전 바이트와 해시로 주소를 생성하는 함수
+func encodeAddress(addressVersion byte, hash []byte) []byte {
 	// 버전과 해시 값을 결합
-	versionHash := append([]byte{version}, pubHash...)
+	versionHash := append([]byte{addressVersion}, hash...)
 	// 체크섬을 계산
 	checksum := Checksum(versionHash)
 
@@ -86,17 +107,25 @@ func NewKeyPair() (ecdsa.PrivateKey, []byte) {
 // 지갑을 생성하는 함수
 func MakeWallet() *Wallet {
 	// 새로운 키 쌍을 생성
-	private, public := NewKeyPair()
+	private, _ := NewKeyPair()
+
+	return newWallet(&private)
+}
 
+// 개인 키로 지갑을 생성하는 함수
+func newWallet(private *ecdsa.PrivateKey) *Wallet {
 	// 개인키를 바이트 배열로 변환하여 저장
 	privateBytes := elliptic.Marshal(private.PublicKey.Curve, private.X, private.Y)
 
-	// D 값을 바이트 배열로 변환하여 저장
-	dBytes := private.D.Bytes()
+	// D 값을 32바이트 배열로 변환하여 저장
+	dBytes := private.D.FillBytes(make([]byte, 32))
 
 	// 개인 키 바이트 배열을 결합하여 저장 (예: [X, Y, D])
 	walletBytes := append(privateBytes, dBytes...)
 
+	// 공개키를 32바이트씩 고정 길이의 X, Y로 변환
+	public := append(private.X.FillBytes(make([]byte, 32)), private.Y.FillBytes(make([]byte, 32))...)
+
 	// 지갑을 생성하고 초기화
 	wallet := Wallet{PrivateKey: walletBytes, PublicKey: public}
 
@@ -133,6 +162,13 @@ func Checksum(payload []byte) []byte {
 	return secondHash[:checksumLength]
 }
 
+// 주소에서 공개 키 해시(스크립트 해시 주소는 스크립트 해시)를 추출하는 함수
+func AddressToPubKeyHash(address string) []byte {
+	pubKeyHash := Base58Decode([]byte(address))
+	// 버전 바이트와 체크섬 제거
+	return pubKeyHash[1 : len(pubKeyHash)-checksumLength]
+}
+
 // 주어진 주소가 유효한지 검증하는 함수
 func ValidateAddress(address string) bool {
 	// Base58 디코딩하여 공개 키 해시를 가져옴
@@ -140,12 +176,22 @@ func ValidateAddress(address string) bool {
 	// 실제 체크섬을 가져옴
 	actualChecksum := pubKeyHash[len(pubKeyHash)-checksumLength:]
 	// 버전 정보를 가져옴
-	version := pubKeyHash[0]
+	addressVersion := pubKeyHash[0]
 	// 버전 정보를 제외한 공개 키 해시를 가져옴
 	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-checksumLength]
 	// 대상 체크섬을 계산
-	targetChecksum := Checksum(append([]byte{version}, pubKeyHash...))
+	targetChecksum := Checksum(append([]byte{addressVersion}, pubKeyHash...))
+
+	// 다른 네트워크의 주소는 유효하지 않음
+	if addressVersion != version && addressVersion != scriptHashVersion {
+		return false
+	}
 
 	// 실제 체크섬과 대상 체크섬을 비교하여 유효성을 확인하고 결과를 반환
 	return bytes.Equal(actualChecksum, targetChecksum)
 }
+
+// 주어진 주소가 스크립트 해시 주소인지 확인하는 함수
+func IsScriptHashAddress(address string) bool {
+	return ValidateAddress(address) && Base58Decode([]byte(address))[0] == scriptHashVersion
+}
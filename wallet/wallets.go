@@ -4,16 +4,49 @@ import (
 	"bytes"
 	"crypto/elliptic"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 )
 
-// 지갑 정보를 저장할 파일 경로
-const walletFile = "./tmp/wallets_%s.data"
+// 지갑 정보를 저장할 파일 경로(데이터 디렉터리 기준)
+const walletFile = "%s/wallets_%s.data"
+
+// 지갑 파일을 저장하는 데이터 디렉터리(선택된 네트워크에 따라 SetNetwork로 변경됨)
+var dataDir = "./tmp"
+
+// 네트워크에 맞게 주소와 개인 키의 버전, HD 코인 타입과 지갑 파일 디렉터리를 설정하는 함수
+func SetNetwork(addressVersion, scriptVersion, keyVersion byte, coinType uint32, dir string) {
+	version = addressVersion
+	scriptHashVersion = scriptVersion
+	privateKeyVersion = keyVersion
+	hdCoinType = coinType
+	dataDir = dir
+}
 
 type Wallets struct {
 	Wallets map[string]*Wallet
+	// 개인 키 없이 감시만 하는 주소와 공개 키 해시
+	WatchOnly map[string][]byte
+	// 다중 서명 주소와 redeem script
+	Scripts map[string][]byte
+	// 지갑 암호화 정보(암호화되지 않은 지갑은 nil)
+	Encryption *Encryption
+
+	// HD 지갑 시드(암호화된 지갑에서는 EncryptedSeed에 저장)
+	Seed          []byte
+	EncryptedSeed []byte
+	// 다음에 파생할 외부 주소 인덱스
+	HDIndex int
+
+	// 노드에 전송했지만 아직 블록에 포함되지 않았을 수 있는 트랜잭션(트랜잭션 ID를 키로 직렬화된 트랜잭션 저장)
+	Unconfirmed map[string][]byte
+	// Unconfirmed 트랜잭션의 잔돈 출력 인덱스(잔돈이 없으면 -1)
+	UnconfirmedChange map[string]int
+
+	// 잠금 해제된 경우의 암호화 키(파일에 저장되지 않음)
+	key []byte
 }
 
 // 새로운 Wallets 구조체 생성 함수
@@ -22,6 +55,10 @@ func CreateWallets(nodeId string) (*Wallets, error) {
 	wallets := Wallets{}
 	// 맵 초기화
 	wallets.Wallets = make(map[string]*Wallet)
+	wallets.WatchOnly = make(map[string][]byte)
+	wallets.Scripts = make(map[string][]byte)
+	wallets.Unconfirmed = make(map[string][]byte)
+	wallets.UnconfirmedChange = make(map[string]int)
 
 	// 파일에서 지갑 정보를 불러와서 에러 확인
 	err := wallets.LoadFile(nodeId)
@@ -30,12 +67,26 @@ func CreateWallets(nodeId string) (*Wallets, error) {
 }
 
 // 새로운 지갑을 생성하고 Wallets 구조체에 추가
+// HD 시드가 있으면 다음 파생 경로의 키를, 없으면 무작위 키를 사용
 func (ws *Wallets) AddWallet() string {
 	// 새로운 지갑 생성
-	wallet := MakeWallet()
+	var wallet *Wallet
+	if ws.HasHDSeed() {
+		wallet = ws.deriveWallet(ws.HDIndex)
+		ws.HDIndex++
+	} else {
+		wallet = MakeWallet()
+	}
 	// 지갑 주소를 문자열로 변환
 	address := string(wallet.Address())
 
+	// 암호화된 지갑이면 새 개인 키도 암호화
+	if ws.IsEncrypted() {
+		if err := ws.encryptWallet(wallet); err != nil {
+			log.Panic(err)
+		}
+	}
+
 	// Wallets 구조체에 지갑 추가
 	ws.Wallets[address] = wallet
 
@@ -43,6 +94,116 @@ func (ws *Wallets) AddWallet() string {
 	return address
 }
 
+// 외부에서 가져온 지갑을 추가하는 함수
+func (ws *Wallets) ImportWallet(wallet *Wallet) (string, error) {
+	address := string(wallet.Address())
+	if _, ok := ws.Wallets[address]; ok {
+		return address, errors.New("key is already in the wallet")
+	}
+
+	// 암호화된 지갑이면 가져온 개인 키도 암호화
+	if ws.IsEncrypted() {
+		if err := ws.encryptWallet(wallet); err != nil {
+			return "", err
+		}
+	}
+
+	// 감시 전용 주소였다면 개인 키를 가진 주소로 전환
+	delete(ws.WatchOnly, address)
+	ws.Wallets[address] = wallet
+
+	return address, nil
+}
+
+// HD 시드가 설정되어 있는지 확인
+func (ws *Wallets) HasHDSeed() bool {
+	return ws.Seed != nil || ws.EncryptedSeed != nil
+}
+
+// 니모닉으로 HD 시드를 설정하는 함수
+func (ws *Wallets) InitHD(mnemonic string) error {
+	if ws.HasHDSeed() {
+		return errors.New("wallet already has an HD seed")
+	}
+	if ws.IsLocked() {
+		return ErrWalletLocked
+	}
+
+	seed, err := MnemonicToSeed(mnemonic, "")
+	if err != nil {
+		return err
+	}
+
+	// 암호화된 지갑이면 시드도 암호화
+	if ws.IsEncrypted() {
+		ws.EncryptedSeed, err = seal(ws.key, seed, nil)
+		if err != nil {
+			return err
+		}
+	}
+	ws.Seed = seed
+	ws.HDIndex = 0
+
+	return nil
+}
+
+// 주어진 인덱스의 외부 주소 지갑을 파생하는 함수
+func (ws *Wallets) deriveWallet(index int) *Wallet {
+	// 잠긴 지갑은 시드를 사용할 수 없음
+	if ws.Seed == nil {
+		log.Panic(ErrWalletLocked)
+	}
+
+	master, err := NewMasterKey(ws.Seed)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	path := HDPath(index)
+	key, err := master.DerivePath(path)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	wallet := key.Wallet()
+	wallet.Path = path
+
+	return wallet
+}
+
+// 복원한 HD 시드로 체인에서 사용된 주소를 찾아 지갑에 추가하는 함수
+// 연속으로 gapLimit개의 주소가 사용되지 않으면 탐색을 멈추고, 추가된 주소를 반환
+func (ws *Wallets) ScanHD(isUsed func(pubKeyHash []byte) bool, gapLimit int) []string {
+	var derived []*Wallet
+	lastUsed := -1
+
+	for index := 0; index-lastUsed <= gapLimit; index++ {
+		wallet := ws.deriveWallet(index)
+		derived = append(derived, wallet)
+
+		if isUsed(PublicKeyHash(wallet.PublicKey)) {
+			lastUsed = index
+		}
+	}
+
+	// 마지막으로 사용된 주소까지 지갑에 추가
+	var addresses []string
+	for _, wallet := range derived[:lastUsed+1] {
+		if ws.IsEncrypted() {
+			if err := ws.encryptWallet(wallet); err != nil {
+				log.Panic(err)
+			}
+		}
+
+		address := string(wallet.Address())
+		ws.Wallets[address] = wallet
+		addresses = append(addresses, address)
+	}
+	ws.HDIndex = lastUsed + 1
+
+	return addresses
+}
+
 // Wallets 구조체에 있는 모든 지갑 주소를 반환
 func (ws *Wallets) GetAllAddresses() []string {
 	// 지갑 주소를 담을 슬라이스 생성
@@ -63,7 +224,7 @@ func (ws *Wallets) GetWallet(address string) Wallet {
 
 // 지갑 파일을 읽어와서 Wallets 구조체에 저장
 func (ws *Wallets) LoadFile(nodeId string) error {
-	walletFile := fmt.Sprintf(walletFile, nodeId)
+	walletFile := fmt.Sprintf(walletFile, dataDir, nodeId)
 	if _, err := os.Stat(walletFile); os.IsNotExist(err) {
 		return err
 	}
@@ -89,6 +250,28 @@ func (ws *Wallets) LoadFile(nodeId string) error {
 
 	// 불러온 지갑 정보를 Wallets 구조체에 저장
 	ws.Wallets = wallets.Wallets
+	ws.Encryption = wallets.Encryption
+	ws.Seed = wallets.Seed
+	ws.EncryptedSeed = wallets.EncryptedSeed
+	ws.HDIndex = wallets.HDIndex
+	// 감시 전용 주소가 없던 이전 파일도 사용할 수 있도록 맵이 있을 때만 교체
+	if wallets.WatchOnly != nil {
+		ws.WatchOnly = wallets.WatchOnly
+	}
+	if wallets.Scripts != nil {
+		ws.Scripts = wallets.Scripts
+	}
+	if wallets.Unconfirmed != nil {
+		ws.Unconfirmed = wallets.Unconfirmed
+	}
+	if wallets.UnconfirmedChange != nil {
+		ws.UnconfirmedChange = wallets.UnconfirmedChange
+	}
+
+	// 암호화된 지갑은 실행 중인 노드가 키를 보관하고 있으면 잠금 해제
+	if ws.IsEncrypted() {
+		return ws.loadAgentKey(nodeId)
+	}
 
 	return nil
 }
@@ -97,22 +280,33 @@ func (ws *Wallets) LoadFile(nodeId string) error {
 func (ws *Wallets) SaveFile(nodeId string) {
 	// 저장할 내용을 담을 버퍼 생성
 	var content bytes.Buffer
-	walletFile := fmt.Sprintf(walletFile, nodeId)
+	walletFile := fmt.Sprintf(walletFile, dataDir, nodeId)
 
 	// Gob 인코더에 타원 곡선 등록
 	gob.Register(elliptic.P256())
 
 	// Gob 인코더 생성
 	encoder := gob.NewEncoder(&content)
-	// Wallets 구조체를 인코딩하여 버퍼에 저장
-	err := encoder.Encode(ws)
+	// Wallets 구조체를 인코딩하여 버퍼에 저장(암호화된 지갑은 평문 개인 키 제외)
+	err := encoder.Encode(ws.persistentCopy())
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// 데이터 디렉터리가 없으면 생성
+	err = os.MkdirAll(dataDir, 0755)
 	if err != nil {
 		log.Panic(err)
 	}
 
-	// 파일에 내용 저장
-	err = os.WriteFile(walletFile, content.Bytes(), 0644)
+	// 소유자만 읽을 수 있도록 파일에 내용 저장
+	err = os.WriteFile(walletFile, content.Bytes(), 0600)
+	if err != nil {
+		log.Panic(err)
+	}
 
+	// 이전 버전에서 생성된 파일의 권한도 제한
+	err = os.Chmod(walletFile, 0600)
 	if err != nil {
 		log.Panic(err)
 	}
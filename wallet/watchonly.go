@@ -0,0 +1,53 @@
+package wallet
+
+import "errors"
+
+// 감시 전용 주소를 추가하는 함수
+// 개인 키 없이 주소(공개 키 해시)만 저장하여 잔액과 내역 조회에만 사용
+func (ws *Wallets) AddWatchOnly(address string) error {
+	if !ValidateAddress(address) {
+		return errors.New("address is not valid")
+	}
+	if _, ok := ws.Wallets[address]; ok {
+		return errors.New("address is already in the wallet")
+	}
+	if ws.IsWatchOnly(address) {
+		return errors.New("address is already watched")
+	}
+
+	ws.WatchOnly[address] = AddressToPubKeyHash(address)
+
+	return nil
+}
+
+// 주소가 감시 전용 주소인지 확인
+func (ws *Wallets) IsWatchOnly(address string) bool {
+	_, ok := ws.WatchOnly[address]
+	return ok
+}
+
+// 모든 감시 전용 주소를 반환
+func (ws *Wallets) GetWatchOnlyAddresses() []string {
+	var addresses []string
+	for address := range ws.WatchOnly {
+		addresses = append(addresses, address)
+	}
+
+	return addresses
+}
+
+// 개인 키를 가진 주소와 감시 전용 주소의 공개 키 해시, 다중 서명 주소의 스크립트 해시를 모두 반환(주소를 키로 사용)
+func (ws *Wallets) GetAllPubKeyHashes() map[string][]byte {
+	pubKeyHashes := make(map[string][]byte)
+	for address, w := range ws.Wallets {
+		pubKeyHashes[address] = PublicKeyHash(w.PublicKey)
+	}
+	for address, pubKeyHash := range ws.WatchOnly {
+		pubKeyHashes[address] = pubKeyHash
+	}
+	for address, redeemScript := range ws.Scripts {
+		pubKeyHashes[address] = PublicKeyHash(redeemScript)
+	}
+
+	return pubKeyHashes
+}
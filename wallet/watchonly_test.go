@@ -0,0 +1,53 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWatchOnlyAddresses(t *testing.T) {
+	dir := dataDir
+	dataDir = t.TempDir()
+	t.Cleanup(func() { dataDir = dir })
+
+	ws, _ := CreateWallets("watch")
+	owned := ws.AddWallet()
+	watched := string(MakeWallet().Address())
+
+	if err := ws.AddWatchOnly(watched); err != nil {
+		t.Fatalf("AddWatchOnly: %v", err)
+	}
+	if err := ws.AddWatchOnly(watched); err == nil {
+		t.Fatal("AddWatchOnly accepted an address twice")
+	}
+	if err := ws.AddWatchOnly(owned); err == nil {
+		t.Fatal("AddWatchOnly accepted an address with a private key")
+	}
+
+	// 다른 네트워크의 주소는 감시할 수 없음
+	other := string(encodeAddress(version+1, PublicKeyHash(MakeWallet().PublicKey)))
+	if err := ws.AddWatchOnly(other); err == nil {
+		t.Fatal("AddWatchOnly accepted another network's address")
+	}
+
+	// 감시 전용 주소는 파일에 저장되고 잔액 조회에 사용할 공개 키 해시 목록에 포함되지만 개인 키는 없음
+	ws.SaveFile("watch")
+	loaded, err := CreateWallets("watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.IsWatchOnly(watched) || loaded.IsWatchOnly(owned) {
+		t.Fatal("watch-only addresses do not survive saving the wallet")
+	}
+	if addresses := loaded.GetWatchOnlyAddresses(); len(addresses) != 1 || addresses[0] != watched {
+		t.Fatalf("GetWatchOnlyAddresses = %v, want [%s]", addresses, watched)
+	}
+
+	hashes := loaded.GetAllPubKeyHashes()
+	if !bytes.Equal(hashes[watched], AddressToPubKeyHash(watched)) || hashes[owned] == nil {
+		t.Fatalf("GetAllPubKeyHashes does not include both addresses: %v", hashes)
+	}
+	if _, ok := loaded.Wallets[watched]; ok {
+		t.Fatal("watch-only address has a private key")
+	}
+}